@@ -2,10 +2,17 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log/slog"
 	"os"
+	"strings"
 
+	"github.com/robertbagge/markdown-writer-mcp/internal/config"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
 	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
+	"github.com/robertbagge/markdown-writer-mcp/internal/schema"
+	"github.com/robertbagge/markdown-writer-mcp/internal/secrets"
 	"github.com/robertbagge/markdown-writer-mcp/internal/tools"
 	"github.com/robertbagge/markdown-writer-mcp/internal/verifier"
 	"github.com/robertbagge/markdown-writer-mcp/internal/writer"
@@ -13,20 +20,84 @@ import (
 )
 
 func main() {
+	configPath := flag.String("config", "", "Path to a JSON config file")
+	var roots rootList
+	flag.Var(&roots, "root", "Directory the tools may access; repeat for several (overrides roots in the config file)")
+	readOnly := flag.Bool("read-only", false, "Register only tools that never create or change files")
+	includeTools := flag.String("tools", "", "Comma-separated tools to register (overrides tools.include in the config file)")
+	excludeTools := flag.String("exclude-tools", "", "Comma-separated tools not to register (overrides tools.exclude in the config file)")
+	flag.Parse()
+
 	// Setup structured logging
 	setupLogger()
 
 	slog.Info("Starting markdown-writer MCP server")
 
+	// Load configuration
+	cfg := config.Default()
+	if *configPath != "" {
+		var err error
+		cfg, err = config.Load(*configPath)
+		if err != nil {
+			slog.Error("failed to load config", slog.Any("error", err))
+			os.Exit(1)
+		}
+	}
+
+	// Confine path-taking tools to the workspace roots
+	if len(roots) > 0 {
+		cfg.Roots = roots
+	}
+	pathutil.SetExpansion(cfg.Paths.ExpandHome, cfg.Paths.ExpandEnv)
+	if err := tools.SetConfiguredRoots(cfg.Roots); err != nil {
+		slog.Error("invalid workspace root", slog.Any("error", err))
+		os.Exit(1)
+	}
+	if len(cfg.Roots) > 0 {
+		slog.Info("workspace roots set", slog.Any("roots", pathutil.Roots()))
+	} else if cfg.Limits.WorkspaceQuotaBytes > 0 {
+		slog.Warn("limits.workspaceQuotaBytes has no effect until workspace roots are set")
+	}
+
 	// Wire dependencies (constructor injection following DIP)
-	fileWriter := writer.NewOSFileWriter()
+	fileWriter := writer.NewOSFileWriter(writer.Limits{
+		MaxBytes:   cfg.Limits.MaxWriteBytes,
+		QuotaBytes: cfg.Limits.WorkspaceQuotaBytes,
+	})
 	fileVerifier := verifier.NewOSFileVerifier()
-	fileReader := reader.NewOSFileReader()
+	fileReader := reader.NewOSFileReader(cfg.Limits.MaxReadBytes)
+	schemaValidator := schema.NewFileValidator(fileReader, cfg.Schemas)
 
 	// Inject dependencies into tools
 	tools.SetFileWriter(fileWriter)
 	tools.SetFileVerifier(fileVerifier)
 	tools.SetFileReader(fileReader)
+	tools.SetStreamReader(fileReader)
+	tools.SetLineAppender(fileWriter)
+	tools.SetSchemaValidator(schemaValidator)
+	tools.SetMaxQueryResultBytes(cfg.Limits.MaxQueryResultBytes)
+	tools.SetMaxReadBytes(cfg.Limits.MaxReadBytes)
+	tools.SetMaxDepth(cfg.Limits.MaxDepth)
+	tools.SetAccessPolicy(policy.New(cfg.Policy))
+	tools.SetRejectBinary(!cfg.Writes.AllowBinary)
+	if cfg.Writes.DefaultMode != "" {
+		tools.SetDefaultWriteMode(writer.Mode(cfg.Writes.DefaultMode))
+	}
+	if err := tools.SetAllowedExtensions(cfg.Writes.Extensions); err != nil {
+		slog.Error("invalid write extensions", slog.Any("error", err))
+		os.Exit(1)
+	}
+	if action := cfg.Writes.Secrets.Action; action != config.SecretsOff {
+		scanner, err := secrets.New(cfg.Writes.Secrets)
+		if err != nil {
+			slog.Error("invalid secret scanning config", slog.Any("error", err))
+			os.Exit(1)
+		}
+		if action == "" {
+			action = config.SecretsBlock
+		}
+		tools.SetSecretDetector(scanner, secrets.Action(action))
+	}
 
 	// Create MCP server instance
 	server := mcp.NewServer(
@@ -34,16 +105,32 @@ func main() {
 			Name:    "markdown-writer",
 			Version: "1.0.0",
 		},
-		nil, // options
+		&mcp.ServerOptions{
+			// Follow the roots the client advertises
+			InitializedHandler:      tools.RootsInitializedHandler,
+			RootsListChangedHandler: tools.RootsListChangedHandler,
+		},
 	)
 
-	// Register all tools
-	if err := tools.RegisterAll(server); err != nil {
+	// Resolve no path before the client's roots are known
+	server.AddReceivingMiddleware(tools.WaitForRootsMiddleware)
+
+	// Register the selected tools
+	if *readOnly {
+		cfg.Tools.ReadOnly = true
+	}
+	if *includeTools != "" {
+		cfg.Tools.Include = splitList(*includeTools)
+	}
+	if *excludeTools != "" {
+		cfg.Tools.Exclude = splitList(*excludeTools)
+	}
+	if err := tools.RegisterSelected(server, cfg.Tools); err != nil {
 		slog.Error("failed to register tools", slog.Any("error", err))
 		os.Exit(1)
 	}
 
-	slog.Info("tools registered successfully")
+	slog.Info("tools registered successfully", slog.Bool("readOnly", cfg.Tools.ReadOnly))
 
 	// Run server with stdio transport
 	ctx := context.Background()
@@ -53,6 +140,29 @@ func main() {
 	}
 }
 
+// rootList collects the values of a repeated -root flag.
+type rootList []string
+
+func (r *rootList) String() string {
+	return strings.Join(*r, ",")
+}
+
+func (r *rootList) Set(value string) error {
+	*r = append(*r, value)
+	return nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func setupLogger() {
 	// JSON handler for structured logging - writes to stderr
 	handler := slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
@@ -1,13 +1,18 @@
 module github.com/robertbagge/markdown-writer-mcp
 
-go 1.23.0
+go 1.25.0
 
-toolchain go1.24.3
-
-require github.com/modelcontextprotocol/go-sdk v1.1.0
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/google/jsonschema-go v0.3.0
+	github.com/jmespath/go-jmespath v0.4.0
+	github.com/modelcontextprotocol/go-sdk v1.1.0
+	github.com/santhosh-tekuri/jsonschema/v6 v6.0.3
+	golang.org/x/text v0.14.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
-	github.com/google/jsonschema-go v0.3.0 // indirect
 	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
 	golang.org/x/oauth2 v0.30.0 // indirect
 )
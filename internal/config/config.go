@@ -0,0 +1,304 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+)
+
+// Config holds the server settings loaded from the JSON config file.
+type Config struct {
+	// Roots are the directories path-taking tools are confined to. Empty
+	// leaves paths unconfined.
+	Roots []string `json:"roots,omitempty"`
+
+	// Paths enables shorthand expansion in tool paths.
+	Paths Paths `json:"paths"`
+
+	// Schemas maps path globs to the JSON Schema documents must satisfy.
+	// The first matching entry wins.
+	Schemas []SchemaMapping `json:"schemas,omitempty"`
+
+	// Limits bounds the resources a single tool call may use.
+	Limits Limits `json:"limits"`
+
+	// Policy allows or denies tool operations by path.
+	Policy Policy `json:"policy"`
+
+	// Tools selects the tools the server registers.
+	Tools ToolSelection `json:"tools"`
+
+	// Writes restricts what the write tools may create.
+	Writes Writes `json:"writes"`
+}
+
+// Writes holds the checks applied to files before a tool writes them.
+type Writes struct {
+	// Extensions maps tool names to the file extensions they may write,
+	// replacing that tool's defaults. An empty list allows any extension.
+	Extensions map[string][]string `json:"extensions,omitempty"`
+
+	// AllowBinary turns off the rejection of written content that holds
+	// NUL bytes or invalid UTF-8.
+	AllowBinary bool `json:"allowBinary,omitempty"`
+
+	// DefaultMode is the write mode of write and json_write when the caller
+	// gives none: "upsert" (the default) or "create", which refuses to
+	// replace a file unless the caller passes overwrite.
+	DefaultMode string `json:"defaultMode,omitempty"`
+
+	// Secrets configures the scan for credentials in written content.
+	Secrets Secrets `json:"secrets"`
+}
+
+// Secrets configures what happens to likely credentials, such as private
+// keys and API tokens, in content a tool is about to write.
+type Secrets struct {
+	// Action is "block" (the default), "redact", "warn" or "off".
+	Action string `json:"action,omitempty"`
+
+	// Disable lists detectors to turn off, e.g. "high-entropy".
+	Disable []string `json:"disable,omitempty"`
+
+	// Allow lists regular expressions matching values that are not
+	// secrets, such as known test fixtures.
+	Allow []string `json:"allow,omitempty"`
+}
+
+// Secret scan actions.
+const (
+	SecretsBlock  = "block"
+	SecretsRedact = "redact"
+	SecretsWarn   = "warn"
+	SecretsOff    = "off"
+)
+
+// ToolSelection picks the tools to register. Empty selects every tool.
+type ToolSelection struct {
+	// ReadOnly registers only tools that never create or change files.
+	ReadOnly bool `json:"readOnly,omitempty"`
+
+	// Include, when not empty, lists the only tools to register.
+	Include []string `json:"include,omitempty"`
+
+	// Exclude lists tools not to register.
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Policy is an ordered list of access rules. The first rule matching a
+// tool, operation and path decides; Default applies when none matches.
+type Policy struct {
+	Rules []PolicyRule `json:"rules,omitempty"`
+
+	// Default is "allow" (the default) or "deny".
+	Default string `json:"default,omitempty"`
+}
+
+// PolicyRule allows or denies the operations of the listed tools on paths
+// matching any of its patterns. Relative patterns match paths relative to
+// the workspace root containing them, absolute ones whole paths. Empty
+// Operations or Tools match all.
+type PolicyRule struct {
+	// Name identifies the rule in denials; defaults to its position.
+	Name       string   `json:"name,omitempty"`
+	Effect     string   `json:"effect"`
+	Patterns   []string `json:"patterns"`
+	Operations []string `json:"operations,omitempty"`
+	Tools      []string `json:"tools,omitempty"`
+}
+
+// Policy effects and operations.
+const (
+	EffectAllow    = "allow"
+	EffectDeny     = "deny"
+	OperationRead  = "read"
+	OperationWrite = "write"
+)
+
+// Paths holds the opt-in expansions applied to tool paths before they are
+// validated.
+type Paths struct {
+	// ExpandHome expands a leading ~ to the user's home directory.
+	ExpandHome bool `json:"expandHome,omitempty"`
+
+	// ExpandEnv lists the environment variables that $NAME and ${NAME}
+	// references in paths may expand.
+	ExpandEnv []string `json:"expandEnv,omitempty"`
+}
+
+// envName matches a valid environment variable name.
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Limits holds resource ceilings. Zero disables a limit.
+type Limits struct {
+	// MaxQueryResultBytes caps the combined size of the elements returned
+	// by one query, measured in bytes of source JSON.
+	MaxQueryResultBytes int64 `json:"maxQueryResultBytes"`
+
+	// MaxReadBytes caps the size of a file read as a whole. Streaming
+	// queries of JSON and JSON Lines files are not limited.
+	MaxReadBytes int64 `json:"maxReadBytes"`
+
+	// MaxWriteBytes caps the bytes written by one tool call.
+	MaxWriteBytes int64 `json:"maxWriteBytes"`
+
+	// MaxDepth caps the nesting of arrays and objects in documents read or
+	// written.
+	MaxDepth int `json:"maxDepth"`
+
+	// WorkspaceQuotaBytes caps the combined size of the files under the
+	// workspace roots. It is off by default and needs workspace roots.
+	WorkspaceQuotaBytes int64 `json:"workspaceQuotaBytes"`
+}
+
+// Default limits, used unless the config file overrides them.
+const (
+	DefaultMaxQueryResultBytes = 64 << 20
+	DefaultMaxReadBytes        = 64 << 20
+	DefaultMaxWriteBytes       = 16 << 20
+	DefaultMaxDepth            = 256
+)
+
+// SchemaMapping associates a path glob with a JSON Schema file.
+type SchemaMapping struct {
+	Pattern string `json:"pattern"`
+	Schema  string `json:"schema"`
+}
+
+// Default returns the configuration used when no config file is given.
+func Default() *Config {
+	return &Config{
+		Limits: Limits{
+			MaxQueryResultBytes: DefaultMaxQueryResultBytes,
+			MaxReadBytes:        DefaultMaxReadBytes,
+			MaxWriteBytes:       DefaultMaxWriteBytes,
+			MaxDepth:            DefaultMaxDepth,
+		},
+	}
+}
+
+// Load reads a JSON config file. Relative roots, schema patterns and schema
+// paths are resolved against the directory containing the config file.
+// Relative policy patterns are kept, to match within the workspace roots.
+func Load(path string) (*Config, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidConfig, err)
+	}
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidConfig, err)
+	}
+
+	cfg := Default()
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", domain.ErrInvalidConfig, absPath, err)
+	}
+
+	for name, value := range map[string]int64{
+		"maxQueryResultBytes": cfg.Limits.MaxQueryResultBytes,
+		"maxReadBytes":        cfg.Limits.MaxReadBytes,
+		"maxWriteBytes":       cfg.Limits.MaxWriteBytes,
+		"maxDepth":            int64(cfg.Limits.MaxDepth),
+		"workspaceQuotaBytes": cfg.Limits.WorkspaceQuotaBytes,
+	} {
+		if value < 0 {
+			return nil, fmt.Errorf("%w: limits.%s must not be negative", domain.ErrInvalidConfig, name)
+		}
+	}
+
+	baseDir := filepath.Dir(absPath)
+	for i, root := range cfg.Roots {
+		if root == "" {
+			return nil, fmt.Errorf("%w: roots[%d] is empty", domain.ErrInvalidConfig, i)
+		}
+		cfg.Roots[i] = absolutize(baseDir, root)
+	}
+	for i, m := range cfg.Schemas {
+		if m.Pattern == "" || m.Schema == "" {
+			return nil, fmt.Errorf("%w: schemas[%d] needs both pattern and schema", domain.ErrInvalidConfig, i)
+		}
+		cfg.Schemas[i].Pattern = absolutize(baseDir, m.Pattern)
+		cfg.Schemas[i].Schema = absolutize(baseDir, m.Schema)
+	}
+
+	if err := loadPolicy(&cfg.Policy); err != nil {
+		return nil, err
+	}
+
+	for tool, exts := range cfg.Writes.Extensions {
+		for i, ext := range exts {
+			if !strings.HasPrefix(ext, ".") || len(ext) < 2 || strings.ContainsAny(ext, `/\`) {
+				return nil, fmt.Errorf("%w: writes.extensions.%s[%d] must be an extension such as \".md\", got %q", domain.ErrInvalidConfig, tool, i, ext)
+			}
+			exts[i] = strings.ToLower(ext)
+		}
+	}
+	switch cfg.Writes.Secrets.Action {
+	case "", SecretsBlock, SecretsRedact, SecretsWarn, SecretsOff:
+	default:
+		return nil, fmt.Errorf("%w: writes.secrets.action must be %q, %q, %q or %q, got %q",
+			domain.ErrInvalidConfig, SecretsBlock, SecretsRedact, SecretsWarn, SecretsOff, cfg.Writes.Secrets.Action)
+	}
+	for i, name := range cfg.Paths.ExpandEnv {
+		if !envName.MatchString(name) {
+			return nil, fmt.Errorf("%w: paths.expandEnv[%d] must be a variable name such as \"PROJECT_DOCS\", got %q", domain.ErrInvalidConfig, i, name)
+		}
+	}
+	switch cfg.Writes.DefaultMode {
+	case "", "upsert", "create":
+	default:
+		return nil, fmt.Errorf("%w: writes.defaultMode must be \"upsert\" or \"create\", got %q", domain.ErrInvalidConfig, cfg.Writes.DefaultMode)
+	}
+
+	return cfg, nil
+}
+
+// loadPolicy validates the policy rules and cleans their absolute patterns.
+func loadPolicy(p *Policy) error {
+	switch p.Default {
+	case "", EffectAllow, EffectDeny:
+	default:
+		return fmt.Errorf("%w: policy.default must be %q or %q, got %q", domain.ErrInvalidConfig, EffectAllow, EffectDeny, p.Default)
+	}
+
+	for i, rule := range p.Rules {
+		if rule.Effect != EffectAllow && rule.Effect != EffectDeny {
+			return fmt.Errorf("%w: policy.rules[%d].effect must be %q or %q, got %q", domain.ErrInvalidConfig, i, EffectAllow, EffectDeny, rule.Effect)
+		}
+		if len(rule.Patterns) == 0 {
+			return fmt.Errorf("%w: policy.rules[%d] needs at least one pattern", domain.ErrInvalidConfig, i)
+		}
+		for _, op := range rule.Operations {
+			if op != OperationRead && op != OperationWrite {
+				return fmt.Errorf("%w: policy.rules[%d].operations must hold %q or %q, got %q", domain.ErrInvalidConfig, i, OperationRead, OperationWrite, op)
+			}
+		}
+		for j, pattern := range rule.Patterns {
+			if pattern == "" {
+				return fmt.Errorf("%w: policy.rules[%d].patterns[%d] is empty", domain.ErrInvalidConfig, i, j)
+			}
+			// Relative patterns stay relative to the workspace roots
+			if filepath.IsAbs(pattern) {
+				p.Rules[i].Patterns[j] = filepath.Clean(pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// absolutize joins relative paths and globs onto baseDir.
+func absolutize(baseDir, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(baseDir, path)
+}
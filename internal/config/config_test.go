@@ -0,0 +1,219 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/config"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+		want    *config.Config
+		wantErr error
+	}{
+		{
+			name:    "empty config",
+			content: `{}`,
+			want:    config.Default(),
+		},
+		{
+			name:    "relative schema mappings resolve against config dir",
+			content: `{"schemas": [{"pattern": "data/**/*.json", "schema": "schemas/item.json"}, {"pattern": "/etc/app.json", "schema": "/opt/app.schema.json"}]}`,
+			want: &config.Config{
+				Schemas: []config.SchemaMapping{
+					{Pattern: filepath.Join(dir, "data/**/*.json"), Schema: filepath.Join(dir, "schemas/item.json")},
+					{Pattern: "/etc/app.json", Schema: "/opt/app.schema.json"},
+				},
+				Limits: config.Default().Limits,
+			},
+		},
+		{
+			name:    "relative roots resolve against config dir",
+			content: `{"roots": ["docs", "/srv/data"]}`,
+			want: &config.Config{
+				Roots:  []string{filepath.Join(dir, "docs"), "/srv/data"},
+				Limits: config.Default().Limits,
+			},
+		},
+		{
+			name:    "empty root",
+			content: `{"roots": [""]}`,
+			wantErr: domain.ErrInvalidConfig,
+		},
+		{
+			name:    "relative policy patterns stay relative",
+			content: `{"policy": {"default": "deny", "rules": [{"name": "secrets", "effect": "deny", "operations": ["write"], "patterns": ["**/.env", "/etc/**"]}]}}`,
+			want: &config.Config{
+				Limits: config.Default().Limits,
+				Policy: config.Policy{
+					Default: "deny",
+					Rules: []config.PolicyRule{
+						{Name: "secrets", Effect: "deny", Operations: []string{"write"}, Patterns: []string{"**/.env", "/etc/**"}},
+					},
+				},
+			},
+		},
+		{
+			name:    "tool selection",
+			content: `{"tools": {"readOnly": true, "exclude": ["json_diff"]}}`,
+			want: &config.Config{
+				Limits: config.Default().Limits,
+				Tools:  config.ToolSelection{ReadOnly: true, Exclude: []string{"json_diff"}},
+			},
+		},
+		{
+			name:    "write extensions are lower-cased",
+			content: `{"writes": {"extensions": {"write": [".MD", ".txt"]}, "allowBinary": true}}`,
+			want: &config.Config{
+				Limits: config.Default().Limits,
+				Writes: config.Writes{Extensions: map[string][]string{"write": {".md", ".txt"}}, AllowBinary: true},
+			},
+		},
+		{
+			name:    "write extension without dot",
+			content: `{"writes": {"extensions": {"write": ["md"]}}}`,
+			wantErr: domain.ErrInvalidConfig,
+		},
+		{
+			name:    "create as default write mode",
+			content: `{"writes": {"defaultMode": "create"}}`,
+			want: &config.Config{
+				Limits: config.Default().Limits,
+				Writes: config.Writes{DefaultMode: "create"},
+			},
+		},
+		{
+			name:    "path expansion",
+			content: `{"paths": {"expandHome": true, "expandEnv": ["PROJECT_DOCS"]}}`,
+			want: &config.Config{
+				Limits: config.Default().Limits,
+				Paths:  config.Paths{ExpandHome: true, ExpandEnv: []string{"PROJECT_DOCS"}},
+			},
+		},
+		{
+			name:    "invalid expansion variable",
+			content: `{"paths": {"expandEnv": ["$PROJECT_DOCS"]}}`,
+			wantErr: domain.ErrInvalidConfig,
+		},
+		{
+			name:    "secret scanning",
+			content: `{"writes": {"secrets": {"action": "redact", "disable": ["high-entropy"]}}}`,
+			want: &config.Config{
+				Limits: config.Default().Limits,
+				Writes: config.Writes{Secrets: config.Secrets{Action: "redact", Disable: []string{"high-entropy"}}},
+			},
+		},
+		{
+			name:    "unknown secret action",
+			content: `{"writes": {"secrets": {"action": "ignore"}}}`,
+			wantErr: domain.ErrInvalidConfig,
+		},
+		{
+			name:    "unknown default write mode",
+			content: `{"writes": {"defaultMode": "overwrite"}}`,
+			wantErr: domain.ErrInvalidConfig,
+		},
+		{
+			name:    "unknown policy effect",
+			content: `{"policy": {"rules": [{"effect": "block", "patterns": ["**"]}]}}`,
+			wantErr: domain.ErrInvalidConfig,
+		},
+		{
+			name:    "unknown policy operation",
+			content: `{"policy": {"rules": [{"effect": "deny", "operations": ["delete"], "patterns": ["**"]}]}}`,
+			wantErr: domain.ErrInvalidConfig,
+		},
+		{
+			name:    "policy rule without patterns",
+			content: `{"policy": {"rules": [{"effect": "deny"}]}}`,
+			wantErr: domain.ErrInvalidConfig,
+		},
+		{
+			name:    "unknown policy default",
+			content: `{"policy": {"default": "ask"}}`,
+			wantErr: domain.ErrInvalidConfig,
+		},
+		{
+			name:    "limits override defaults",
+			content: `{"limits": {"maxQueryResultBytes": 0, "maxReadBytes": 0, "maxWriteBytes": 0, "maxDepth": 0, "workspaceQuotaBytes": 0}}`,
+			want:    &config.Config{},
+		},
+		{
+			name:    "workspace quota",
+			content: `{"limits": {"workspaceQuotaBytes": 1048576}}`,
+			want: func() *config.Config {
+				cfg := config.Default()
+				cfg.Limits.WorkspaceQuotaBytes = 1 << 20
+				return cfg
+			}(),
+		},
+		{
+			name:    "negative depth",
+			content: `{"limits": {"maxDepth": -1}}`,
+			wantErr: domain.ErrInvalidConfig,
+		},
+		{
+			name:    "negative limit",
+			content: `{"limits": {"maxQueryResultBytes": -1}}`,
+			wantErr: domain.ErrInvalidConfig,
+		},
+		{
+			name:    "unknown field",
+			content: `{"schema": []}`,
+			wantErr: domain.ErrInvalidConfig,
+		},
+		{
+			name:    "incomplete mapping",
+			content: `{"schemas": [{"pattern": "*.json"}]}`,
+			wantErr: domain.ErrInvalidConfig,
+		},
+		{
+			name:    "malformed JSON",
+			content: `{"schemas": `,
+			wantErr: domain.ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "config.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+
+			got, err := config.Load(path)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Load() unexpected error = %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	_, err := config.Load(filepath.Join(t.TempDir(), "missing.json"))
+	if !errors.Is(err, domain.ErrInvalidConfig) {
+		t.Errorf("Load() error = %v, wantErr %v", err, domain.ErrInvalidConfig)
+	}
+}
@@ -0,0 +1,175 @@
+package csvdoc_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/csvdoc"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+)
+
+func decodeJSON(t *testing.T, s string) []any {
+	t.Helper()
+	var v []any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid test JSON: %v", err)
+	}
+	return v
+}
+
+func TestEncode(t *testing.T) {
+	const records = `[
+		{"id": 1, "name": "Acme, Inc.", "address": {"city": "Oslo", "zip": "0150"}, "tags": ["b2b", "eu"]},
+		{"id": 2.5, "name": "Quote \"Co\"", "active": true, "address": {}, "tags": [], "owners": [{"id": 7}]}
+	]`
+
+	tests := []struct {
+		name        string
+		opts        csvdoc.EncodeOptions
+		want        string
+		wantColumns []string
+	}{
+		{
+			name:        "all leaf columns",
+			want:        "address.city,address.zip,id,name,tags,active,owners\nOslo,0150,1,\"Acme, Inc.\",b2b;eu,,\n,,2.5,\"Quote \"\"Co\"\"\",,true,\"[{\"\"id\"\":7}]\"\n",
+			wantColumns: []string{"address.city", "address.zip", "id", "name", "tags", "active", "owners"},
+		},
+		{
+			name:        "chosen columns, delimiter and separator",
+			opts:        csvdoc.EncodeOptions{Columns: []string{"name", "address.city", "tags", "missing"}, Delimiter: '\t', ArraySeparator: "|"},
+			want:        "name\taddress.city\ttags\tmissing\nAcme, Inc.\tOslo\tb2b|eu\t\n\"Quote \"\"Co\"\"\"\t\t\t\n",
+			wantColumns: []string{"name", "address.city", "tags", "missing"},
+		},
+		{
+			name:        "object column is written as JSON",
+			opts:        csvdoc.EncodeOptions{Columns: []string{"address"}},
+			want:        "address\n\"{\"\"city\"\":\"\"Oslo\"\",\"\"zip\"\":\"\"0150\"\"}\"\n{}\n",
+			wantColumns: []string{"address"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, columns, err := csvdoc.Encode(decodeJSON(t, records), tt.opts)
+			if err != nil {
+				t.Fatalf("Encode() unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Encode() =\n%q\nwant\n%q", got, tt.want)
+			}
+			if !reflect.DeepEqual(columns, tt.wantColumns) {
+				t.Errorf("Encode() columns = %v, want %v", columns, tt.wantColumns)
+			}
+		})
+	}
+}
+
+func TestEncode_DottedKey(t *testing.T) {
+	records := []any{map[string]any{"a.b": "flat", "a": map[string]any{"c": "nested"}}}
+	got, _, err := csvdoc.Encode(records, csvdoc.EncodeOptions{Columns: []string{"a.b", "a.c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a.b,a.c\nflat,nested\n"; string(got) != want {
+		t.Errorf("Encode() = %q, want %q", got, want)
+	}
+}
+
+func TestEncode_NotAnObject(t *testing.T) {
+	_, _, err := csvdoc.Encode([]any{map[string]any{}, "text"}, csvdoc.EncodeOptions{})
+	if !errors.Is(err, domain.ErrInvalidCSV) {
+		t.Errorf("Encode() error = %v, want %v", err, domain.ErrInvalidCSV)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		opts        csvdoc.DecodeOptions
+		want        string
+		wantColumns []string
+		wantErr     error
+	}{
+		{
+			name:        "type inference",
+			input:       "\ufeffid,name,zip,active,score,note\n1,Acme,0150,true,-2.5e3,\n2,\"Beta, Ltd\",10001,false,0,n/a\n",
+			want:        `[{"id":1,"name":"Acme","zip":"0150","active":true,"score":-2.5e3,"note":null},{"id":2,"name":"Beta, Ltd","zip":10001,"active":false,"score":0,"note":"n/a"}]`,
+			wantColumns: []string{"id", "name", "zip", "active", "score", "note"},
+		},
+		{
+			name:  "keep strings",
+			input: "id,note\n1,\n",
+			opts:  csvdoc.DecodeOptions{KeepStrings: true},
+			want:  `[{"id":"1","note":""}]`,
+		},
+		{
+			name:  "nested columns and arrays",
+			input: "id;address.city;address.zip;tags\n1;Oslo;0150;b2b|eu|3\n2;;;\n",
+			opts:  csvdoc.DecodeOptions{Delimiter: ';', ArrayColumns: []string{"tags"}, ArraySeparator: "|"},
+			want:  `[{"id":1,"address":{"city":"Oslo","zip":"0150"},"tags":["b2b","eu",3]},{"id":2,"address":{"city":null,"zip":null},"tags":[]}]`,
+		},
+		{
+			name:        "empty input",
+			input:       "",
+			want:        `[]`,
+			wantColumns: []string{},
+		},
+		{
+			name:    "short row",
+			input:   "a,b\n1\n",
+			wantErr: domain.ErrInvalidCSV,
+		},
+		{
+			name:    "duplicate column",
+			input:   "a,a\n1,2\n",
+			wantErr: domain.ErrInvalidCSV,
+		},
+		{
+			name:    "empty column name",
+			input:   "a,\n1,2\n",
+			wantErr: domain.ErrInvalidCSV,
+		},
+		{
+			name:    "conflicting columns",
+			input:   "a,a.b\n1,2\n",
+			wantErr: domain.ErrInvalidCSV,
+		},
+		{
+			name:    "unterminated quote",
+			input:   "a\n\"open\n",
+			wantErr: domain.ErrInvalidCSV,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, columns, err := csvdoc.Decode([]byte(tt.input), tt.opts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error: %v", err)
+			}
+
+			var want []any
+			dec := json.NewDecoder(strings.NewReader(tt.want))
+			dec.UseNumber()
+			if err := dec.Decode(&want); err != nil {
+				t.Fatalf("invalid test JSON: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Decode() = %#v, want %#v", got, want)
+			}
+			if tt.wantColumns != nil && !reflect.DeepEqual(columns, tt.wantColumns) {
+				t.Errorf("Decode() columns = %v, want %v", columns, tt.wantColumns)
+			}
+		})
+	}
+}
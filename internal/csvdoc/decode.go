@@ -0,0 +1,157 @@
+package csvdoc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+)
+
+// jsonNumber matches a number in standard JSON form. Values with leading
+// zeros, such as postal codes, do not match and stay strings.
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// DecodeOptions controls how a CSV table is read into records.
+type DecodeOptions struct {
+	// Delimiter separates fields; zero means a comma.
+	Delimiter rune
+	// ArrayColumns lists the columns whose cells hold arrays joined with
+	// ArraySeparator.
+	ArrayColumns []string
+	// ArraySeparator splits array cells; empty means DefaultArraySeparator.
+	ArraySeparator string
+	// KeepStrings disables type inference, so every cell is a string.
+	KeepStrings bool
+}
+
+// Decode reads a CSV table whose first row names the columns and returns
+// one object per remaining row. Dot-separated column names build nested
+// objects. Unless KeepStrings is set, empty cells become null, true and
+// false become booleans and numbers in JSON form become json.Number.
+func Decode(src []byte, opts DecodeOptions) ([]any, []string, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(src, []byte("\ufeff"))))
+	if opts.Delimiter != 0 {
+		r.Comma = opts.Delimiter
+	}
+	r.ReuseRecord = true
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return []any{}, []string{}, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", domain.ErrInvalidCSV, err)
+	}
+	columns := append([]string(nil), header...)
+	if err := checkColumns(columns); err != nil {
+		return nil, nil, err
+	}
+
+	sep := opts.ArraySeparator
+	if sep == "" {
+		sep = DefaultArraySeparator
+	}
+	isArray := make(map[string]bool, len(opts.ArrayColumns))
+	for _, col := range opts.ArrayColumns {
+		isArray[col] = true
+	}
+
+	records := []any{}
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", domain.ErrInvalidCSV, err)
+		}
+
+		record := make(map[string]any, len(columns))
+		for i, col := range columns {
+			var value any
+			if isArray[col] {
+				items := []any{}
+				if row[i] != "" {
+					for _, item := range strings.Split(row[i], sep) {
+						items = append(items, cellValue(item, opts.KeepStrings))
+					}
+				}
+				value = items
+			} else {
+				value = cellValue(row[i], opts.KeepStrings)
+			}
+			if err := setPath(record, col, value); err != nil {
+				line, _ := r.FieldPos(i)
+				return nil, nil, fmt.Errorf("%w: line %d: %v", domain.ErrInvalidCSV, line, err)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, columns, nil
+}
+
+// checkColumns rejects empty and repeated column names.
+func checkColumns(columns []string) error {
+	seen := make(map[string]bool, len(columns))
+	for i, col := range columns {
+		if col == "" {
+			return fmt.Errorf("%w: column %d has no name", domain.ErrInvalidCSV, i+1)
+		}
+		if seen[col] {
+			return fmt.Errorf("%w: column %q appears more than once", domain.ErrInvalidCSV, col)
+		}
+		seen[col] = true
+	}
+	return nil
+}
+
+// cellValue infers the type of a cell.
+func cellValue(cell string, keepStrings bool) any {
+	if keepStrings {
+		return cell
+	}
+	switch {
+	case cell == "":
+		return nil
+	case cell == "true":
+		return true
+	case cell == "false":
+		return false
+	case jsonNumber.MatchString(cell):
+		return json.Number(cell)
+	}
+	return cell
+}
+
+// setPath stores value at a dot-separated path, creating nested objects.
+func setPath(record map[string]any, path string, value any) error {
+	parts := strings.Split(path, ".")
+	current := record
+	for _, part := range parts[:len(parts)-1] {
+		switch next := current[part].(type) {
+		case nil:
+			if _, exists := current[part]; exists {
+				return fmt.Errorf("column %q conflicts with column %q", path, part)
+			}
+			child := make(map[string]any)
+			current[part] = child
+			current = child
+		case map[string]any:
+			current = next
+		default:
+			return fmt.Errorf("column %q conflicts with column %q", path, part)
+		}
+	}
+	last := parts[len(parts)-1]
+	if _, exists := current[last]; exists {
+		return fmt.Errorf("column %q conflicts with another column", path)
+	}
+	current[last] = value
+	return nil
+}
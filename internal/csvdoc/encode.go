@@ -0,0 +1,175 @@
+// Package csvdoc converts between arrays of JSON objects and CSV tables.
+// Nested objects map to dot-separated column names such as "address.city"
+// and arrays of scalars to a single joined cell.
+package csvdoc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+)
+
+// DefaultArraySeparator joins the items of an array value in one cell.
+const DefaultArraySeparator = ";"
+
+// EncodeOptions controls how records are laid out as CSV.
+type EncodeOptions struct {
+	// Columns selects and orders the columns by dot-separated field path.
+	// When empty, every leaf field of the records becomes a column, in the
+	// order first seen, with the keys of each object sorted.
+	Columns []string
+	// Delimiter separates fields; zero means a comma.
+	Delimiter rune
+	// ArraySeparator joins arrays of scalars; empty means
+	// DefaultArraySeparator. Arrays holding objects or arrays are written
+	// as compact JSON.
+	ArraySeparator string
+}
+
+// Encode writes records, which must be JSON objects, as a CSV table with a
+// header row. It returns the table and its columns. Missing and null
+// fields are written as empty cells.
+func Encode(records []any, opts EncodeOptions) ([]byte, []string, error) {
+	objects := make([]map[string]any, len(records))
+	for i, record := range records {
+		obj, ok := record.(map[string]any)
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: record %d is not an object", domain.ErrInvalidCSV, i)
+		}
+		objects[i] = obj
+	}
+
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = leafColumns(objects)
+	}
+	sep := opts.ArraySeparator
+	if sep == "" {
+		sep = DefaultArraySeparator
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if opts.Delimiter != 0 {
+		w.Comma = opts.Delimiter
+	}
+	if err := w.Write(columns); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", domain.ErrInvalidCSV, err)
+	}
+	row := make([]string, len(columns))
+	for _, obj := range objects {
+		for i, col := range columns {
+			cell, err := formatCell(lookup(obj, col), sep)
+			if err != nil {
+				return nil, nil, err
+			}
+			row[i] = cell
+		}
+		if err := w.Write(row); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", domain.ErrInvalidCSV, err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", domain.ErrInvalidCSV, err)
+	}
+	return buf.Bytes(), columns, nil
+}
+
+// leafColumns lists the paths of the leaf fields of objects in the order
+// first seen. Nested objects are flattened, so an empty one adds no column;
+// arrays are leaves.
+func leafColumns(objects []map[string]any) []string {
+	var columns []string
+	seen := make(map[string]bool)
+	var walk func(obj map[string]any, prefix string)
+	walk = func(obj map[string]any, prefix string) {
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			path := prefix + k
+			if nested, ok := obj[k].(map[string]any); ok {
+				walk(nested, path+".")
+				continue
+			}
+			if !seen[path] {
+				seen[path] = true
+				columns = append(columns, path)
+			}
+		}
+	}
+	for _, obj := range objects {
+		walk(obj, "")
+	}
+	return columns
+}
+
+// lookup returns the value at a dot-separated path. A key that itself
+// contains dots matches before its parts do.
+func lookup(obj map[string]any, path string) any {
+	if v, ok := obj[path]; ok {
+		return v
+	}
+	for i := strings.Index(path, "."); i >= 0; {
+		if nested, ok := obj[path[:i]].(map[string]any); ok {
+			if v := lookup(nested, path[i+1:]); v != nil {
+				return v
+			}
+		}
+		next := strings.Index(path[i+1:], ".")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return nil
+}
+
+// formatCell renders a value as cell text.
+func formatCell(v any, sep string) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return val, nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	case json.Number:
+		return string(val), nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	case []any:
+		items := make([]string, len(val))
+		for i, item := range val {
+			switch item.(type) {
+			case map[string]any, []any:
+				return compactJSON(val)
+			}
+			cell, err := formatCell(item, sep)
+			if err != nil {
+				return "", err
+			}
+			items[i] = cell
+		}
+		return strings.Join(items, sep), nil
+	}
+	return compactJSON(v)
+}
+
+// compactJSON encodes a value as compact JSON.
+func compactJSON(v any) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", domain.ErrInvalidCSV, err)
+	}
+	return string(raw), nil
+}
@@ -14,6 +14,15 @@ var (
 	// ErrFileNotFound indicates the requested file does not exist
 	ErrFileNotFound = errors.New("file not found")
 
+	// ErrSecretDetected indicates content to write holds a likely credential
+	ErrSecretDetected = errors.New("possible secret in content")
+
+	// ErrFileExists indicates a file that was to be created already exists
+	ErrFileExists = errors.New("file already exists")
+
+	// ErrInvalidWriteMode indicates an unknown or disallowed write mode
+	ErrInvalidWriteMode = errors.New("invalid write mode")
+
 	// ErrWriteFailed indicates a file write operation failed
 	ErrWriteFailed = errors.New("write operation failed")
 
@@ -34,4 +43,82 @@ var (
 
 	// ErrInvalidFilter indicates a filter configuration is invalid
 	ErrInvalidFilter = errors.New("invalid filter configuration")
+
+	// ErrInvalidPointer indicates a JSON Pointer is syntactically invalid
+	ErrInvalidPointer = errors.New("invalid JSON pointer")
+
+	// ErrPointerNotFound indicates a JSON Pointer does not resolve to a value
+	ErrPointerNotFound = errors.New("JSON pointer target not found")
+
+	// ErrInvalidPatch indicates a JSON Patch document is malformed
+	ErrInvalidPatch = errors.New("invalid JSON patch")
+
+	// ErrPatchTestFailed indicates a JSON Patch test operation did not match
+	ErrPatchTestFailed = errors.New("JSON patch test operation failed")
+
+	// ErrInvalidUpdate indicates an update or upsert specification is invalid
+	ErrInvalidUpdate = errors.New("invalid update specification")
+
+	// ErrTooManyMatches indicates more elements matched than maxAffected allows
+	ErrTooManyMatches = errors.New("matched elements exceed maxAffected")
+
+	// ErrDuplicateKey indicates an item repeats a value of a unique key field
+	ErrDuplicateKey = errors.New("duplicate value for unique key")
+
+	// ErrInvalidConfig indicates the server configuration file is invalid
+	ErrInvalidConfig = errors.New("invalid configuration")
+
+	// ErrInvalidSchema indicates a JSON Schema could not be loaded or compiled
+	ErrInvalidSchema = errors.New("invalid JSON schema")
+
+	// ErrNoSchema indicates no JSON Schema applies to the document
+	ErrNoSchema = errors.New("no JSON schema applies to document")
+
+	// ErrSchemaViolation indicates a document does not satisfy its JSON Schema
+	ErrSchemaViolation = errors.New("document does not match JSON schema")
+
+	// ErrInvalidFormat indicates an unsupported output formatting option
+	ErrInvalidFormat = errors.New("invalid formatting option")
+
+	// ErrResultTooLarge indicates a query result exceeds the configured size ceiling
+	ErrResultTooLarge = errors.New("query result too large")
+
+	// ErrInvalidDiff indicates a diff request does not name exactly one comparison target
+	ErrInvalidDiff = errors.New("invalid diff request")
+
+	// ErrInvalidYAML indicates content is not valid YAML or cannot be mapped to JSON
+	ErrInvalidYAML = errors.New("invalid YAML content")
+
+	// ErrInvalidTOML indicates content is not valid TOML or a document cannot be written as TOML
+	ErrInvalidTOML = errors.New("invalid TOML content")
+
+	// ErrInvalidCSV indicates CSV content is malformed or records cannot be written as CSV
+	ErrInvalidCSV = errors.New("invalid CSV content")
+
+	// ErrInvalidExpression indicates a query expression is malformed or cannot be evaluated
+	ErrInvalidExpression = errors.New("invalid query expression")
+
+	// ErrOutsideWorkspace indicates a path resolves outside the allowed workspace roots
+	ErrOutsideWorkspace = errors.New("path is outside the workspace roots")
+
+	// ErrAccessDenied indicates the access policy denies the operation on the path
+	ErrAccessDenied = errors.New("access denied by policy")
+
+	// ErrExtensionNotAllowed indicates a tool may not write files with the path's extension
+	ErrExtensionNotAllowed = errors.New("file extension not allowed for this tool")
+
+	// ErrBinaryContent indicates content to write holds NUL bytes or invalid UTF-8
+	ErrBinaryContent = errors.New("binary content rejected")
+
+	// ErrReadTooLarge indicates a file is larger than the read size limit
+	ErrReadTooLarge = errors.New("file exceeds the read size limit")
+
+	// ErrWriteTooLarge indicates content is larger than the write size limit
+	ErrWriteTooLarge = errors.New("content exceeds the write size limit")
+
+	// ErrDepthExceeded indicates a document nests deeper than the depth limit
+	ErrDepthExceeded = errors.New("document nesting exceeds the depth limit")
+
+	// ErrQuotaExceeded indicates a write would take the workspace over its disk quota
+	ErrQuotaExceeded = errors.New("workspace disk quota exceeded")
 )
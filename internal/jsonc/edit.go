@@ -0,0 +1,684 @@
+package jsonc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonfmt"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpatch"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpointer"
+)
+
+// node is a value of a parsed document together with its byte span.
+type node struct {
+	start int
+	end   int
+	// kind is '{' or '[' for containers and 0 for scalars
+	kind    byte
+	members []member
+	elems   []*node
+}
+
+// member is an object member; start is the offset of its key.
+type member struct {
+	key   string
+	start int
+	value *node
+}
+
+// span is the extent of a container item: from its key, if any, to the end
+// of its value.
+type span struct {
+	start int
+	end   int
+}
+
+// items returns the spans of the members or elements of a container.
+func (n *node) items() []span {
+	var spans []span
+	for _, m := range n.members {
+		spans = append(spans, span{m.start, m.value.end})
+	}
+	for _, el := range n.elems {
+		spans = append(spans, span{el.start, el.end})
+	}
+	return spans
+}
+
+// parse builds the syntax tree of a JSONC or JSON5 document.
+func parse(src []byte) (*node, error) {
+	p := &parser{s: newScanner(bytes.NewReader(src))}
+	tok, err := p.s.next()
+	if err != nil {
+		return nil, err
+	}
+	root, err := p.value(tok)
+	if err != nil {
+		return nil, err
+	}
+	if tok, err = p.s.next(); err != nil {
+		return nil, err
+	}
+	if tok.kind != tokEOF {
+		return nil, p.s.errorf(tok.start, "unexpected data after top-level value")
+	}
+	return root, nil
+}
+
+// parser is a recursive descent parser over the scanner's tokens.
+type parser struct {
+	s *scanner
+}
+
+// value parses the value starting with tok.
+func (p *parser) value(tok token) (*node, error) {
+	switch tok.kind {
+	case tokDelim:
+		switch tok.delim {
+		case '{':
+			return p.object(tok)
+		case '[':
+			return p.array(tok)
+		}
+	case tokString, tokNumber, tokLiteral:
+		return &node{start: int(tok.start), end: int(tok.end)}, nil
+	case tokIdent:
+		return nil, p.s.identValueError(tok)
+	}
+	return nil, p.unexpected(tok, "value")
+}
+
+// object parses the rest of an object after its opening brace.
+func (p *parser) object(open token) (*node, error) {
+	n := &node{start: int(open.start), kind: '{'}
+	for {
+		key, err := p.s.next()
+		if err != nil {
+			return nil, err
+		}
+		if key.kind == tokDelim && key.delim == '}' {
+			n.end = int(key.end)
+			return n, nil
+		}
+		if key.kind != tokString && key.kind != tokIdent && key.kind != tokLiteral {
+			return nil, p.unexpected(key, "object key")
+		}
+
+		colon, err := p.s.next()
+		if err != nil {
+			return nil, err
+		}
+		if colon.kind != tokDelim || colon.delim != ':' {
+			return nil, p.unexpected(colon, "':'")
+		}
+		tok, err := p.s.next()
+		if err != nil {
+			return nil, err
+		}
+		value, err := p.value(tok)
+		if err != nil {
+			return nil, err
+		}
+		n.members = append(n.members, member{key: key.value, start: int(key.start), value: value})
+
+		if done, err := p.separator(n, '}'); err != nil || done {
+			return n, err
+		}
+	}
+}
+
+// array parses the rest of an array after its opening bracket.
+func (p *parser) array(open token) (*node, error) {
+	n := &node{start: int(open.start), kind: '['}
+	for {
+		tok, err := p.s.next()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind == tokDelim && tok.delim == ']' {
+			n.end = int(tok.end)
+			return n, nil
+		}
+		elem, err := p.value(tok)
+		if err != nil {
+			return nil, err
+		}
+		n.elems = append(n.elems, elem)
+
+		if done, err := p.separator(n, ']'); err != nil || done {
+			return n, err
+		}
+	}
+}
+
+// separator reads the comma or closing delimiter after a container item
+// and reports whether the container ended.
+func (p *parser) separator(n *node, closing byte) (bool, error) {
+	tok, err := p.s.next()
+	if err != nil {
+		return false, err
+	}
+	if tok.kind == tokDelim && tok.delim == closing {
+		n.end = int(tok.end)
+		return true, nil
+	}
+	if tok.kind != tokDelim || tok.delim != ',' {
+		return false, p.unexpected(tok, fmt.Sprintf("',' or '%c'", closing))
+	}
+	return false, nil
+}
+
+// unexpected reports a token that does not fit the grammar.
+func (p *parser) unexpected(tok token, want string) error {
+	if tok.kind == tokEOF {
+		return p.s.errorf(tok.start, "unexpected end of input")
+	}
+	return p.s.errorf(tok.start, "expected %s, found %s", want, tok.text)
+}
+
+// Decode parses a JSONC or JSON5 document. Numbers are decoded as
+// json.Number.
+func Decode(src []byte) (any, error) {
+	dec := json.NewDecoder(NewReader(bytes.NewReader(src)))
+	dec.UseNumber()
+
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		if !errors.Is(err, domain.ErrInvalidJSON) {
+			err = fmt.Errorf("%w: %v", domain.ErrInvalidJSON, err)
+		}
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("%w: unexpected data after top-level value", domain.ErrInvalidJSON)
+	}
+	return doc, nil
+}
+
+// Patch applies RFC 6902 operations to a JSONC or JSON5 document by editing
+// its text, so that comments, indentation and the order of untouched
+// members are kept. New values are laid out like their siblings. The
+// operations are first checked against the decoded document with
+// jsonpatch.Apply, whose error is returned if they do not apply.
+func Patch(src []byte, ops []jsonpatch.Operation) ([]byte, error) {
+	doc, err := Decode(src)
+	if err != nil {
+		return nil, err
+	}
+	want, err := jsonpatch.Apply(doc, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &editor{src: append([]byte(nil), src...)}
+	if err := e.detectLayout(); err != nil {
+		return nil, err
+	}
+	for i, op := range ops {
+		if err := e.apply(op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	// The text edits must decode to exactly the patched document
+	got, err := Decode(e.src)
+	if err != nil || !jsonpatch.Equal(got, want) {
+		return nil, fmt.Errorf("%w: could not apply the patch without reformatting the document", domain.ErrInvalidPatch)
+	}
+	return e.src, nil
+}
+
+// editor applies patch operations to document text.
+type editor struct {
+	src []byte
+	// pretty is set for multi-line documents
+	pretty bool
+	// unit is the indentation unit of the document
+	unit string
+}
+
+// render produces the text of an inserted value for the given indentation
+// of its line and whether it may span several lines.
+type render func(indent string, multiline bool) (string, error)
+
+// detectLayout records whether the document is multi-line and its
+// indentation unit, taken from the first member of the root container.
+func (e *editor) detectLayout() error {
+	root, err := parse(e.src)
+	if err != nil {
+		return err
+	}
+	e.pretty = bytes.Contains(bytes.TrimSpace(e.src), []byte("\n"))
+	e.unit = "  "
+	if items := root.items(); len(items) > 0 && e.ownLine(items[0].start) {
+		indent := strings.TrimPrefix(e.indentAt(items[0].start), e.indentAt(root.start))
+		if indent != "" {
+			e.unit = indent
+		}
+	}
+	return nil
+}
+
+// apply performs one operation on the text.
+func (e *editor) apply(op jsonpatch.Operation) error {
+	root, err := parse(e.src)
+	if err != nil {
+		return err
+	}
+	path, err := jsonpointer.Parse(op.Path)
+	if err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case "add":
+		return e.add(root, path, e.encoder(op.Value))
+
+	case "replace":
+		parent, _, target, err := find(root, path)
+		if err != nil {
+			return err
+		}
+		return e.replace(parent, target, e.encoder(op.Value))
+
+	case "remove":
+		parent, idx, _, err := find(root, path)
+		if err != nil {
+			return err
+		}
+		e.remove(parent, idx)
+		return nil
+
+	case "move", "copy":
+		from, err := jsonpointer.Parse(op.From)
+		if err != nil {
+			return err
+		}
+		parent, idx, source, err := find(root, from)
+		if err != nil {
+			return err
+		}
+		// Carry the raw text, comments included, re-indented for its new place
+		raw := string(e.src[source.start:source.end])
+		oldIndent := e.indentAt(source.start)
+		text := func(indent string, _ bool) (string, error) {
+			return strings.ReplaceAll(raw, "\n"+oldIndent, "\n"+indent), nil
+		}
+		if op.Op == "move" {
+			e.remove(parent, idx)
+			if root, err = parse(e.src); err != nil {
+				return err
+			}
+		}
+		return e.add(root, path, text)
+
+	case "test":
+		return nil
+	}
+	return fmt.Errorf("%w: unknown operation %q", domain.ErrInvalidPatch, op.Op)
+}
+
+// encoder renders v in the document's style.
+func (e *editor) encoder(v any) render {
+	return func(indent string, multiline bool) (string, error) {
+		if !e.pretty || !multiline {
+			return jsonfmt.Encode(v, jsonfmt.Options{Style: jsonfmt.StyleCompact})
+		}
+		s, err := jsonfmt.Encode(v, jsonfmt.Options{Style: jsonfmt.StylePretty, Indent: e.unit})
+		if err != nil {
+			return "", err
+		}
+		return strings.ReplaceAll(s, "\n", "\n"+indent), nil
+	}
+}
+
+// find locates the value at path, its parent container and its index
+// there. The parent of the root is nil.
+func find(root *node, path jsonpointer.Pointer) (*node, int, *node, error) {
+	var parent *node
+	idx := -1
+	current := root
+	for _, token := range path {
+		parent = current
+		switch current.kind {
+		case '{':
+			idx = -1
+			// The last of duplicate keys wins, as when decoding
+			for i, m := range current.members {
+				if m.key == token {
+					idx = i
+				}
+			}
+			if idx < 0 {
+				return nil, 0, nil, fmt.Errorf("%w: member %q not found", domain.ErrPointerNotFound, token)
+			}
+			current = current.members[idx].value
+		case '[':
+			i, err := jsonpointer.ArrayIndex(token, len(current.elems))
+			if err != nil {
+				return nil, 0, nil, err
+			}
+			idx = i
+			current = current.elems[i]
+		default:
+			return nil, 0, nil, fmt.Errorf("%w: cannot descend into a scalar", domain.ErrPointerNotFound)
+		}
+	}
+	return parent, idx, current, nil
+}
+
+// add inserts a value at path, replacing an existing object member.
+func (e *editor) add(root *node, path jsonpointer.Pointer, text render) error {
+	if len(path) == 0 {
+		return e.replace(nil, root, text)
+	}
+	_, _, parent, err := find(root, path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	last := path[len(path)-1]
+
+	switch parent.kind {
+	case '{':
+		for i := len(parent.members) - 1; i >= 0; i-- {
+			if parent.members[i].key == last {
+				return e.replace(parent, parent.members[i].value, text)
+			}
+		}
+		key, err := jsonfmt.Encode(last, jsonfmt.Options{Style: jsonfmt.StyleCompact})
+		if err != nil {
+			return err
+		}
+		colon := ":"
+		if e.pretty {
+			colon = ": "
+		}
+		return e.insert(parent, len(parent.members), func(indent string, multiline bool) (string, error) {
+			value, err := text(indent, multiline)
+			return key + colon + value, err
+		})
+	case '[':
+		idx, err := jsonpointer.InsertIndex(last, len(parent.elems))
+		if err != nil {
+			return err
+		}
+		return e.insert(parent, idx, text)
+	}
+	return fmt.Errorf("%w: cannot add to a scalar", domain.ErrPointerNotFound)
+}
+
+// replace substitutes the text of target, a value inside parent.
+func (e *editor) replace(parent, target *node, text render) error {
+	multiline := e.pretty
+	if parent != nil {
+		multiline = e.multiline(parent)
+	}
+	value, err := text(e.indentAt(target.start), multiline)
+	if err != nil {
+		return err
+	}
+	e.splice(target.start, target.end, value)
+	return nil
+}
+
+// insert adds an item to container n before index i.
+func (e *editor) insert(n *node, i int, text render) error {
+	items := n.items()
+	if len(items) == 0 {
+		blank := isBlank(e.src[n.start+1 : n.end-1])
+		inner := n.end - 1
+		if !blank {
+			// Keep comments inside the empty container after the new item
+			inner = n.start + 1
+		}
+		if !e.pretty {
+			value, err := text("", false)
+			if err != nil {
+				return err
+			}
+			e.splice(n.start+1, inner, value)
+			return nil
+		}
+		indent := e.indentAt(n.start)
+		value, err := text(indent+e.unit, true)
+		if err != nil {
+			return err
+		}
+		closing := ""
+		if blank {
+			closing = "\n" + indent
+		}
+		e.splice(n.start+1, inner, "\n"+indent+e.unit+value+closing)
+		return nil
+	}
+
+	multiline := e.multiline(n)
+	indent := ""
+	if multiline {
+		indent = e.indentAt(items[0].start)
+	}
+	value, err := text(indent, multiline)
+	if err != nil {
+		return err
+	}
+
+	if i < len(items) {
+		next := items[i]
+		if multiline && e.ownLine(next.start) {
+			// Insert above the next item and any comments leading it
+			at := e.leadingStart(next.start)
+			e.splice(at, at, indent+value+",\n")
+		} else {
+			e.splice(next.start, next.start, value+","+e.separator(items))
+		}
+		return nil
+	}
+
+	last := items[len(items)-1]
+	comma := e.commaAfter(last.end)
+	if !multiline {
+		if comma >= 0 {
+			e.splice(comma+1, comma+1, e.separator(items)+value+",")
+		} else {
+			e.splice(last.end, last.end, ","+e.separator(items)+value)
+		}
+		return nil
+	}
+
+	// Append on a new line after any comment trailing the last item
+	end := e.lineEnd(last.end, comma)
+	if comma >= 0 {
+		e.splice(end, end, "\n"+indent+value+",")
+	} else {
+		e.splice(end, end, "\n"+indent+value)
+		e.splice(last.end, last.end, ",")
+	}
+	return nil
+}
+
+// remove deletes item i of container n with its comma. An item on a line
+// of its own is removed together with that line, its trailing comment and
+// any comment lines leading it.
+func (e *editor) remove(n *node, i int) {
+	items := n.items()
+	item := items[i]
+	comma := e.commaAfter(item.end)
+	end := e.lineEnd(item.end, comma)
+
+	if e.ownLine(item.start) && (end == len(e.src) || e.src[end] == '\n' || e.src[end] == '\r') {
+		start := e.leadingStart(item.start)
+		if end < len(e.src) && e.src[end] == '\r' {
+			end++
+		}
+		if end < len(e.src) && e.src[end] == '\n' {
+			end++
+		}
+
+		// Collapse a container left with nothing but whitespace
+		if len(items) == 1 && isBlank(e.src[n.start+1:start]) && isBlank(e.src[end:n.end-1]) {
+			e.splice(n.start+1, n.end-1, "")
+			return
+		}
+		e.splice(start, end, "")
+		if comma < 0 && i > 0 {
+			// The previous item is now the last; drop its comma
+			if prev := e.commaAfter(items[i-1].end); prev >= 0 {
+				e.splice(prev, prev+1, "")
+			}
+		}
+		return
+	}
+
+	switch {
+	case i+1 < len(items):
+		e.splice(item.start, items[i+1].start, "")
+	case i > 0:
+		e.splice(items[i-1].end, item.end, "")
+	default:
+		if comma >= 0 {
+			item.end = comma + 1
+		}
+		e.splice(item.start, item.end, "")
+	}
+}
+
+// multiline reports whether the items of container n sit on lines of
+// their own.
+func (e *editor) multiline(n *node) bool {
+	items := n.items()
+	if len(items) == 0 {
+		return e.pretty
+	}
+	return e.ownLine(items[0].start)
+}
+
+// separator returns the whitespace between the items of a single-line
+// container.
+func (e *editor) separator(items []span) string {
+	if len(items) >= 2 {
+		if comma := e.commaAfter(items[0].end); comma >= 0 {
+			if between := e.src[comma+1 : items[1].start]; isBlank(between) && !bytes.ContainsAny(between, "\r\n") {
+				return string(between)
+			}
+		}
+	}
+	if e.pretty {
+		return " "
+	}
+	return ""
+}
+
+// splice replaces src[start:end] with text.
+func (e *editor) splice(start, end int, text string) {
+	e.src = append(e.src[:start:start], append([]byte(text), e.src[end:]...)...)
+}
+
+// commaAfter returns the offset of the comma following the value ending at
+// pos, skipping whitespace and comments, or -1.
+func (e *editor) commaAfter(pos int) int {
+	pos = e.skipTrivia(pos)
+	if pos < len(e.src) && e.src[pos] == ',' {
+		return pos
+	}
+	return -1
+}
+
+// lineEnd returns the offset after an item's comma and any comment
+// trailing it on the same line.
+func (e *editor) lineEnd(end, comma int) int {
+	pos := end
+	if comma >= 0 && !bytes.ContainsAny(e.src[end:comma], "\r\n") {
+		pos = comma + 1
+	}
+	pos = e.skipBlank(pos)
+	rest := e.src[pos:]
+	switch {
+	case bytes.HasPrefix(rest, []byte("//")):
+		if nl := bytes.IndexAny(rest, "\r\n"); nl >= 0 {
+			return pos + nl
+		}
+		return len(e.src)
+	case bytes.HasPrefix(rest, []byte("/*")):
+		close := bytes.Index(rest, []byte("*/"))
+		if close >= 0 && !bytes.ContainsAny(rest[:close], "\r\n") {
+			return e.skipBlank(pos + close + 2)
+		}
+	}
+	return pos
+}
+
+// skipBlank skips spaces and tabs.
+func (e *editor) skipBlank(pos int) int {
+	for pos < len(e.src) && (e.src[pos] == ' ' || e.src[pos] == '\t') {
+		pos++
+	}
+	return pos
+}
+
+// skipTrivia skips whitespace and comments.
+func (e *editor) skipTrivia(pos int) int {
+	for pos < len(e.src) {
+		rest := e.src[pos:]
+		switch {
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\n' || rest[0] == '\r':
+			pos++
+		case bytes.HasPrefix(rest, []byte("//")):
+			nl := bytes.IndexByte(rest, '\n')
+			if nl < 0 {
+				return len(e.src)
+			}
+			pos += nl + 1
+		case bytes.HasPrefix(rest, []byte("/*")):
+			close := bytes.Index(rest[2:], []byte("*/"))
+			if close < 0 {
+				return len(e.src)
+			}
+			pos += close + 4
+		default:
+			return pos
+		}
+	}
+	return pos
+}
+
+// lineStart returns the offset of the start of the line containing pos.
+func (e *editor) lineStart(pos int) int {
+	return bytes.LastIndexByte(e.src[:pos], '\n') + 1
+}
+
+// indentAt returns the leading whitespace of the line containing pos.
+func (e *editor) indentAt(pos int) string {
+	start := e.lineStart(pos)
+	end := e.skipBlank(start)
+	return string(e.src[start:end])
+}
+
+// ownLine reports whether only whitespace precedes pos on its line.
+func (e *editor) ownLine(pos int) bool {
+	return isBlank(e.src[e.lineStart(pos):pos])
+}
+
+// leadingStart returns the start of the line holding the item at pos,
+// extended upwards over the comment lines directly above it.
+func (e *editor) leadingStart(pos int) int {
+	start := e.lineStart(pos)
+	for start > 0 {
+		prev := e.lineStart(start - 1)
+		line := bytes.TrimSpace(e.src[prev : start-1])
+		if !bytes.HasPrefix(line, []byte("//")) &&
+			!(bytes.HasPrefix(line, []byte("/*")) && bytes.HasSuffix(line, []byte("*/"))) {
+			break
+		}
+		start = prev
+	}
+	return start
+}
+
+// isBlank reports whether b holds only whitespace.
+func isBlank(b []byte) bool {
+	return len(bytes.TrimSpace(b)) == 0
+}
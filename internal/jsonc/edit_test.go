@@ -0,0 +1,284 @@
+package jsonc_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonc"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpatch"
+)
+
+const tsconfig = `{
+  // Compiler options
+  "compilerOptions": {
+    "target": "es2020", // language level
+    "strict": true,
+  },
+  /* sources */
+  "include": ["src"],
+  "exclude": [
+    "node_modules",
+    "dist"
+  ]
+}
+`
+
+func TestPatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		patch string
+		want  string
+	}{
+		{
+			name:  "replace keeps trailing comment",
+			input: tsconfig,
+			patch: `[{"op": "replace", "path": "/compilerOptions/target", "value": "es2022"}]`,
+			want: `{
+  // Compiler options
+  "compilerOptions": {
+    "target": "es2022", // language level
+    "strict": true,
+  },
+  /* sources */
+  "include": ["src"],
+  "exclude": [
+    "node_modules",
+    "dist"
+  ]
+}
+`,
+		},
+		{
+			name:  "add member after trailing comma",
+			input: tsconfig,
+			patch: `[{"op": "add", "path": "/compilerOptions/outDir", "value": "build"}]`,
+			want: `{
+  // Compiler options
+  "compilerOptions": {
+    "target": "es2020", // language level
+    "strict": true,
+    "outDir": "build",
+  },
+  /* sources */
+  "include": ["src"],
+  "exclude": [
+    "node_modules",
+    "dist"
+  ]
+}
+`,
+		},
+		{
+			name:  "add member without trailing comma",
+			input: tsconfig,
+			patch: `[{"op": "add", "path": "/references", "value": [{"path": "./lib"}]}]`,
+			want: `{
+  // Compiler options
+  "compilerOptions": {
+    "target": "es2020", // language level
+    "strict": true,
+  },
+  /* sources */
+  "include": ["src"],
+  "exclude": [
+    "node_modules",
+    "dist"
+  ],
+  "references": [
+    {
+      "path": "./lib"
+    }
+  ]
+}
+`,
+		},
+		{
+			name:  "remove member with its leading comment",
+			input: tsconfig,
+			patch: `[{"op": "remove", "path": "/compilerOptions"}]`,
+			want: `{
+  /* sources */
+  "include": ["src"],
+  "exclude": [
+    "node_modules",
+    "dist"
+  ]
+}
+`,
+		},
+		{
+			name:  "remove last element drops the previous comma",
+			input: tsconfig,
+			patch: `[{"op": "remove", "path": "/exclude/1"}]`,
+			want: `{
+  // Compiler options
+  "compilerOptions": {
+    "target": "es2020", // language level
+    "strict": true,
+  },
+  /* sources */
+  "include": ["src"],
+  "exclude": [
+    "node_modules"
+  ]
+}
+`,
+		},
+		{
+			name:  "single-line array",
+			input: tsconfig,
+			patch: `[
+				{"op": "add", "path": "/include/-", "value": "test"},
+				{"op": "add", "path": "/include/0", "value": "types"}
+			]`,
+			want: `{
+  // Compiler options
+  "compilerOptions": {
+    "target": "es2020", // language level
+    "strict": true,
+  },
+  /* sources */
+  "include": ["types", "src", "test"],
+  "exclude": [
+    "node_modules",
+    "dist"
+  ]
+}
+`,
+		},
+		{
+			name:  "insert and move elements",
+			input: tsconfig,
+			patch: `[
+				{"op": "add", "path": "/exclude/1", "value": "tmp"},
+				{"op": "move", "from": "/exclude/0", "path": "/exclude/2"}
+			]`,
+			want: `{
+  // Compiler options
+  "compilerOptions": {
+    "target": "es2020", // language level
+    "strict": true,
+  },
+  /* sources */
+  "include": ["src"],
+  "exclude": [
+    "tmp",
+    "dist",
+    "node_modules"
+  ]
+}
+`,
+		},
+		{
+			name:  "emptied container collapses",
+			input: "{\n  \"a\": [\n    1\n  ]\n}",
+			patch: `[{"op": "remove", "path": "/a/0"}]`,
+			want:  "{\n  \"a\": []\n}",
+		},
+		{
+			name:  "add to empty object",
+			input: "{\n  // nothing yet\n  \"a\": {}\n}",
+			patch: `[{"op": "add", "path": "/a/b", "value": 1}]`,
+			want:  "{\n  // nothing yet\n  \"a\": {\n    \"b\": 1\n  }\n}",
+		},
+		{
+			name:  "JSON5 document",
+			input: "{\n\tname: 'app', // the name\n\tport: 0x1F90,\n}",
+			patch: `[{"op": "replace", "path": "/port", "value": 9000}, {"op": "add", "path": "/debug", "value": {"level": 2}}]`,
+			want:  "{\n\tname: 'app', // the name\n\tport: 9000,\n\t\"debug\": {\n\t\t\"level\": 2\n\t},\n}",
+		},
+		{
+			name:  "compact document",
+			input: `{"a":1,/*x*/"b":[1,2]}`,
+			patch: `[{"op": "remove", "path": "/a"}, {"op": "add", "path": "/b/-", "value": 3}]`,
+			want:  `{"b":[1,2,3]}`,
+		},
+		{
+			name:  "replace root",
+			input: "// header\n[1]\n",
+			patch: `[{"op": "replace", "path": "", "value": {"a": 1}}]`,
+			want:  "// header\n{\n  \"a\": 1\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ops []jsonpatch.Operation
+			if err := json.Unmarshal([]byte(tt.patch), &ops); err != nil {
+				t.Fatalf("invalid test patch: %v", err)
+			}
+			got, err := jsonc.Patch([]byte(tt.input), ops)
+			if err != nil {
+				t.Fatalf("Patch() unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Patch() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatch_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		patch   string
+		wantErr error
+	}{
+		{
+			name:    "missing path",
+			input:   `{"a": 1, // c` + "\n}",
+			patch:   `[{"op": "remove", "path": "/b"}]`,
+			wantErr: domain.ErrPointerNotFound,
+		},
+		{
+			name:    "failed test",
+			input:   `{"a": 1,}`,
+			patch:   `[{"op": "test", "path": "/a", "value": 2}]`,
+			wantErr: domain.ErrPatchTestFailed,
+		},
+		{
+			name:    "invalid document",
+			input:   `{"a": 1 /* open`,
+			patch:   `[]`,
+			wantErr: domain.ErrInvalidJSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ops []jsonpatch.Operation
+			if err := json.Unmarshal([]byte(tt.patch), &ops); err != nil {
+				t.Fatalf("invalid test patch: %v", err)
+			}
+			if _, err := jsonc.Patch([]byte(tt.input), ops); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Patch() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPatch_Diff(t *testing.T) {
+	// Edits computed by Diff reproduce the target document
+	input := "[\n  // first\n  {\"id\": 1, \"v\": \"a\"},\n  {\"id\": 2, \"v\": \"b\"}, // second\n  {\"id\": 3, \"v\": \"c\"},\n]\n"
+	from, err := jsonc.Decode([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var to any
+	if err := json.Unmarshal([]byte(`[{"id": 3, "v": "c"}, {"id": 1, "v": "z"}, {"id": 4, "v": "d"}]`), &to); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := jsonc.Patch([]byte(input), jsonpatch.Diff(from, to, jsonpatch.DiffOptions{ArrayKey: "id"}))
+	if err != nil {
+		t.Fatalf("Patch() unexpected error: %v", err)
+	}
+	want := "[\n  {\"id\": 3, \"v\": \"c\"},\n  // first\n  {\"id\": 1, \"v\": \"z\"},\n  {\n    \"id\": 4,\n    \"v\": \"d\"\n  },\n]\n"
+	if string(got) != want {
+		t.Errorf("Patch() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,147 @@
+package jsonc
+
+import (
+	"bytes"
+	"io"
+)
+
+// translator is an io.Reader producing the standard JSON form of a JSONC or
+// JSON5 text. Comments and trailing commas are dropped and the JSON5-only
+// forms are rewritten; the output is otherwise compact. Syntax errors that
+// are not specific to the relaxed dialects, such as a missing colon, are
+// passed through for the JSON decoder reading the output to report.
+type translator struct {
+	s   *scanner
+	out []byte
+	err error
+
+	// stack holds the opening delimiters of the enclosing containers
+	stack []byte
+	// expectKey is set where an object key may appear
+	expectKey bool
+	// afterValue is set once a complete value has been written
+	afterValue bool
+	// pendingComma holds back a comma until it is known not to be trailing
+	pendingComma bool
+}
+
+// NewReader returns a reader that translates the JSONC or JSON5 text read
+// from r into standard JSON as it goes, so that large documents can be
+// streamed. Standard JSON input passes through unchanged apart from
+// whitespace. Errors specific to the relaxed syntax, such as an unterminated
+// comment, wrap domain.ErrInvalidJSON.
+func NewReader(r io.Reader) io.Reader {
+	return &translator{s: newScanner(r)}
+}
+
+// Translate converts a JSONC or JSON5 document to standard JSON.
+func Translate(src []byte) ([]byte, error) {
+	return io.ReadAll(NewReader(bytes.NewReader(src)))
+}
+
+// Read implements io.Reader.
+func (t *translator) Read(p []byte) (int, error) {
+	for len(t.out) == 0 && t.err == nil {
+		t.err = t.step()
+	}
+	if len(t.out) > 0 {
+		n := copy(p, t.out)
+		t.out = t.out[n:]
+		return n, nil
+	}
+	return 0, t.err
+}
+
+// step translates the next token.
+func (t *translator) step() error {
+	tok, err := t.s.next()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case tok.kind == tokEOF:
+		t.flushComma()
+		return io.EOF
+
+	case tok.kind == tokDelim && tok.delim == ',':
+		// A comma directly after an opening delimiter or another comma is
+		// passed on for the decoder to reject
+		if !t.afterValue || t.pendingComma {
+			t.flushComma()
+			t.write(",")
+			return nil
+		}
+		t.pendingComma = true
+		t.afterValue = false
+		t.expectKey = t.inObject()
+		return nil
+
+	case tok.kind == tokDelim && (tok.delim == '}' || tok.delim == ']'):
+		// Drop a trailing comma
+		t.pendingComma = false
+		if len(t.stack) > 0 {
+			t.stack = t.stack[:len(t.stack)-1]
+		}
+		t.write(tok.text)
+		t.afterValue = true
+		t.expectKey = false
+		return nil
+	}
+
+	t.flushComma()
+	switch tok.kind {
+	case tokDelim:
+		t.write(tok.text)
+		t.afterValue = false
+		switch tok.delim {
+		case '{':
+			t.stack = append(t.stack, '{')
+			t.expectKey = true
+		case '[':
+			t.stack = append(t.stack, '[')
+			t.expectKey = false
+		case ':':
+			t.expectKey = false
+		}
+		return nil
+
+	case tokLiteral:
+		if t.expectKey {
+			// Reserved words are valid unquoted keys
+			t.write(`"` + tok.value + `"`)
+		} else {
+			t.write(tok.text)
+		}
+
+	case tokIdent:
+		if !t.expectKey {
+			return t.s.identValueError(tok)
+		}
+		t.write(tok.text)
+
+	default:
+		t.write(tok.text)
+	}
+	t.afterValue = !t.expectKey
+	t.expectKey = false
+	return nil
+}
+
+// flushComma writes a held-back comma.
+func (t *translator) flushComma() {
+	if t.pendingComma {
+		t.write(",")
+		t.pendingComma = false
+	}
+}
+
+// inObject reports whether the innermost container is an object.
+func (t *translator) inObject() bool {
+	return len(t.stack) > 0 && t.stack[len(t.stack)-1] == '{'
+}
+
+// write appends translated output.
+func (t *translator) write(s string) {
+	t.out = append(t.out, s...)
+}
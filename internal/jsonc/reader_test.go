@@ -0,0 +1,173 @@
+package jsonc_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonc"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "standard JSON is compacted",
+			input: "{\n  \"a\": [1, 2.5e3, true, null],\n  \"b\": \"x\\u00e9\"\n}\n",
+			want:  `{"a":[1,2.5e3,true,null],"b":"xé"}`,
+		},
+		{
+			name:  "line and block comments",
+			input: "// header\n{ /* inline */ \"a\": 1 // trailing\n}",
+			want:  `{"a":1}`,
+		},
+		{
+			name:  "trailing commas",
+			input: `{"a": [1, 2, ], "b": {"c": 3,},}`,
+			want:  `{"a":[1,2],"b":{"c":3}}`,
+		},
+		{
+			name:  "comment between trailing comma and closing bracket",
+			input: "[1, // last\n]",
+			want:  `[1]`,
+		},
+		{
+			name:  "JSON5 strings",
+			input: `['it\'s', "say \"hi\"", 'tab\there', 'cont\` + "\n" + `inued', '\x41é']`,
+			want:  `["it's","say \"hi\"","tab\there","continued","Aé"]`,
+		},
+		{
+			name:  "JSON5 unquoted keys",
+			input: `{name: 1, $id: 2, _x9: 3, null: 4}`,
+			want:  `{"name":1,"$id":2,"_x9":3,"null":4}`,
+		},
+		{
+			name:  "JSON5 numbers",
+			input: `[0x1F, -0XFF, +3, .5, 5., -.25e2, 1.e2]`,
+			want:  `[31,-255,3,0.5,5,-0.25e2,1e2]`,
+		},
+		{
+			name:  "surrogate pair escape",
+			input: `"\ud83d\ude00"`,
+			want:  `"😀"`,
+		},
+		{
+			name:  "byte order mark",
+			input: "\xEF\xBB\xBF{\"a\": 1}",
+			want:  `{"a":1}`,
+		},
+		{
+			name:    "Infinity",
+			input:   `{"a": Infinity}`,
+			wantErr: domain.ErrInvalidJSON,
+		},
+		{
+			name:    "negative NaN",
+			input:   `[-NaN]`,
+			wantErr: domain.ErrInvalidJSON,
+		},
+		{
+			name:    "unquoted value",
+			input:   `{"a": yes}`,
+			wantErr: domain.ErrInvalidJSON,
+		},
+		{
+			name:    "unterminated comment",
+			input:   `{"a": 1} /* open`,
+			wantErr: domain.ErrInvalidJSON,
+		},
+		{
+			name:    "unterminated string",
+			input:   `{"a": 'open}`,
+			wantErr: domain.ErrInvalidJSON,
+		},
+		{
+			name:    "leading zero",
+			input:   `[007]`,
+			wantErr: domain.ErrInvalidJSON,
+		},
+		{
+			name:    "single slash",
+			input:   `[1 / 2]`,
+			wantErr: domain.ErrInvalidJSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonc.Translate([]byte(tt.input))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Translate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Translate() unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Translate() = %s, want %s", got, tt.want)
+			}
+			if !json.Valid(got) {
+				t.Errorf("Translate() output is not valid JSON: %s", got)
+			}
+		})
+	}
+}
+
+func TestTranslate_InvalidStructureIsKept(t *testing.T) {
+	// Errors outside the relaxed syntax are left for the JSON decoder
+	for _, input := range []string{`[1,,2]`, `[,]`, `{"a" 1}`, `{"a": 1,,}`} {
+		got, err := jsonc.Translate([]byte(input))
+		if err != nil {
+			t.Fatalf("Translate(%s) unexpected error: %v", input, err)
+		}
+		if json.Valid(got) {
+			t.Errorf("Translate(%s) = %s, want invalid JSON", input, got)
+		}
+	}
+}
+
+func TestNewReader_SmallReads(t *testing.T) {
+	r := jsonc.NewReader(strings.NewReader("[1, // one\n 2, /* two */ 3,]"))
+
+	var sb strings.Builder
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		sb.Write(buf[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read() unexpected error: %v", err)
+		}
+	}
+	if sb.String() != "[1,2,3]" {
+		t.Errorf("read %s, want [1,2,3]", sb.String())
+	}
+}
+
+func TestDecode(t *testing.T) {
+	got, err := jsonc.Decode([]byte("{\n  // comment\n  big: 12345678901234567890,\n}"))
+	if err != nil {
+		t.Fatalf("Decode() unexpected error: %v", err)
+	}
+	want := map[string]any{"big": json.Number("12345678901234567890")}
+	if got.(map[string]any)["big"] != want["big"] {
+		t.Errorf("Decode() = %v, want %v", got, want)
+	}
+
+	for _, input := range []string{``, `{"a": 1} {"b": 2}`, `{"a" 1}`} {
+		if _, err := jsonc.Decode([]byte(input)); !errors.Is(err, domain.ErrInvalidJSON) {
+			t.Errorf("Decode(%q) error = %v, want %v", input, err, domain.ErrInvalidJSON)
+		}
+	}
+}
@@ -0,0 +1,399 @@
+// Package jsonc reads the relaxed JSON dialects used by configuration files:
+// JSONC (JSON with comments and trailing commas, as in tsconfig.json) and
+// JSON5 (which adds single-quoted strings, unquoted keys and more number
+// forms). Documents are either translated to standard JSON for decoding or
+// edited in place so that their comments and layout survive.
+package jsonc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonfmt"
+)
+
+// tokenKind classifies a lexical token.
+type tokenKind int
+
+const (
+	tokEOF     tokenKind = iota
+	tokDelim             // one of { } [ ] : ,
+	tokString            // double- or single-quoted string
+	tokNumber            // any JSON5 number
+	tokLiteral           // true, false or null
+	tokIdent             // unquoted name, only valid as an object key
+)
+
+// token is a lexical token together with its byte span in the input.
+type token struct {
+	kind  tokenKind
+	delim byte
+	start int64
+	end   int64
+	// text is the token in standard JSON form
+	text string
+	// value is the decoded string of a string, literal or identifier
+	value string
+}
+
+// jsonNumber matches a number in standard JSON form.
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// scanner splits JSONC and JSON5 text into tokens, skipping whitespace and
+// comments.
+type scanner struct {
+	r   *bufio.Reader
+	off int64
+}
+
+// newScanner returns a scanner reading from r.
+func newScanner(r io.Reader) *scanner {
+	return &scanner{r: bufio.NewReader(r)}
+}
+
+// readByte reads the next input byte.
+func (s *scanner) readByte() (byte, error) {
+	b, err := s.r.ReadByte()
+	if err == nil {
+		s.off++
+	}
+	return b, err
+}
+
+// unreadByte steps back over the byte just read.
+func (s *scanner) unreadByte() {
+	if s.r.UnreadByte() == nil {
+		s.off--
+	}
+}
+
+// errorf reports a syntax error at the given input offset.
+func (s *scanner) errorf(off int64, format string, args ...any) error {
+	return fmt.Errorf("%w: %s at offset %d", domain.ErrInvalidJSON, fmt.Sprintf(format, args...), off)
+}
+
+// next returns the next token, or a tokEOF token at the end of the input.
+func (s *scanner) next() (token, error) {
+	if err := s.skipSpace(); err != nil {
+		return token{}, err
+	}
+
+	start := s.off
+	b, err := s.readByte()
+	if err == io.EOF {
+		return token{kind: tokEOF, start: start, end: start}, nil
+	}
+	if err != nil {
+		return token{}, err
+	}
+
+	switch {
+	case strings.IndexByte("{}[]:,", b) >= 0:
+		return token{kind: tokDelim, delim: b, start: start, end: s.off, text: string(b)}, nil
+	case b == '"' || b == '\'':
+		return s.str(b, start)
+	case b == '-' || b == '+' || b == '.' || isDigit(b):
+		s.unreadByte()
+		return s.number(start)
+	case isIdentByte(b):
+		s.unreadByte()
+		return s.ident(start)
+	}
+	return token{}, s.errorf(start, "invalid character %q", b)
+}
+
+// skipSpace consumes whitespace, comments and a leading byte order mark.
+func (s *scanner) skipSpace() error {
+	for {
+		start := s.off
+		b, err := s.readByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\v' || b == '\f':
+		case b == 0xEF && start == 0:
+			// UTF-8 byte order mark
+			if bom, _ := s.r.Peek(2); string(bom) != "\xBB\xBF" {
+				return s.errorf(start, "invalid character %q", b)
+			}
+			s.r.Discard(2)
+			s.off += 2
+		case b == '/':
+			if err := s.comment(start); err != nil {
+				return err
+			}
+		default:
+			s.unreadByte()
+			return nil
+		}
+	}
+}
+
+// comment consumes the rest of a comment whose leading slash has been read.
+func (s *scanner) comment(start int64) error {
+	b, err := s.readByte()
+	if err != nil {
+		return s.errorf(start, "invalid character '/'")
+	}
+	switch b {
+	case '/':
+		for {
+			b, err := s.readByte()
+			if err == io.EOF || b == '\n' {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	case '*':
+		for prev := byte(0); ; {
+			b, err := s.readByte()
+			if err == io.EOF {
+				return s.errorf(start, "unterminated comment")
+			}
+			if err != nil {
+				return err
+			}
+			if prev == '*' && b == '/' {
+				return nil
+			}
+			prev = b
+		}
+	}
+	return s.errorf(start, "invalid character '/'")
+}
+
+// str reads a string whose opening quote has been read.
+func (s *scanner) str(quote byte, start int64) (token, error) {
+	var sb strings.Builder
+	for {
+		b, err := s.readByte()
+		if err == io.EOF {
+			return token{}, s.errorf(start, "unterminated string")
+		}
+		if err != nil {
+			return token{}, err
+		}
+
+		switch {
+		case b == quote:
+			value := sb.String()
+			text, err := jsonfmt.Encode(value, jsonfmt.Options{Style: jsonfmt.StyleCompact})
+			if err != nil {
+				return token{}, err
+			}
+			return token{kind: tokString, start: start, end: s.off, text: text, value: value}, nil
+		case b < 0x20:
+			return token{}, s.errorf(s.off-1, "invalid control character in string")
+		case b == '\\':
+			if err := s.escape(&sb); err != nil {
+				return token{}, err
+			}
+		default:
+			sb.WriteByte(b)
+		}
+	}
+}
+
+// escape decodes an escape sequence whose backslash has been read.
+func (s *scanner) escape(sb *strings.Builder) error {
+	start := s.off - 1
+	b, err := s.readByte()
+	if err != nil {
+		return s.errorf(start, "unterminated string")
+	}
+
+	switch b {
+	case 'b':
+		sb.WriteByte('\b')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'r':
+		sb.WriteByte('\r')
+	case 't':
+		sb.WriteByte('\t')
+	case 'v':
+		sb.WriteByte('\v')
+	case '0':
+		sb.WriteByte(0)
+	case '\n':
+		// Line continuation
+	case '\r':
+		// Line continuation, possibly CRLF
+		if next, _ := s.r.Peek(1); string(next) == "\n" {
+			s.readByte()
+		}
+	case 'x':
+		n, err := s.hex(2)
+		if err != nil {
+			return err
+		}
+		sb.WriteRune(rune(n))
+	case 'u':
+		n, err := s.hex(4)
+		if err != nil {
+			return err
+		}
+		r := rune(n)
+		if utf16.IsSurrogate(r) {
+			// Pair a high surrogate with the low surrogate escape after it
+			if next, _ := s.r.Peek(2); string(next) == `\u` {
+				s.r.Discard(2)
+				s.off += 2
+				low, err := s.hex(4)
+				if err != nil {
+					return err
+				}
+				r = utf16.DecodeRune(r, rune(low))
+			} else {
+				r = utf8.RuneError
+			}
+		}
+		sb.WriteRune(r)
+	default:
+		// Any other character, including quotes and slashes, stands for itself
+		sb.WriteByte(b)
+	}
+	return nil
+}
+
+// hex reads n hexadecimal digits.
+func (s *scanner) hex(n int) (uint64, error) {
+	start := s.off
+	digits := make([]byte, 0, n)
+	for i := 0; i < n; i++ {
+		b, err := s.readByte()
+		if err != nil || !isHexDigit(b) {
+			return 0, s.errorf(start, "invalid escape sequence")
+		}
+		digits = append(digits, b)
+	}
+	return strconv.ParseUint(string(digits), 16, 32)
+}
+
+// number reads a number in any JSON5 form and converts it to standard JSON.
+func (s *scanner) number(start int64) (token, error) {
+	var raw []byte
+	for {
+		b, err := s.readByte()
+		if err != nil {
+			break
+		}
+		if !isDigit(b) && !isIdentByte(b) && b != '.' && b != '+' && b != '-' {
+			s.unreadByte()
+			break
+		}
+		// A sign only continues a number at its start or after an exponent
+		if (b == '+' || b == '-') && len(raw) > 0 && raw[len(raw)-1] != 'e' && raw[len(raw)-1] != 'E' {
+			s.unreadByte()
+			break
+		}
+		raw = append(raw, b)
+	}
+
+	text, err := standardNumber(string(raw))
+	if err != nil {
+		return token{}, s.errorf(start, "%v", err)
+	}
+	return token{kind: tokNumber, start: start, end: s.off, text: text}, nil
+}
+
+// standardNumber rewrites a JSON5 number as a standard JSON number.
+func standardNumber(raw string) (string, error) {
+	sign, digits := "", raw
+	if digits != "" && (digits[0] == '-' || digits[0] == '+') {
+		if digits[0] == '-' {
+			sign = "-"
+		}
+		digits = digits[1:]
+	}
+
+	switch {
+	case digits == "Infinity" || digits == "NaN":
+		return "", fmt.Errorf("%s%s cannot be represented in JSON", sign, digits)
+
+	case strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X"):
+		n, ok := new(big.Int).SetString(digits[2:], 16)
+		if !ok {
+			return "", fmt.Errorf("invalid number %q", raw)
+		}
+		return sign + n.String(), nil
+	}
+
+	// Allow a bare leading or trailing decimal point
+	if strings.HasPrefix(digits, ".") {
+		digits = "0" + digits
+	}
+	if i := strings.IndexByte(digits, '.'); i >= 0 && (i == len(digits)-1 || !isDigit(digits[i+1])) {
+		digits = digits[:i] + digits[i+1:]
+	}
+	if !jsonNumber.MatchString(sign + digits) {
+		return "", fmt.Errorf("invalid number %q", raw)
+	}
+	return sign + digits, nil
+}
+
+// ident reads an unquoted name or a true, false or null literal.
+func (s *scanner) ident(start int64) (token, error) {
+	var sb strings.Builder
+	for {
+		b, err := s.readByte()
+		if err != nil {
+			break
+		}
+		if !isIdentByte(b) && !isDigit(b) {
+			s.unreadByte()
+			break
+		}
+		sb.WriteByte(b)
+	}
+
+	name := sb.String()
+	switch name {
+	case "true", "false", "null":
+		return token{kind: tokLiteral, start: start, end: s.off, text: name, value: name}, nil
+	}
+	text, err := jsonfmt.Encode(name, jsonfmt.Options{Style: jsonfmt.StyleCompact})
+	if err != nil {
+		return token{}, err
+	}
+	return token{kind: tokIdent, start: start, end: s.off, text: text, value: name}, nil
+}
+
+// identValueError explains why an unquoted name cannot be a value.
+func (s *scanner) identValueError(tok token) error {
+	if tok.value == "Infinity" || tok.value == "NaN" {
+		return s.errorf(tok.start, "%s cannot be represented in JSON", tok.value)
+	}
+	return s.errorf(tok.start, "invalid value %q", tok.value)
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func isHexDigit(b byte) bool {
+	return isDigit(b) || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
+
+// isIdentByte reports whether b may appear in an unquoted name. Bytes of
+// multi-byte UTF-8 sequences are accepted so that non-ASCII names work.
+func isIdentByte(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || b == '_' || b == '$' || b >= 0x80
+}
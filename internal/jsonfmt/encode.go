@@ -0,0 +1,337 @@
+package jsonfmt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+)
+
+// Output styles supported by Encode.
+const (
+	StylePretty    = "pretty"
+	StyleCompact   = "compact"
+	StyleCanonical = "canonical"
+)
+
+// Options controls how Encode lays out a document.
+type Options struct {
+	// Style is one of StylePretty, StyleCompact or StyleCanonical.
+	Style string
+
+	// Indent is the indentation unit used by StylePretty.
+	Indent string
+
+	// SortKeys orders object members by key instead of by Order.
+	SortKeys bool
+
+	// TrailingNewline appends a newline after the document.
+	// It is ignored by StyleCanonical.
+	TrailingNewline bool
+
+	// Order lists key order hints consulted in turn. Members not found in
+	// any hint follow in sorted order.
+	Order []*KeyOrder
+}
+
+// DefaultOptions returns the layout used for documents with no existing
+// formatting to follow: two-space indentation and a trailing newline.
+func DefaultOptions() Options {
+	return Options{
+		Style:           StylePretty,
+		Indent:          "  ",
+		TrailingNewline: true,
+	}
+}
+
+// DetectOptions infers the layout of an existing JSON text: compact if it is
+// a single line, otherwise pretty with the indentation of its first indented
+// line. The text's own key order is used as the order hint.
+func DetectOptions(content string) Options {
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" {
+		return DefaultOptions()
+	}
+
+	opts := Options{
+		Style:           StyleCompact,
+		TrailingNewline: strings.HasSuffix(content, "\n"),
+	}
+	if strings.Contains(trimmed, "\n") {
+		opts.Style = StylePretty
+		for _, line := range strings.Split(trimmed, "\n")[1:] {
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			if indent != "" {
+				opts.Indent = indent
+				break
+			}
+		}
+	}
+	if order, err := ParseKeyOrder(content); err == nil {
+		opts.Order = []*KeyOrder{order}
+	}
+	return opts
+}
+
+// Encode serializes a decoded JSON value. StyleCanonical produces RFC 8785
+// (JCS) output: no whitespace, members sorted by UTF-16 code units and
+// numbers in ECMAScript form.
+func Encode(v any, opts Options) (string, error) {
+	e := &encoder{opts: opts}
+	if err := e.value(v, 0, opts.Order); err != nil {
+		return "", fmt.Errorf("%w: %v", domain.ErrInvalidJSON, err)
+	}
+	if opts.TrailingNewline && opts.Style != StyleCanonical {
+		e.buf.WriteByte('\n')
+	}
+	return e.buf.String(), nil
+}
+
+// encoder accumulates the encoded output.
+type encoder struct {
+	buf  strings.Builder
+	opts Options
+}
+
+// value encodes any decoded JSON value at the given nesting depth.
+func (e *encoder) value(v any, depth int, hints []*KeyOrder) error {
+	switch val := v.(type) {
+	case nil:
+		e.buf.WriteString("null")
+	case bool:
+		e.buf.WriteString(strconv.FormatBool(val))
+	case string:
+		e.str(val)
+	case json.Number:
+		return e.number(val)
+	case float64:
+		return e.float(val)
+	case float32:
+		return e.float(float64(val))
+	case int:
+		e.buf.WriteString(strconv.Itoa(val))
+	case int64:
+		e.buf.WriteString(strconv.FormatInt(val, 10))
+	case map[string]any:
+		return e.object(val, depth, hints)
+	case []any:
+		return e.array(val, depth, hints)
+	default:
+		// Fall back to encoding/json for any other Go value, then re-encode
+		// the result so the requested style still applies
+		raw, err := json.Marshal(val)
+		if err != nil {
+			return err
+		}
+		var decoded any
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			return err
+		}
+		return e.value(decoded, depth, hints)
+	}
+	return nil
+}
+
+// object encodes an object with its members in hinted order.
+func (e *encoder) object(obj map[string]any, depth int, hints []*KeyOrder) error {
+	if len(obj) == 0 {
+		e.buf.WriteString("{}")
+		return nil
+	}
+
+	e.buf.WriteByte('{')
+	for i, key := range e.keys(obj, hints) {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+		e.newline(depth + 1)
+		e.str(key)
+		e.buf.WriteByte(':')
+		if e.opts.Style == StylePretty {
+			e.buf.WriteByte(' ')
+		}
+
+		var childHints []*KeyOrder
+		for _, h := range hints {
+			if child := h.field(key); child != nil {
+				childHints = append(childHints, child)
+			}
+		}
+		if err := e.value(obj[key], depth+1, childHints); err != nil {
+			return err
+		}
+	}
+	e.newline(depth)
+	e.buf.WriteByte('}')
+	return nil
+}
+
+// array encodes an array, pairing each element with its recorded key order.
+func (e *encoder) array(arr []any, depth int, hints []*KeyOrder) error {
+	if len(arr) == 0 {
+		e.buf.WriteString("[]")
+		return nil
+	}
+
+	e.buf.WriteByte('[')
+	for i, item := range arr {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+		e.newline(depth + 1)
+
+		var childHints []*KeyOrder
+		for _, h := range hints {
+			if child := h.elem(i); child != nil {
+				childHints = append(childHints, child)
+			}
+		}
+		if err := e.value(item, depth+1, childHints); err != nil {
+			return err
+		}
+	}
+	e.newline(depth)
+	e.buf.WriteByte(']')
+	return nil
+}
+
+// newline starts a new indented line in pretty style.
+func (e *encoder) newline(depth int) {
+	if e.opts.Style != StylePretty {
+		return
+	}
+	e.buf.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		e.buf.WriteString(e.opts.Indent)
+	}
+}
+
+// keys returns the object's keys in output order.
+func (e *encoder) keys(obj map[string]any, hints []*KeyOrder) []string {
+	keys := make([]string, 0, len(obj))
+
+	if e.opts.Style == StyleCanonical {
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
+		return keys
+	}
+
+	seen := make(map[string]bool, len(obj))
+	if !e.opts.SortKeys {
+		for _, h := range hints {
+			for _, k := range h.Keys {
+				if _, ok := obj[k]; ok && !seen[k] {
+					seen[k] = true
+					keys = append(keys, k)
+				}
+			}
+		}
+	}
+
+	// Members without a hint follow in sorted order
+	rest := make([]string, 0, len(obj)-len(keys))
+	for k := range obj {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+// lessUTF16 compares strings by UTF-16 code units, as RFC 8785 requires.
+func lessUTF16(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
+
+// str encodes a string, escaping only what JSON requires.
+func (e *encoder) str(s string) {
+	const hex = "0123456789abcdef"
+	e.buf.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			e.buf.WriteString(`\ufffd`)
+		case r == '"':
+			e.buf.WriteString(`\"`)
+		case r == '\\':
+			e.buf.WriteString(`\\`)
+		case r == '\b':
+			e.buf.WriteString(`\b`)
+		case r == '\f':
+			e.buf.WriteString(`\f`)
+		case r == '\n':
+			e.buf.WriteString(`\n`)
+		case r == '\r':
+			e.buf.WriteString(`\r`)
+		case r == '\t':
+			e.buf.WriteString(`\t`)
+		case r < 0x20:
+			e.buf.WriteString(`\u00`)
+			e.buf.WriteByte(hex[r>>4])
+			e.buf.WriteByte(hex[r&0xf])
+		default:
+			e.buf.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	e.buf.WriteByte('"')
+}
+
+// number encodes a json.Number. Its original text is kept except in
+// canonical style, where it is normalized through float64.
+func (e *encoder) number(n json.Number) error {
+	if e.opts.Style != StyleCanonical {
+		if !json.Valid([]byte(n)) {
+			return fmt.Errorf("invalid number %q", string(n))
+		}
+		e.buf.WriteString(string(n))
+		return nil
+	}
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", string(n))
+	}
+	return e.float(f)
+}
+
+// float encodes a float64 the way ECMAScript's Number.prototype.toString
+// does, which is also what encoding/json produces.
+func (e *encoder) float(f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("unsupported number %v", f)
+	}
+	if f == 0 {
+		e.buf.WriteByte('0')
+		return nil
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs < 1e-6 || abs >= 1e21 {
+		format = 'e'
+	}
+	s := strconv.FormatFloat(f, format, -1, 64)
+	if format == 'e' {
+		// Trim the exponent's leading zero: 1e-07 becomes 1e-7
+		if n := len(s); n >= 4 && s[n-4] == 'e' && s[n-2] == '0' {
+			s = s[:n-2] + s[n-1:]
+		}
+	}
+	e.buf.WriteString(s)
+	return nil
+}
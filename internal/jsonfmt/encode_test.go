@@ -0,0 +1,185 @@
+package jsonfmt_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonfmt"
+)
+
+// decode parses JSON the way the tools do, keeping numbers as json.Number.
+func decode(t *testing.T, content string) any {
+	t.Helper()
+	dec := json.NewDecoder(strings.NewReader(content))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		t.Fatalf("decode %q: %v", content, err)
+	}
+	return v
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  jsonfmt.Options
+		want  string
+	}{
+		{
+			name:  "pretty with two spaces",
+			input: `{"b": [1, {"c": null}], "a": {}}`,
+			opts:  jsonfmt.DefaultOptions(),
+			want:  "{\n  \"a\": {},\n  \"b\": [\n    1,\n    {\n      \"c\": null\n    }\n  ]\n}\n",
+		},
+		{
+			name:  "pretty with tabs",
+			input: `{"a": [true]}`,
+			opts:  jsonfmt.Options{Style: jsonfmt.StylePretty, Indent: "\t"},
+			want:  "{\n\t\"a\": [\n\t\ttrue\n\t]\n}",
+		},
+		{
+			name:  "compact",
+			input: `{"b": 1, "a": [1, 2], "c": []}`,
+			opts:  jsonfmt.Options{Style: jsonfmt.StyleCompact},
+			want:  `{"a":[1,2],"b":1,"c":[]}`,
+		},
+		{
+			name:  "numbers keep their original text",
+			input: `[1.0, 1e3, 9007199254740993]`,
+			opts:  jsonfmt.Options{Style: jsonfmt.StyleCompact},
+			want:  `[1.0,1e3,9007199254740993]`,
+		},
+		{
+			name:  "strings escape only what JSON requires",
+			input: `"<a & b>\t\"é\"\u0001"`,
+			opts:  jsonfmt.Options{Style: jsonfmt.StyleCompact},
+			want:  `"<a & b>\t\"é\"\u0001"`,
+		},
+		{
+			name:  "canonical sorts by UTF-16 code units",
+			input: `{"\u20ac": 1, "\ud83d\ude00": 2, "a": 3, "\r": 4}`,
+			opts:  jsonfmt.Options{Style: jsonfmt.StyleCanonical, TrailingNewline: true},
+			want:  "{\"\\r\":4,\"a\":3,\"\u20ac\":1,\"\U0001F600\":2}",
+		},
+		{
+			name:  "canonical normalizes numbers",
+			input: `[1.0, 1e3, -0, 0.000001, 1e-7, 1e21, 123456789012345680000, 4.50]`,
+			opts:  jsonfmt.Options{Style: jsonfmt.StyleCanonical},
+			want:  `[1,1000,0,0.000001,1e-7,1e+21,123456789012345680000,4.5]`,
+		},
+		{
+			name:  "order hints apply in turn, then sorted",
+			input: `{"z": 1, "b": 2, "a": 3, "y": {"q": 1, "p": 2}}`,
+			opts: jsonfmt.Options{
+				Style: jsonfmt.StyleCompact,
+				Order: []*jsonfmt.KeyOrder{
+					{Keys: []string{"y", "z"}, Fields: map[string]*jsonfmt.KeyOrder{"y": {Keys: []string{"q"}}}},
+					{Keys: []string{"b", "z"}},
+				},
+			},
+			want: `{"y":{"q":1,"p":2},"z":1,"b":2,"a":3}`,
+		},
+		{
+			name:  "sortKeys overrides hints",
+			input: `{"b": 1, "a": 2}`,
+			opts: jsonfmt.Options{
+				Style:    jsonfmt.StyleCompact,
+				SortKeys: true,
+				Order:    []*jsonfmt.KeyOrder{{Keys: []string{"b", "a"}}},
+			},
+			want: `{"a":2,"b":1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonfmt.Encode(decode(t, tt.input), tt.opts)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Encode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeRejectsNonFiniteNumbers(t *testing.T) {
+	_, err := jsonfmt.Encode([]any{math.Inf(1)}, jsonfmt.DefaultOptions())
+	if !errors.Is(err, domain.ErrInvalidJSON) {
+		t.Errorf("Encode() error = %v, want %v", err, domain.ErrInvalidJSON)
+	}
+}
+
+func TestDetectOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		wantStyle     string
+		wantIndent    string
+		wantNewline   bool
+		wantRoundTrip bool
+	}{
+		{
+			name:          "four spaces",
+			content:       "{\n    \"b\": [\n        1\n    ],\n    \"a\": 2\n}\n",
+			wantStyle:     jsonfmt.StylePretty,
+			wantIndent:    "    ",
+			wantNewline:   true,
+			wantRoundTrip: true,
+		},
+		{
+			name:          "tabs without trailing newline",
+			content:       "{\n\t\"z\": {\n\t\t\"y\": 1,\n\t\t\"x\": 2\n\t}\n}",
+			wantStyle:     jsonfmt.StylePretty,
+			wantIndent:    "\t",
+			wantRoundTrip: true,
+		},
+		{
+			name:          "single line is compact",
+			content:       "{\"b\":1,\"a\":[{\"d\":1,\"c\":2}]}\n",
+			wantStyle:     jsonfmt.StyleCompact,
+			wantNewline:   true,
+			wantRoundTrip: true,
+		},
+		{
+			name:        "empty content uses defaults",
+			content:     "",
+			wantStyle:   jsonfmt.StylePretty,
+			wantIndent:  "  ",
+			wantNewline: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := jsonfmt.DetectOptions(tt.content)
+			if opts.Style != tt.wantStyle {
+				t.Errorf("DetectOptions() style = %q, want %q", opts.Style, tt.wantStyle)
+			}
+			if opts.Indent != tt.wantIndent {
+				t.Errorf("DetectOptions() indent = %q, want %q", opts.Indent, tt.wantIndent)
+			}
+			if opts.TrailingNewline != tt.wantNewline {
+				t.Errorf("DetectOptions() trailing newline = %v, want %v", opts.TrailingNewline, tt.wantNewline)
+			}
+			if !tt.wantRoundTrip {
+				return
+			}
+
+			// A consistently formatted file must re-encode byte for byte
+			got, err := jsonfmt.Encode(decode(t, tt.content), opts)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if got != tt.content {
+				t.Errorf("Encode() = %q, want %q", got, tt.content)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package jsonfmt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+)
+
+// KeyOrder records the member order of every object in a JSON text, so that
+// a decoded and modified document can be written back in its original order.
+type KeyOrder struct {
+	Keys   []string
+	Fields map[string]*KeyOrder
+	Elems  []*KeyOrder
+}
+
+// ParseKeyOrder scans a JSON text and records its object member order.
+func ParseKeyOrder(content string) (*KeyOrder, error) {
+	dec := json.NewDecoder(strings.NewReader(content))
+	dec.UseNumber()
+	order, err := parseOrder(dec)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidJSON, err)
+	}
+	return order, nil
+}
+
+// parseOrder consumes one value from dec and returns its key order, which is
+// nil for scalars.
+func parseOrder(dec *json.Decoder) (*KeyOrder, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return nil, nil
+	}
+
+	order := &KeyOrder{}
+	switch delim {
+	case '{':
+		order.Fields = map[string]*KeyOrder{}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := keyTok.(string)
+			child, err := parseOrder(dec)
+			if err != nil {
+				return nil, err
+			}
+			// The first occurrence of a duplicate key fixes its position
+			if _, seen := order.Fields[key]; !seen {
+				order.Keys = append(order.Keys, key)
+			}
+			order.Fields[key] = child
+		}
+	case '[':
+		for dec.More() {
+			child, err := parseOrder(dec)
+			if err != nil {
+				return nil, err
+			}
+			order.Elems = append(order.Elems, child)
+		}
+	}
+
+	// Consume the closing delimiter
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// field returns the key order recorded for an object member.
+func (o *KeyOrder) field(key string) *KeyOrder {
+	if o == nil {
+		return nil
+	}
+	return o.Fields[key]
+}
+
+// elem returns the key order recorded for an array element. Elements past
+// the recorded ones reuse the last recorded order, since array elements
+// usually share a shape.
+func (o *KeyOrder) elem(i int) *KeyOrder {
+	if o == nil || len(o.Elems) == 0 {
+		return nil
+	}
+	if i >= len(o.Elems) {
+		return o.Elems[len(o.Elems)-1]
+	}
+	return o.Elems[i]
+}
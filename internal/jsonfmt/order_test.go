@@ -0,0 +1,43 @@
+package jsonfmt_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonfmt"
+)
+
+func TestParseKeyOrder(t *testing.T) {
+	order, err := jsonfmt.ParseKeyOrder(`{"b": 1, "a": {"y": 1, "x": 2}, "c": [{"q": 1, "p": 2}, 3], "b": 4}`)
+	if err != nil {
+		t.Fatalf("ParseKeyOrder() error = %v", err)
+	}
+
+	if want := []string{"b", "a", "c"}; !reflect.DeepEqual(order.Keys, want) {
+		t.Errorf("ParseKeyOrder() keys = %v, want %v", order.Keys, want)
+	}
+	if want := []string{"y", "x"}; !reflect.DeepEqual(order.Fields["a"].Keys, want) {
+		t.Errorf("ParseKeyOrder() nested keys = %v, want %v", order.Fields["a"].Keys, want)
+	}
+
+	elems := order.Fields["c"].Elems
+	if len(elems) != 2 {
+		t.Fatalf("ParseKeyOrder() elements = %d, want 2", len(elems))
+	}
+	if want := []string{"q", "p"}; !reflect.DeepEqual(elems[0].Keys, want) {
+		t.Errorf("ParseKeyOrder() element keys = %v, want %v", elems[0].Keys, want)
+	}
+	if elems[1] != nil {
+		t.Errorf("ParseKeyOrder() scalar element = %v, want nil", elems[1])
+	}
+}
+
+func TestParseKeyOrderInvalid(t *testing.T) {
+	for _, content := range []string{``, `{"a": `, `{"a" 1}`} {
+		if _, err := jsonfmt.ParseKeyOrder(content); !errors.Is(err, domain.ErrInvalidJSON) {
+			t.Errorf("ParseKeyOrder(%q) error = %v, want %v", content, err, domain.ErrInvalidJSON)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package jsonpatch
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonfmt"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpointer"
+)
+
+// DiffOptions controls how Diff compares documents.
+type DiffOptions struct {
+	// ArrayKey, when set, matches array elements by the value of this object
+	// member instead of by position. Arrays whose elements are not all
+	// objects with a unique scalar value for the key are compared by position.
+	ArrayKey string
+}
+
+// Difference is one operation of a diff together with the value it removes
+// or replaces, which is nil for add and move operations.
+type Difference struct {
+	Operation
+	Old any
+}
+
+// Diff returns an RFC 6902 patch that transforms from into to. Applying the
+// result to from with Apply yields a document Equal to to.
+func Diff(from, to any, opts DiffOptions) []Operation {
+	diffs := Compare(from, to, opts)
+	ops := make([]Operation, len(diffs))
+	for i, d := range diffs {
+		ops[i] = d.Operation
+	}
+	return ops
+}
+
+// Compare is Diff with the previous value of every removed or replaced
+// location attached, for describing the changes.
+func Compare(from, to any, opts DiffOptions) []Difference {
+	d := &differ{opts: opts, diffs: []Difference{}}
+	d.diff(jsonpointer.Pointer{}, from, to)
+	return d.diffs
+}
+
+// differ accumulates the operations of a diff.
+type differ struct {
+	opts  DiffOptions
+	diffs []Difference
+}
+
+// emit records an operation on path that replaces old.
+func (d *differ) emit(op string, path jsonpointer.Pointer, value, old any) {
+	d.diffs = append(d.diffs, Difference{Operation: Operation{Op: op, Path: path.String(), Value: value}, Old: old})
+}
+
+// diff compares the values found at path.
+func (d *differ) diff(path jsonpointer.Pointer, a, b any) {
+	if Equal(a, b) {
+		return
+	}
+
+	switch av := a.(type) {
+	case map[string]any:
+		if bv, ok := b.(map[string]any); ok {
+			d.diffObjects(path, av, bv)
+			return
+		}
+	case []any:
+		if bv, ok := b.([]any); ok {
+			if d.opts.ArrayKey != "" {
+				aKeys, aok := identities(av, d.opts.ArrayKey)
+				bKeys, bok := identities(bv, d.opts.ArrayKey)
+				if aok && bok {
+					d.diffKeyedArrays(path, av, bv, aKeys, bKeys)
+					return
+				}
+			}
+			d.diffArrays(path, av, bv)
+			return
+		}
+	}
+	d.emit("replace", path, DeepCopy(b), a)
+}
+
+// diffObjects compares object members in sorted key order.
+func (d *differ) diffObjects(path jsonpointer.Pointer, a, b map[string]any) {
+	for _, k := range sortedKeys(a) {
+		if bchild, ok := b[k]; ok {
+			d.diff(path.Append(k), a[k], bchild)
+		} else {
+			d.emit("remove", path.Append(k), nil, a[k])
+		}
+	}
+	for _, k := range sortedKeys(b) {
+		if _, ok := a[k]; !ok {
+			d.emit("add", path.Append(k), DeepCopy(b[k]), nil)
+		}
+	}
+}
+
+// diffArrays compares arrays position by position, then removes or appends
+// the tail.
+func (d *differ) diffArrays(path jsonpointer.Pointer, a, b []any) {
+	common := min(len(a), len(b))
+	for i := 0; i < common; i++ {
+		d.diff(path.Append(strconv.Itoa(i)), a[i], b[i])
+	}
+	// Remove from the end so earlier indices stay valid
+	for i := len(a) - 1; i >= common; i-- {
+		d.emit("remove", path.Append(strconv.Itoa(i)), nil, a[i])
+	}
+	for i := common; i < len(b); i++ {
+		d.emit("add", path.Append("-"), DeepCopy(b[i]), nil)
+	}
+}
+
+// diffKeyedArrays matches elements by identity: elements missing from b are
+// removed, the rest are moved into b's order and compared, and new elements
+// are inserted where b has them.
+func (d *differ) diffKeyedArrays(path jsonpointer.Pointer, a, b []any, aKeys, bKeys []string) {
+	inB := make(map[string]bool, len(bKeys))
+	for _, k := range bKeys {
+		inB[k] = true
+	}
+
+	// current tracks the identities in the array as operations are applied
+	current := make([]string, 0, len(aKeys))
+	byKey := make(map[string]any, len(aKeys))
+	for i := len(aKeys) - 1; i >= 0; i-- {
+		if !inB[aKeys[i]] {
+			d.emit("remove", path.Append(strconv.Itoa(i)), nil, a[i])
+		}
+	}
+	for i, k := range aKeys {
+		if inB[k] {
+			current = append(current, k)
+			byKey[k] = a[i]
+		}
+	}
+
+	for j, k := range bKeys {
+		elem, existed := byKey[k]
+		if !existed {
+			d.emit("add", path.Append(strconv.Itoa(j)), DeepCopy(b[j]), nil)
+			current = insertAt(current, j, k)
+			continue
+		}
+
+		if p := indexOf(current, k); p != j {
+			d.diffs = append(d.diffs, Difference{Operation: Operation{
+				Op:   "move",
+				From: path.Append(strconv.Itoa(p)).String(),
+				Path: path.Append(strconv.Itoa(j)).String(),
+			}})
+			current = insertAt(append(current[:p], current[p+1:]...), j, k)
+		}
+		d.diff(path.Append(strconv.Itoa(j)), elem, b[j])
+	}
+}
+
+// identities returns the canonical identity of every element of arr, or
+// false if some element is not an object with a unique scalar key value.
+func identities(arr []any, key string) ([]string, bool) {
+	ids := make([]string, len(arr))
+	seen := make(map[string]bool, len(arr))
+	for i, item := range arr {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		v, ok := obj[key]
+		if !ok {
+			return nil, false
+		}
+		if _, isObj := v.(map[string]any); isObj {
+			return nil, false
+		}
+		if _, isArr := v.([]any); isArr {
+			return nil, false
+		}
+		// Canonical encoding makes numerically equal keys such as 1 and 1.0 match
+		id, err := jsonfmt.Encode(v, jsonfmt.Options{Style: jsonfmt.StyleCanonical})
+		if err != nil || seen[id] {
+			return nil, false
+		}
+		seen[id] = true
+		ids[i] = id
+	}
+	return ids, true
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// indexOf returns the position of s in list, or -1.
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// insertAt inserts s into list before index i.
+func insertAt(list []string, i int, s string) []string {
+	list = append(list, "")
+	copy(list[i+1:], list[i:])
+	list[i] = s
+	return list
+}
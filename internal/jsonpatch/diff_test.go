@@ -0,0 +1,121 @@
+package jsonpatch_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpatch"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		arrayKey string
+		want     string
+	}{
+		{
+			name: "identical documents",
+			from: `{"a": [1, {"b": null}]}`,
+			to:   `{"a": [1.0, {"b": null}]}`,
+			want: `[]`,
+		},
+		{
+			name: "object members",
+			from: `{"a": 1, "b": {"c": "x", "d": true}, "gone": 0}`,
+			to:   `{"a": 2, "b": {"c": "x", "e": null}, "new": [1]}`,
+			want: `[
+				{"op": "replace", "path": "/a", "value": 2},
+				{"op": "remove", "path": "/b/d"},
+				{"op": "add", "path": "/b/e", "value": null},
+				{"op": "remove", "path": "/gone"},
+				{"op": "add", "path": "/new", "value": [1]}
+			]`,
+		},
+		{
+			name: "type change replaces",
+			from: `{"a": {"b": 1}}`,
+			to:   `{"a": [1]}`,
+			want: `[{"op": "replace", "path": "/a", "value": [1]}]`,
+		},
+		{
+			name: "root scalar",
+			from: `1`,
+			to:   `"one"`,
+			want: `[{"op": "replace", "path": "", "value": "one"}]`,
+		},
+		{
+			name: "arrays by index",
+			from: `[1, 2, 3, 4]`,
+			to:   `[1, 5]`,
+			want: `[
+				{"op": "replace", "path": "/1", "value": 5},
+				{"op": "remove", "path": "/3"},
+				{"op": "remove", "path": "/2"}
+			]`,
+		},
+		{
+			name: "arrays grow at the end",
+			from: `[1]`,
+			to:   `[1, 2, 3]`,
+			want: `[
+				{"op": "add", "path": "/-", "value": 2},
+				{"op": "add", "path": "/-", "value": 3}
+			]`,
+		},
+		{
+			name:     "arrays by identity key",
+			from:     `{"items": [{"id": 1, "v": "a"}, {"id": 2, "v": "b"}, {"id": 3, "v": "c"}]}`,
+			to:       `{"items": [{"id": 4, "v": "d"}, {"id": 3, "v": "c"}, {"id": 1, "v": "A"}]}`,
+			arrayKey: "id",
+			want: `[
+				{"op": "remove", "path": "/items/1"},
+				{"op": "add", "path": "/items/0", "value": {"id": 4, "v": "d"}},
+				{"op": "move", "from": "/items/2", "path": "/items/1"},
+				{"op": "replace", "path": "/items/2/v", "value": "A"}
+			]`,
+		},
+		{
+			name:     "inserting by identity key shifts nothing else",
+			from:     `[{"id": "a", "n": 1}, {"id": "b", "n": 2}]`,
+			to:       `[{"id": "new"}, {"id": "a", "n": 1}, {"id": "b", "n": 2}]`,
+			arrayKey: "id",
+			want:     `[{"op": "add", "path": "/0", "value": {"id": "new"}}]`,
+		},
+		{
+			name:     "duplicate identities fall back to index",
+			from:     `[{"id": 1, "v": 1}, {"id": 1, "v": 2}]`,
+			to:       `[{"id": 1, "v": 2}]`,
+			arrayKey: "id",
+			want: `[
+				{"op": "replace", "path": "/0/v", "value": 2},
+				{"op": "remove", "path": "/1"}
+			]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := mustDecode(t, tt.from), mustDecode(t, tt.to)
+			ops := jsonpatch.Diff(from, to, jsonpatch.DiffOptions{ArrayKey: tt.arrayKey})
+
+			got, err := json.Marshal(ops)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !jsonpatch.Equal(mustDecode(t, string(got)), mustDecode(t, tt.want)) {
+				t.Errorf("Diff() = %s, want %s", got, tt.want)
+			}
+
+			// The patch must turn from into to
+			patched, err := jsonpatch.Apply(from, ops)
+			if err != nil {
+				t.Fatalf("Apply(Diff()) error = %v", err)
+			}
+			if !jsonpatch.Equal(patched, to) {
+				t.Errorf("Apply(Diff()) = %v, want %v", patched, to)
+			}
+		})
+	}
+}
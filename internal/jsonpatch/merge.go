@@ -0,0 +1,92 @@
+package jsonpatch
+
+import (
+	"sort"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpointer"
+)
+
+// Change kinds reported by MergePatch.
+const (
+	ChangeAdded   = "added"
+	ChangeUpdated = "updated"
+	ChangeRemoved = "removed"
+)
+
+// Change describes a single member that a merge patch added, updated or removed.
+type Change struct {
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+}
+
+// MergePatch applies an RFC 7396 merge patch to a copy of target and returns
+// the result along with the changed members. Objects merge recursively, null
+// removes a member and any other value replaces the target outright.
+// base is the location of target within its document and prefixes every
+// reported change path.
+func MergePatch(target, patch any, base jsonpointer.Pointer) (any, []Change) {
+	changes := []Change{}
+	merged := mergeValue(DeepCopy(target), patch, base, &changes)
+	return merged, changes
+}
+
+// mergeValue merges patch into target in place and records changed members.
+// When target is replaced wholesale, changes below it are not recorded
+// because the caller reports the replacement itself.
+func mergeValue(target, patch any, at jsonpointer.Pointer, changes *[]Change) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return DeepCopy(patch)
+	}
+
+	obj, ok := target.(map[string]any)
+	if !ok {
+		obj = make(map[string]any, len(patchObj))
+		changes = nil
+	}
+
+	// Visit keys in sorted order so the change summary is deterministic
+	keys := make([]string, 0, len(patchObj))
+	for k := range patchObj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := patchObj[key]
+		old, had := obj[key]
+		path := at.Append(key)
+
+		if value == nil {
+			if had {
+				delete(obj, key)
+				record(changes, path, ChangeRemoved)
+			}
+			continue
+		}
+
+		updated := mergeValue(old, value, path, changes)
+		obj[key] = updated
+
+		_, oldIsObj := old.(map[string]any)
+		_, valueIsObj := value.(map[string]any)
+		switch {
+		case !had:
+			record(changes, path, ChangeAdded)
+		case oldIsObj && valueIsObj:
+			// Nested changes were recorded by the recursive merge
+		case !Equal(old, updated):
+			record(changes, path, ChangeUpdated)
+		}
+	}
+
+	return obj
+}
+
+// record appends a change unless recording is suppressed.
+func record(changes *[]Change, path jsonpointer.Pointer, kind string) {
+	if changes == nil {
+		return
+	}
+	*changes = append(*changes, Change{Path: path.String(), Kind: kind})
+}
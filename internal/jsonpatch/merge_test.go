@@ -0,0 +1,118 @@
+package jsonpatch_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpatch"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpointer"
+)
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		patch       string
+		base        jsonpointer.Pointer
+		want        string
+		wantChanges []jsonpatch.Change
+	}{
+		{
+			name:        "replace member",
+			target:      `{"a": "b"}`,
+			patch:       `{"a": "c"}`,
+			want:        `{"a": "c"}`,
+			wantChanges: []jsonpatch.Change{{Path: "/a", Kind: jsonpatch.ChangeUpdated}},
+		},
+		{
+			name:        "add member",
+			target:      `{"a": "b"}`,
+			patch:       `{"b": "c"}`,
+			want:        `{"a": "b", "b": "c"}`,
+			wantChanges: []jsonpatch.Change{{Path: "/b", Kind: jsonpatch.ChangeAdded}},
+		},
+		{
+			name:        "null removes member",
+			target:      `{"a": "b", "b": "c"}`,
+			patch:       `{"a": null}`,
+			want:        `{"b": "c"}`,
+			wantChanges: []jsonpatch.Change{{Path: "/a", Kind: jsonpatch.ChangeRemoved}},
+		},
+		{
+			name:        "null for missing member is a no-op",
+			target:      `{"a": "b"}`,
+			patch:       `{"c": null}`,
+			want:        `{"a": "b"}`,
+			wantChanges: []jsonpatch.Change{},
+		},
+		{
+			name:        "arrays replace",
+			target:      `{"a": [{"b": "c"}]}`,
+			patch:       `{"a": [1]}`,
+			want:        `{"a": [1]}`,
+			wantChanges: []jsonpatch.Change{{Path: "/a", Kind: jsonpatch.ChangeUpdated}},
+		},
+		{
+			name:   "nested merge",
+			target: `{"title": "Goodbye!", "author": {"givenName": "John", "familyName": "Doe"}, "tags": ["example", "sample"], "content": "This will be unchanged"}`,
+			patch:  `{"title": "Hello!", "phoneNumber": "+01-123-456-7890", "author": {"familyName": null}, "tags": ["example"]}`,
+			want:   `{"title": "Hello!", "author": {"givenName": "John"}, "tags": ["example"], "content": "This will be unchanged", "phoneNumber": "+01-123-456-7890"}`,
+			wantChanges: []jsonpatch.Change{
+				{Path: "/author/familyName", Kind: jsonpatch.ChangeRemoved},
+				{Path: "/phoneNumber", Kind: jsonpatch.ChangeAdded},
+				{Path: "/tags", Kind: jsonpatch.ChangeUpdated},
+				{Path: "/title", Kind: jsonpatch.ChangeUpdated},
+			},
+		},
+		{
+			name:        "object replaces scalar",
+			target:      `{"a": "foo"}`,
+			patch:       `{"a": {"bb": {"ccc": null}}}`,
+			want:        `{"a": {"bb": {}}}`,
+			wantChanges: []jsonpatch.Change{{Path: "/a", Kind: jsonpatch.ChangeUpdated}},
+		},
+		{
+			name:        "non-object patch replaces target",
+			target:      `{"a": "foo"}`,
+			patch:       `["c"]`,
+			want:        `["c"]`,
+			wantChanges: []jsonpatch.Change{},
+		},
+		{
+			name:        "unchanged value is not reported",
+			target:      `{"a": 1}`,
+			patch:       `{"a": 1.0}`,
+			want:        `{"a": 1}`,
+			wantChanges: []jsonpatch.Change{},
+		},
+		{
+			name:        "base prefixes change paths",
+			target:      `{"theme": "light"}`,
+			patch:       `{"theme": "dark"}`,
+			base:        jsonpointer.Pointer{"editor"},
+			want:        `{"theme": "dark"}`,
+			wantChanges: []jsonpatch.Change{{Path: "/editor/theme", Kind: jsonpatch.ChangeUpdated}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := mustDecode(t, tt.target)
+			original := jsonpatch.DeepCopy(target)
+
+			got, changes := jsonpatch.MergePatch(target, mustDecode(t, tt.patch), tt.base)
+
+			if !jsonpatch.Equal(target, original) {
+				t.Errorf("MergePatch() modified input document: %v", target)
+			}
+
+			if want := mustDecode(t, tt.want); !jsonpatch.Equal(got, want) {
+				t.Errorf("MergePatch() = %v, want %v", got, want)
+			}
+
+			if !reflect.DeepEqual(changes, tt.wantChanges) {
+				t.Errorf("MergePatch() changes = %v, want %v", changes, tt.wantChanges)
+			}
+		})
+	}
+}
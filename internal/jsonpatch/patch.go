@@ -0,0 +1,216 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpointer"
+)
+
+// Operation is a single RFC 6902 JSON Patch operation.
+type Operation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// MarshalJSON always includes the value of add, replace and test
+// operations, even when it is null.
+func (op Operation) MarshalJSON() ([]byte, error) {
+	type wire struct {
+		Op    string          `json:"op"`
+		From  string          `json:"from,omitempty"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value,omitempty"`
+	}
+	w := wire{Op: op.Op, Path: op.Path, From: op.From}
+	switch op.Op {
+	case "add", "replace", "test":
+		value, err := json.Marshal(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		w.Value = value
+	}
+	return json.Marshal(w)
+}
+
+// Apply applies the patch operations to a copy of doc and returns the result.
+// Operations are applied in order; if any operation fails, the error is
+// returned and doc is left unmodified.
+func Apply(doc any, ops []Operation) (any, error) {
+	result := DeepCopy(doc)
+	for i, op := range ops {
+		var err error
+		result, err = applyOne(result, op)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return result, nil
+}
+
+// applyOne applies a single operation and returns the new document root.
+func applyOne(doc any, op Operation) (any, error) {
+	path, err := jsonpointer.Parse(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		return add(doc, path, DeepCopy(op.Value))
+
+	case "remove":
+		if len(path) == 0 {
+			return nil, fmt.Errorf("%w: cannot remove the document root", domain.ErrInvalidPatch)
+		}
+		return remove(doc, path)
+
+	case "replace":
+		if _, err := path.Get(doc); err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return DeepCopy(op.Value), nil
+		}
+		doc, err = remove(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, DeepCopy(op.Value))
+
+	case "move":
+		from, err := jsonpointer.Parse(op.From)
+		if err != nil {
+			return nil, err
+		}
+		if from.IsPrefixOf(path) {
+			return nil, fmt.Errorf("%w: cannot move %q into its own child %q", domain.ErrInvalidPatch, op.From, op.Path)
+		}
+		value, err := from.Get(doc)
+		if err != nil {
+			return nil, err
+		}
+		if len(from) == 0 {
+			// Moving the root onto itself is the only valid root move
+			return doc, nil
+		}
+		doc, err = remove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+
+	case "copy":
+		from, err := jsonpointer.Parse(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := from.Get(doc)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, DeepCopy(value))
+
+	case "test":
+		value, err := path.Get(doc)
+		if err != nil {
+			return nil, err
+		}
+		if !Equal(value, op.Value) {
+			return nil, fmt.Errorf("%w: value at %q does not match", domain.ErrPatchTestFailed, op.Path)
+		}
+		return doc, nil
+
+	default:
+		return nil, fmt.Errorf("%w: unknown op %q", domain.ErrInvalidPatch, op.Op)
+	}
+}
+
+// add inserts value at path. Object members are created or replaced, array
+// elements are inserted before the given index or appended with "-".
+func add(doc any, path jsonpointer.Pointer, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return mutateParent(doc, path, func(parent any, key string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			node[key] = value
+			return node, nil
+		case []any:
+			idx, err := jsonpointer.InsertIndex(key, len(node))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", domain.ErrPointerNotFound, path, err)
+			}
+			node = append(node, nil)
+			copy(node[idx+1:], node[idx:])
+			node[idx] = value
+			return node, nil
+		default:
+			return nil, fmt.Errorf("%w: %s: parent is not a container", domain.ErrPointerNotFound, path)
+		}
+	})
+}
+
+// remove deletes the value at path, which must exist.
+func remove(doc any, path jsonpointer.Pointer) (any, error) {
+	return mutateParent(doc, path, func(parent any, key string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			if _, ok := node[key]; !ok {
+				return nil, fmt.Errorf("%w: %s", domain.ErrPointerNotFound, path)
+			}
+			delete(node, key)
+			return node, nil
+		case []any:
+			idx, err := jsonpointer.ArrayIndex(key, len(node))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", domain.ErrPointerNotFound, path, err)
+			}
+			return append(node[:idx], node[idx+1:]...), nil
+		default:
+			return nil, fmt.Errorf("%w: %s: parent is not a container", domain.ErrPointerNotFound, path)
+		}
+	})
+}
+
+// mutateParent walks to the parent of path and calls fn with the parent
+// container and the final reference token. The container returned by fn
+// replaces the original, which lets fn grow or shrink arrays.
+func mutateParent(node any, path jsonpointer.Pointer, fn func(parent any, key string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(node, path[0])
+	}
+
+	key, rest := path[0], path[1:]
+	switch container := node.(type) {
+	case map[string]any:
+		child, ok := container[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: member %q", domain.ErrPointerNotFound, key)
+		}
+		updated, err := mutateParent(child, rest, fn)
+		if err != nil {
+			return nil, err
+		}
+		container[key] = updated
+		return container, nil
+	case []any:
+		idx, err := jsonpointer.ArrayIndex(key, len(container))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrPointerNotFound, err)
+		}
+		updated, err := mutateParent(container[idx], rest, fn)
+		if err != nil {
+			return nil, err
+		}
+		container[idx] = updated
+		return container, nil
+	default:
+		return nil, fmt.Errorf("%w: cannot index into scalar at %q", domain.ErrPointerNotFound, key)
+	}
+}
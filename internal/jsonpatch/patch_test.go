@@ -0,0 +1,192 @@
+package jsonpatch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpatch"
+)
+
+func mustDecode(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("failed to decode %q: %v", s, err)
+	}
+	return v
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:  "add object member",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			want:  `{"foo": "bar", "baz": "qux"}`,
+		},
+		{
+			name:  "add array element before index",
+			doc:   `{"foo": ["bar", "baz"]}`,
+			patch: `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			want:  `{"foo": ["bar", "qux", "baz"]}`,
+		},
+		{
+			name:  "add array element with dash appends",
+			doc:   `{"foo": ["bar"]}`,
+			patch: `[{"op": "add", "path": "/foo/-", "value": ["abc"]}]`,
+			want:  `{"foo": ["bar", ["abc"]]}`,
+		},
+		{
+			name:  "remove array element",
+			doc:   `{"foo": ["bar", "qux", "baz"]}`,
+			patch: `[{"op": "remove", "path": "/foo/1"}]`,
+			want:  `{"foo": ["bar", "baz"]}`,
+		},
+		{
+			name:  "replace value",
+			doc:   `{"baz": "qux", "foo": "bar"}`,
+			patch: `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			want:  `{"baz": "boo", "foo": "bar"}`,
+		},
+		{
+			name:  "replace root",
+			doc:   `{"foo": 1}`,
+			patch: `[{"op": "replace", "path": "", "value": [1, 2]}]`,
+			want:  `[1, 2]`,
+		},
+		{
+			name:  "move value",
+			doc:   `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			patch: `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			want:  `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		{
+			name:  "move array element",
+			doc:   `{"foo": ["all", "grass", "cows", "eat"]}`,
+			patch: `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			want:  `{"foo": ["all", "cows", "eat", "grass"]}`,
+		},
+		{
+			name:  "copy value is independent",
+			doc:   `{"a": {"b": 1}}`,
+			patch: `[{"op": "copy", "from": "/a", "path": "/c"}, {"op": "replace", "path": "/c/b", "value": 2}]`,
+			want:  `{"a": {"b": 1}, "c": {"b": 2}}`,
+		},
+		{
+			name:  "test passes with equal number",
+			doc:   `{"n": 10, "list": [1, "two"]}`,
+			patch: `[{"op": "test", "path": "/n", "value": 10.0}, {"op": "test", "path": "/list", "value": [1, "two"]}]`,
+			want:  `{"n": 10, "list": [1, "two"]}`,
+		},
+		{
+			name:    "test failure",
+			doc:     `{"baz": "qux"}`,
+			patch:   `[{"op": "test", "path": "/baz", "value": "bar"}]`,
+			wantErr: domain.ErrPatchTestFailed,
+		},
+		{
+			name:    "remove missing member",
+			doc:     `{"foo": 1}`,
+			patch:   `[{"op": "remove", "path": "/bar"}]`,
+			wantErr: domain.ErrPointerNotFound,
+		},
+		{
+			name:    "add to missing parent",
+			doc:     `{"foo": 1}`,
+			patch:   `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+			wantErr: domain.ErrPointerNotFound,
+		},
+		{
+			name:    "add beyond array end",
+			doc:     `[1, 2]`,
+			patch:   `[{"op": "add", "path": "/5", "value": 3}]`,
+			wantErr: domain.ErrPointerNotFound,
+		},
+		{
+			name:    "move into own child",
+			doc:     `{"a": {"b": 1}}`,
+			patch:   `[{"op": "move", "from": "/a", "path": "/a/b/c"}]`,
+			wantErr: domain.ErrInvalidPatch,
+		},
+		{
+			name:    "invalid pointer",
+			doc:     `{"a": 1}`,
+			patch:   `[{"op": "replace", "path": "a", "value": 2}]`,
+			wantErr: domain.ErrInvalidPointer,
+		},
+		{
+			name:    "unknown op",
+			doc:     `{"a": 1}`,
+			patch:   `[{"op": "increment", "path": "/a"}]`,
+			wantErr: domain.ErrInvalidPatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := mustDecode(t, tt.doc)
+			original := jsonpatch.DeepCopy(doc)
+
+			var ops []jsonpatch.Operation
+			if err := json.Unmarshal([]byte(tt.patch), &ops); err != nil {
+				t.Fatalf("failed to decode patch: %v", err)
+			}
+
+			got, err := jsonpatch.Apply(doc, ops)
+
+			// The input document must never be modified
+			if !jsonpatch.Equal(doc, original) {
+				t.Errorf("Apply() modified input document: %v", doc)
+			}
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Apply() unexpected error = %v", err)
+				return
+			}
+
+			if want := mustDecode(t, tt.want); !jsonpatch.Equal(got, want) {
+				t.Errorf("Apply() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a    any
+		b    any
+		want bool
+	}{
+		{name: "json.Number and float64", a: json.Number("42"), b: float64(42), want: true},
+		{name: "json.Number and int", a: json.Number("1.5"), b: 1, want: false},
+		{name: "large integers", a: json.Number("9007199254740993"), b: json.Number("9007199254740992"), want: false},
+		{name: "string and number", a: "1", b: float64(1), want: false},
+		{name: "nil and nil", a: nil, b: nil, want: true},
+		{name: "objects ignore key order", a: map[string]any{"a": 1, "b": 2}, b: map[string]any{"b": 2, "a": 1}, want: true},
+		{name: "arrays respect order", a: []any{1, 2}, b: []any{2, 1}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jsonpatch.Equal(tt.a, tt.b); got != tt.want {
+				t.Errorf("Equal(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
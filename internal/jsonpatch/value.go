@@ -0,0 +1,92 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"math/big"
+)
+
+// DeepCopy returns a deep copy of a decoded JSON value so that mutations
+// of the copy never affect the original.
+func DeepCopy(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, child := range val {
+			out[k] = DeepCopy(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, child := range val {
+			out[i] = DeepCopy(child)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// Equal reports whether two decoded JSON values are structurally equal.
+// Numbers compare by value regardless of whether they were decoded as
+// float64, json.Number or a Go integer type.
+func Equal(a, b any) bool {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, achild := range av {
+			bchild, ok := bv[k]
+			if !ok || !Equal(achild, bchild) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !Equal(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case string:
+		bv, ok := b.(string)
+		return ok && av == bv
+	case bool:
+		bv, ok := b.(bool)
+		return ok && av == bv
+	case nil:
+		return b == nil
+	}
+
+	an, aok := toNumber(a)
+	bn, bok := toNumber(b)
+	return aok && bok && an.Cmp(bn) == 0
+}
+
+// toNumber converts any JSON-compatible numeric value to an exact big.Float.
+func toNumber(v any) (*big.Float, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, _, err := big.ParseFloat(string(n), 10, 256, big.ToNearestEven)
+		return f, err == nil
+	case float64:
+		return new(big.Float).SetFloat64(n), true
+	case float32:
+		return new(big.Float).SetFloat64(float64(n)), true
+	case int:
+		return new(big.Float).SetInt64(int64(n)), true
+	case int64:
+		return new(big.Float).SetInt64(n), true
+	case int32:
+		return new(big.Float).SetInt64(int64(n)), true
+	case uint64:
+		return new(big.Float).SetUint64(n), true
+	}
+	return nil, false
+}
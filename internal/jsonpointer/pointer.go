@@ -0,0 +1,166 @@
+package jsonpointer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+)
+
+// Pointer is a parsed RFC 6901 JSON Pointer, stored as its unescaped
+// reference tokens. The empty pointer refers to the whole document.
+type Pointer []string
+
+// Parse parses an RFC 6901 JSON Pointer string such as "/data/items/3".
+func Parse(s string) (Pointer, error) {
+	if s == "" {
+		return Pointer{}, nil
+	}
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf("%w: %q must be empty or start with '/'", domain.ErrInvalidPointer, s)
+	}
+
+	parts := strings.Split(s[1:], "/")
+	tokens := make(Pointer, 0, len(parts))
+	for _, part := range parts {
+		token, err := unescape(part)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", domain.ErrInvalidPointer, s, err)
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// unescape decodes the ~0 and ~1 escape sequences of a reference token.
+func unescape(token string) (string, error) {
+	if !strings.Contains(token, "~") {
+		return token, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(token); i++ {
+		if token[i] != '~' {
+			b.WriteByte(token[i])
+			continue
+		}
+		if i+1 >= len(token) {
+			return "", fmt.Errorf("dangling '~' in token %q", token)
+		}
+		switch token[i+1] {
+		case '0':
+			b.WriteByte('~')
+		case '1':
+			b.WriteByte('/')
+		default:
+			return "", fmt.Errorf("invalid escape '~%c' in token %q", token[i+1], token)
+		}
+		i++
+	}
+	return b.String(), nil
+}
+
+// String formats the pointer back into its RFC 6901 string form.
+func (p Pointer) String() string {
+	if len(p) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, token := range p {
+		b.WriteByte('/')
+		token = strings.ReplaceAll(token, "~", "~0")
+		token = strings.ReplaceAll(token, "/", "~1")
+		b.WriteString(token)
+	}
+	return b.String()
+}
+
+// Append returns a new pointer with the given tokens added to the end.
+func (p Pointer) Append(tokens ...string) Pointer {
+	out := make(Pointer, 0, len(p)+len(tokens))
+	out = append(out, p...)
+	return append(out, tokens...)
+}
+
+// IsPrefixOf reports whether p is a proper prefix of other.
+func (p Pointer) IsPrefixOf(other Pointer) bool {
+	if len(p) >= len(other) {
+		return false
+	}
+	for i := range p {
+		if p[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Get returns the value the pointer refers to within doc.
+func (p Pointer) Get(doc any) (any, error) {
+	current := doc
+	for i, token := range p {
+		switch node := current.(type) {
+		case map[string]any:
+			val, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", domain.ErrPointerNotFound, p[:i+1])
+			}
+			current = val
+		case []any:
+			idx, err := ArrayIndex(token, len(node))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", domain.ErrPointerNotFound, p[:i+1], err)
+			}
+			current = node[idx]
+		default:
+			return nil, fmt.Errorf("%w: %s: cannot index into scalar", domain.ErrPointerNotFound, p[:i+1])
+		}
+	}
+	return current, nil
+}
+
+// ArrayIndex parses an array reference token and checks it is within [0, length).
+// Leading zeros and the "-" token are rejected, as RFC 6901 requires.
+func ArrayIndex(token string, length int) (int, error) {
+	idx, err := parseIndex(token)
+	if err != nil {
+		return 0, err
+	}
+	if idx >= length {
+		return 0, fmt.Errorf("index %d out of range (length %d)", idx, length)
+	}
+	return idx, nil
+}
+
+// parseIndex parses a non-negative array index without bounds checking.
+func parseIndex(token string) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	for _, c := range token {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid array index %q", token)
+		}
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	return idx, nil
+}
+
+// InsertIndex parses an array reference token for insertion, where "-" and
+// an index equal to the array length both mean "append".
+func InsertIndex(token string, length int) (int, error) {
+	if token == "-" {
+		return length, nil
+	}
+	idx, err := parseIndex(token)
+	if err != nil {
+		return 0, err
+	}
+	if idx > length {
+		return 0, fmt.Errorf("index %d out of range (length %d)", idx, length)
+	}
+	return idx, nil
+}
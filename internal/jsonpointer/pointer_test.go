@@ -0,0 +1,159 @@
+package jsonpointer_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpointer"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    jsonpointer.Pointer
+		wantErr error
+	}{
+		{
+			name:  "root pointer",
+			input: "",
+			want:  jsonpointer.Pointer{},
+		},
+		{
+			name:  "nested pointer",
+			input: "/data/items/3/name",
+			want:  jsonpointer.Pointer{"data", "items", "3", "name"},
+		},
+		{
+			name:  "escaped tokens",
+			input: "/a~1b/m~0n",
+			want:  jsonpointer.Pointer{"a/b", "m~n"},
+		},
+		{
+			name:  "empty token",
+			input: "/",
+			want:  jsonpointer.Pointer{""},
+		},
+		{
+			name:    "missing leading slash",
+			input:   "data/items",
+			wantErr: domain.ErrInvalidPointer,
+		},
+		{
+			name:    "invalid escape",
+			input:   "/a~2b",
+			wantErr: domain.ErrInvalidPointer,
+		},
+		{
+			name:    "dangling tilde",
+			input:   "/a~",
+			wantErr: domain.ErrInvalidPointer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := jsonpointer.Parse(tt.input)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Parse() unexpected error = %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %#v, want %#v", got, tt.want)
+			}
+
+			// Round-trip back to the original string
+			if got.String() != tt.input {
+				t.Errorf("String() = %q, want %q", got.String(), tt.input)
+			}
+		})
+	}
+}
+
+func TestPointer_Get(t *testing.T) {
+	doc := map[string]any{
+		"data": map[string]any{
+			"items": []any{
+				map[string]any{"name": "first"},
+				map[string]any{"name": "second"},
+			},
+		},
+		"a/b": "slash",
+	}
+
+	tests := []struct {
+		name    string
+		pointer string
+		want    any
+		wantErr error
+	}{
+		{
+			name:    "array element field",
+			pointer: "/data/items/1/name",
+			want:    "second",
+		},
+		{
+			name:    "escaped key",
+			pointer: "/a~1b",
+			want:    "slash",
+		},
+		{
+			name:    "missing key",
+			pointer: "/data/missing",
+			wantErr: domain.ErrPointerNotFound,
+		},
+		{
+			name:    "index out of range",
+			pointer: "/data/items/2",
+			wantErr: domain.ErrPointerNotFound,
+		},
+		{
+			name:    "leading zero index",
+			pointer: "/data/items/01",
+			wantErr: domain.ErrPointerNotFound,
+		},
+		{
+			name:    "index into scalar",
+			pointer: "/a~1b/0",
+			wantErr: domain.ErrPointerNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ptr, err := jsonpointer.Parse(tt.pointer)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error = %v", err)
+			}
+
+			got, err := ptr.Get(doc)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Get() unexpected error = %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Package jsonstream walks JSON documents token by token so that large
+// arrays can be processed one element at a time without loading the whole
+// document into memory.
+package jsonstream
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpointer"
+)
+
+// ElementFunc is called for each array element with its index, its decoded
+// value (numbers as json.Number) and the number of input bytes it spans.
+// Returning false stops the iteration.
+type ElementFunc func(index int, elem any, size int64) (bool, error)
+
+// ForEach streams the array found at path within the JSON text read from r
+// and calls fn for each of its elements in order. Path keys select object
+// members and numeric keys select array elements, as in json_query's
+// arrayPath. Content outside the array is skipped token by token.
+func ForEach(r io.Reader, path []string, fn ElementFunc) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	if err := navigate(dec, path); err != nil {
+		return err
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return invalid(err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		if ok {
+			// Consume the rest of the value so the error reflects its type
+			// rather than a syntax problem further along
+			if err := skipContainer(dec); err != nil {
+				return invalid(err)
+			}
+		}
+		return domain.ErrNotAnArray
+	}
+
+	for i := 0; dec.More(); i++ {
+		start := dec.InputOffset()
+		var elem any
+		if err := dec.Decode(&elem); err != nil {
+			return invalid(err)
+		}
+		more, err := fn(i, elem, dec.InputOffset()-start)
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+
+	// Check the remainder of the document is well formed
+	for {
+		if _, err := dec.Token(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return invalid(err)
+		}
+	}
+}
+
+// navigate advances dec to the start of the value at path.
+func navigate(dec *json.Decoder, path []string) error {
+	for _, key := range path {
+		tok, err := dec.Token()
+		if err != nil {
+			return invalid(err)
+		}
+		delim, ok := tok.(json.Delim)
+		if !ok {
+			return domain.ErrArrayPathNotFound
+		}
+
+		switch delim {
+		case '{':
+			found := false
+			for dec.More() {
+				name, err := dec.Token()
+				if err != nil {
+					return invalid(err)
+				}
+				if name == key {
+					found = true
+					break
+				}
+				if err := skipValue(dec); err != nil {
+					return invalid(err)
+				}
+			}
+			if !found {
+				return domain.ErrArrayPathNotFound
+			}
+
+		case '[':
+			idx, err := jsonpointer.ArrayIndex(key, math.MaxInt)
+			if err != nil {
+				return fmt.Errorf("%w: %v", domain.ErrArrayPathNotFound, err)
+			}
+			for i := 0; i < idx && dec.More(); i++ {
+				if err := skipValue(dec); err != nil {
+					return invalid(err)
+				}
+			}
+			if !dec.More() {
+				return fmt.Errorf("%w: index %d out of range", domain.ErrArrayPathNotFound, idx)
+			}
+		}
+	}
+	return nil
+}
+
+// skipValue consumes one complete value from dec.
+func skipValue(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if _, ok := tok.(json.Delim); ok {
+		return skipContainer(dec)
+	}
+	return nil
+}
+
+// skipContainer consumes the rest of an object or array whose opening
+// delimiter has already been read.
+func skipContainer(dec *json.Decoder) error {
+	for depth := 1; depth > 0; {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return nil
+}
+
+// invalid wraps a decoding error as invalid JSON.
+func invalid(err error) error {
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("%w: %v", domain.ErrInvalidJSON, err)
+}
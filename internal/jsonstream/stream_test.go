@@ -0,0 +1,131 @@
+package jsonstream_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonstream"
+)
+
+func TestForEach(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		path     []string
+		stopAt   int // stop after this many elements; 0 means never
+		want     []any
+		wantSize []int64
+		wantErr  error
+	}{
+		{
+			name:     "top-level array",
+			input:    `[1, "two", {"three": 3}]`,
+			want:     []any{json.Number("1"), "two", map[string]any{"three": json.Number("3")}},
+			wantSize: []int64{1, 7, 14},
+		},
+		{
+			name:  "nested path skips other members",
+			input: `{"meta": {"items": [0]}, "data": {"skip": [[1], {"x": []}], "items": [true, null]}}`,
+			path:  []string{"data", "items"},
+			want:  []any{true, nil},
+		},
+		{
+			name:  "numeric segment selects an element",
+			input: `{"groups": [{"m": [1]}, {"m": [2, 3]}]}`,
+			path:  []string{"groups", "1", "m"},
+			want:  []any{json.Number("2"), json.Number("3")},
+		},
+		{
+			name:   "stop early ignores the rest",
+			input:  `[1, 2, {"broken`,
+			stopAt: 2,
+			want:   []any{json.Number("1"), json.Number("2")},
+		},
+		{
+			name:    "missing member",
+			input:   `{"data": {}}`,
+			path:    []string{"data", "items"},
+			wantErr: domain.ErrArrayPathNotFound,
+		},
+		{
+			name:    "index out of range",
+			input:   `{"groups": [{}]}`,
+			path:    []string{"groups", "1"},
+			wantErr: domain.ErrArrayPathNotFound,
+		},
+		{
+			name:    "path through a scalar",
+			input:   `{"data": 1}`,
+			path:    []string{"data", "items"},
+			wantErr: domain.ErrArrayPathNotFound,
+		},
+		{
+			name:    "target is an object",
+			input:   `{"data": {"a": 1}}`,
+			path:    []string{"data"},
+			wantErr: domain.ErrNotAnArray,
+		},
+		{
+			name:    "malformed element",
+			input:   `[1, {"a": }]`,
+			wantErr: domain.ErrInvalidJSON,
+		},
+		{
+			name:    "malformed remainder",
+			input:   `{"items": [1], "x": ]`,
+			path:    []string{"items"},
+			wantErr: domain.ErrInvalidJSON,
+		},
+		{
+			name:    "truncated input",
+			input:   `{"items": `,
+			path:    []string{"items"},
+			wantErr: domain.ErrInvalidJSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []any
+			var sizes []int64
+			err := jsonstream.ForEach(strings.NewReader(tt.input), tt.path, func(i int, elem any, size int64) (bool, error) {
+				if i != len(got) {
+					t.Errorf("ForEach() index = %d, want %d", i, len(got))
+				}
+				got = append(got, elem)
+				sizes = append(sizes, size)
+				return tt.stopAt == 0 || len(got) < tt.stopAt, nil
+			})
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ForEach() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ForEach() unexpected error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ForEach() elements = %#v, want %#v", got, tt.want)
+			}
+			if tt.wantSize != nil && !reflect.DeepEqual(sizes, tt.wantSize) {
+				t.Errorf("ForEach() sizes = %v, want %v", sizes, tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestForEachPropagatesCallbackError(t *testing.T) {
+	stop := errors.New("stop")
+	err := jsonstream.ForEach(strings.NewReader(`[1, 2]`), nil, func(int, any, int64) (bool, error) {
+		return false, stop
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("ForEach() error = %v, want %v", err, stop)
+	}
+}
@@ -0,0 +1,125 @@
+package pathutil
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+)
+
+// Dir is the directory the reader and writer open files through, by names
+// relative to it.
+type Dir interface {
+	OpenFile(name string, flag int, perm fs.FileMode) (*os.File, error)
+	Lstat(name string) (fs.FileInfo, error)
+	MkdirAll(name string, perm fs.FileMode) error
+	Rename(oldname, newname string) error
+	Link(oldname, newname string) error
+	Remove(name string) error
+	Close() error
+}
+
+// OpenDir opens the workspace root containing path and returns it with the
+// name of path within it. The root is an *os.Root, which refuses every
+// name that leads out of it, through ".." or a symlink, at the moment a
+// file is opened, so a directory swapped for a symlink after path was
+// checked cannot redirect the access. A path in no root is
+// ErrOutsideWorkspace. Without roots, paths are not confined: the whole
+// file system is returned, and path as it is.
+func OpenDir(path string) (Dir, string, error) {
+	rootsMu.RLock()
+	allowed := roots
+	rootsMu.RUnlock()
+	if len(allowed) == 0 {
+		return hostDir{}, path, nil
+	}
+
+	// The last element stays as named: the root decides at open time
+	// whether a symlink there may be followed
+	clean := filepath.Clean(path)
+	realDir, err := realPath(filepath.Dir(clean))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s: %v", domain.ErrOutsideWorkspace, path, err)
+	}
+	real := filepath.Join(realDir, filepath.Base(clean))
+	base := ""
+	for _, root := range allowed {
+		if within(root.real, real) && len(root.real) > len(base) {
+			base = root.real
+		}
+	}
+	if base == "" {
+		return nil, "", fmt.Errorf("%w: %s", domain.ErrOutsideWorkspace, path)
+	}
+	name, err := filepath.Rel(base, real)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %s: %v", domain.ErrOutsideWorkspace, path, err)
+	}
+	root, err := os.OpenRoot(base)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: workspace root %s: %v", domain.ErrOutsideWorkspace, base, err)
+	}
+	return rootDir{root}, name, nil
+}
+
+// rootDir is a workspace root. It reports a name the root refused to
+// follow out of it as ErrOutsideWorkspace.
+type rootDir struct {
+	*os.Root
+}
+
+func (d rootDir) OpenFile(name string, flag int, perm fs.FileMode) (*os.File, error) {
+	f, err := d.Root.OpenFile(name, flag, perm)
+	return f, d.confine(name, err)
+}
+
+func (d rootDir) Lstat(name string) (fs.FileInfo, error) {
+	info, err := d.Root.Lstat(name)
+	return info, d.confine(name, err)
+}
+
+func (d rootDir) MkdirAll(name string, perm fs.FileMode) error {
+	return d.confine(name, d.Root.MkdirAll(name, perm))
+}
+
+func (d rootDir) Rename(oldname, newname string) error {
+	return d.confine(newname, d.Root.Rename(oldname, newname))
+}
+
+func (d rootDir) Link(oldname, newname string) error {
+	return d.confine(newname, d.Root.Link(oldname, newname))
+}
+
+// confine returns err, as ErrOutsideWorkspace when name now leads out of
+// the workspace. os.Root does not export the error it refuses with.
+func (d rootDir) confine(name string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if outside := Confine(filepath.Join(d.Name(), name)); outside != nil {
+		return fmt.Errorf("%w: %v", outside, err)
+	}
+	return err
+}
+
+// hostDir is the whole file system, for when no roots are set. Names are
+// paths.
+type hostDir struct{}
+
+func (hostDir) OpenFile(name string, flag int, perm fs.FileMode) (*os.File, error) {
+	return os.OpenFile(name, flag, perm)
+}
+
+func (hostDir) Lstat(name string) (fs.FileInfo, error) { return os.Lstat(name) }
+
+func (hostDir) MkdirAll(name string, perm fs.FileMode) error { return os.MkdirAll(name, perm) }
+
+func (hostDir) Rename(oldname, newname string) error { return os.Rename(oldname, newname) }
+
+func (hostDir) Link(oldname, newname string) error { return os.Link(oldname, newname) }
+
+func (hostDir) Remove(name string) error { return os.Remove(name) }
+
+func (hostDir) Close() error { return nil }
@@ -0,0 +1,88 @@
+package pathutil_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+)
+
+func TestOpenDir_SwappedSymlink(t *testing.T) {
+	workspace := t.TempDir()
+	outside := t.TempDir()
+	mustMkdir(t, filepath.Join(workspace, "notes"))
+	if err := pathutil.SetRoots([]string{workspace}); err != nil {
+		t.Fatalf("SetRoots() unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = pathutil.SetRoots(nil) })
+
+	dir, name, err := pathutil.OpenDir(filepath.Join(workspace, "notes", "a.md"))
+	if err != nil {
+		t.Fatalf("OpenDir() unexpected error: %v", err)
+	}
+	defer dir.Close()
+	if want := filepath.Join("notes", "a.md"); name != want {
+		t.Errorf("OpenDir() name = %v, want %v", name, want)
+	}
+
+	// Swap the checked directory for a symlink out of the workspace
+	if err := os.Remove(filepath.Join(workspace, "notes")); err != nil {
+		t.Fatal(err)
+	}
+	mustSymlink(t, outside, filepath.Join(workspace, "notes"))
+
+	f, err := dir.OpenFile(name, os.O_WRONLY|os.O_CREATE, 0644)
+	if err == nil {
+		f.Close()
+	}
+	if !errors.Is(err, domain.ErrOutsideWorkspace) {
+		t.Errorf("OpenFile() error = %v, wantErr %v", err, domain.ErrOutsideWorkspace)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "a.md")); !os.IsNotExist(err) {
+		t.Errorf("OpenFile() created a file outside the workspace")
+	}
+}
+
+func TestOpenDir(t *testing.T) {
+	workspace := t.TempDir()
+	outside := t.TempDir()
+	mustSymlink(t, outside, filepath.Join(workspace, "escape"))
+	if err := pathutil.SetRoots([]string{workspace}); err != nil {
+		t.Fatalf("SetRoots() unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = pathutil.SetRoots(nil) })
+
+	tests := []struct {
+		name     string
+		path     string
+		wantName string
+		wantErr  error
+	}{
+		{name: "file in root", path: filepath.Join(workspace, "a.md"), wantName: "a.md"},
+		{name: "missing directories", path: filepath.Join(workspace, "x", "y", "a.md"), wantName: filepath.Join("x", "y", "a.md")},
+		{name: "outside the roots", path: filepath.Join(outside, "a.md"), wantErr: domain.ErrOutsideWorkspace},
+		{name: "through a symlink out", path: filepath.Join(workspace, "escape", "a.md"), wantErr: domain.ErrOutsideWorkspace},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, name, err := pathutil.OpenDir(tt.path)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("OpenDir() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("OpenDir() unexpected error: %v", err)
+			}
+			defer dir.Close()
+			if name != tt.wantName {
+				t.Errorf("OpenDir() name = %v, want %v", name, tt.wantName)
+			}
+		})
+	}
+}
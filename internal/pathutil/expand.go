@@ -0,0 +1,81 @@
+package pathutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+)
+
+var (
+	expandMu   sync.RWMutex
+	expandHome bool
+	expandEnv  []string
+)
+
+// envRef matches a $NAME or ${NAME} reference to an environment variable.
+var envRef = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// SetExpansion sets the shorthands Resolve expands: a leading ~ to the
+// home directory when home is set, and $NAME or ${NAME} references to the
+// listed environment variables. Both are off by default.
+func SetExpansion(home bool, env []string) {
+	expandMu.Lock()
+	defer expandMu.Unlock()
+	expandHome = home
+	expandEnv = slices.Clone(env)
+}
+
+// expand applies the enabled expansions to path. A leading ~ or variable
+// reference that is not enabled is an error rather than a literal name,
+// which would quietly create a directory called "~" or "$DOCS". Other
+// references, such as a route file named $slug.tsx, are left as they are.
+func expand(path string) (string, error) {
+	expandMu.RLock()
+	home, env := expandHome, expandEnv
+	expandMu.RUnlock()
+
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		if !home {
+			return "", fmt.Errorf("%w: %s starts with ~, but home directory expansion is not enabled", domain.ErrInvalidPath, path)
+		}
+		dir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("%w: cannot expand ~: %v", domain.ErrInvalidPath, err)
+		}
+		path = dir + path[1:]
+	}
+
+	if m := envRef.FindStringSubmatchIndex(path); m != nil && m[0] == 0 {
+		if name := refName(path[m[0]:m[1]]); !slices.Contains(env, name) {
+			return "", fmt.Errorf("%w: %s starts with $%s, which is not an environment variable enabled for expansion", domain.ErrInvalidPath, path, name)
+		}
+	}
+
+	var expandErr error
+	expanded := envRef.ReplaceAllStringFunc(path, func(ref string) string {
+		name := refName(ref)
+		if !slices.Contains(env, name) {
+			return ref
+		}
+		value := os.Getenv(name)
+		if value == "" && expandErr == nil {
+			expandErr = fmt.Errorf("%w: environment variable %s in %s is not set", domain.ErrInvalidPath, name, path)
+		}
+		return value
+	})
+	if expandErr != nil {
+		return "", expandErr
+	}
+	return expanded, nil
+}
+
+// refName returns the variable name of a reference matched by envRef.
+func refName(ref string) string {
+	return strings.Trim(ref, "${}")
+}
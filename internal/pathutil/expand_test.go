@@ -0,0 +1,73 @@
+package pathutil_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+)
+
+func TestResolve_Expansion(t *testing.T) {
+	home := t.TempDir()
+	workspace := t.TempDir()
+	outside := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PROJECT_DOCS", filepath.Join(workspace, "docs"))
+	t.Setenv("OUTSIDE", outside)
+	t.Setenv("UP", "..")
+	t.Setenv("EMPTY", "")
+	t.Cleanup(func() { pathutil.SetExpansion(false, nil) })
+
+	tests := []struct {
+		name    string
+		home    bool
+		env     []string
+		roots   []string
+		path    string
+		want    string
+		wantErr error
+	}{
+		{name: "home expanded", home: true, path: "~/notes/today.md", want: filepath.Join(home, "notes", "today.md")},
+		{name: "bare home", home: true, path: "~", want: home},
+		{name: "home not enabled", path: "~/notes/today.md", wantErr: domain.ErrInvalidPath},
+		{name: "tilde inside a name", path: "/tmp/~draft.md", want: "/tmp/~draft.md"},
+		{name: "other user's home is a name", home: true, path: "/tmp/~bob", want: "/tmp/~bob"},
+		{name: "tilde directory kept", home: true, path: "/~/x", want: "/~/x"},
+		{name: "tilde directory kept without expansion", path: "/~/x", want: "/~/x"},
+		{name: "variable expanded", env: []string{"PROJECT_DOCS"}, path: "$PROJECT_DOCS/adr.md", want: filepath.Join(workspace, "docs", "adr.md")},
+		{name: "braced variable expanded", env: []string{"PROJECT_DOCS"}, path: "${PROJECT_DOCS}/adr.md", want: filepath.Join(workspace, "docs", "adr.md")},
+		{name: "leading variable not allowed", env: []string{"PROJECT_DOCS"}, path: "$HOME/adr.md", wantErr: domain.ErrInvalidPath},
+		{name: "later reference kept as a name", path: "/tmp/routes/$slug.tsx", want: "/tmp/routes/$slug.tsx"},
+		{name: "unset variable", env: []string{"EMPTY"}, path: "$EMPTY/adr.md", wantErr: domain.ErrInvalidPath},
+		{name: "expanded traversal", env: []string{"UP"}, path: "/tmp/$UP/etc/passwd", wantErr: domain.ErrPathTraversal},
+		{name: "expanded path within roots", env: []string{"PROJECT_DOCS"}, roots: []string{workspace}, path: "$PROJECT_DOCS/adr.md", want: filepath.Join(workspace, "docs", "adr.md")},
+		{name: "expanded path outside roots", env: []string{"OUTSIDE"}, roots: []string{workspace}, path: "$OUTSIDE/adr.md", wantErr: domain.ErrOutsideWorkspace},
+		{name: "home outside roots", home: true, roots: []string{workspace}, path: "~/notes.md", wantErr: domain.ErrOutsideWorkspace},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pathutil.SetExpansion(tt.home, tt.env)
+			if err := pathutil.SetRoots(tt.roots); err != nil {
+				t.Fatalf("SetRoots() unexpected error: %v", err)
+			}
+			t.Cleanup(func() { _ = pathutil.SetRoots(nil) })
+
+			got, err := pathutil.Resolve(tt.path)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package pathutil_test
+
+import (
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		want    bool
+	}{
+		{name: "exact match", pattern: "/repo/go.sum", path: "/repo/go.sum", want: true},
+		{name: "star within segment", pattern: "/repo/*.json", path: "/repo/package.json", want: true},
+		{name: "star does not cross segments", pattern: "/repo/*.json", path: "/repo/data/items.json", want: false},
+		{name: "double star matches nested", pattern: "/repo/**/*.json", path: "/repo/data/a/items.json", want: true},
+		{name: "double star matches zero segments", pattern: "/repo/**/*.json", path: "/repo/items.json", want: true},
+		{name: "trailing double star", pattern: "/repo/.git/**", path: "/repo/.git/objects/ab/cdef", want: true},
+		{name: "trailing double star excludes sibling", pattern: "/repo/.git/**", path: "/repo/.github/workflows/ci.yaml", want: false},
+		{name: "leading double star", pattern: "**/.env", path: "/home/user/project/.env", want: true},
+		{name: "question mark", pattern: "/repo/v?.md", path: "/repo/v1.md", want: true},
+		{name: "character class", pattern: "/repo/[ab].md", path: "/repo/c.md", want: false},
+		{name: "pattern longer than path", pattern: "/repo/data/*.json", path: "/repo/data", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pathutil.MatchGlob(tt.pattern, tt.path); got != tt.want {
+				t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,23 +2,36 @@ package pathutil
 
 import (
 	"path/filepath"
-	"strings"
 
 	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
 )
 
 // Resolve converts a relative or absolute path to a clean absolute path
-// and validates that it doesn't contain path traversal attempts.
+// and validates that it doesn't contain path traversal attempts, i.e. ".."
+// components. A leading ~ and environment variable references are expanded
+// first, when enabled with SetExpansion, so the checks apply to the result.
+// When workspace roots are set, relative paths resolve against the primary
+// root and the path, through any symlinks, must lie within one of the roots.
 func Resolve(path string) (string, error) {
 	if path == "" {
 		return "", domain.ErrInvalidPath
 	}
 
-	// Prevent path traversal attacks
-	if strings.Contains(path, "..") {
+	path, err := expand(path)
+	if err != nil {
+		return "", err
+	}
+
+	// Prevent path traversal attacks; names such as v1..v2.md are fine
+	if hasParentRef(path) {
 		return "", domain.ErrPathTraversal
 	}
 
+	// Relative paths belong to the primary workspace root, if any
+	if base := primaryRoot(); base != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(base, path)
+	}
+
 	// Convert to absolute path
 	absPath, err := filepath.Abs(path)
 	if err != nil {
@@ -28,5 +41,20 @@ func Resolve(path string) (string, error) {
 	// Clean the path (remove redundant separators, resolve . and ..)
 	cleanPath := filepath.Clean(absPath)
 
+	// Confine the path, through any symlinks, to the workspace roots
+	if err := Confine(cleanPath); err != nil {
+		return "", err
+	}
+
 	return cleanPath, nil
 }
+
+// hasParentRef reports whether any component of path is "..".
+func hasParentRef(path string) bool {
+	for _, name := range splitPath(path) {
+		if name == ".." {
+			return true
+		}
+	}
+	return false
+}
@@ -35,6 +35,26 @@ func TestResolve(t *testing.T) {
 			path:    "/tmp/../etc/passwd",
 			wantErr: domain.ErrPathTraversal,
 		},
+		{
+			name:    "parent reference as last component",
+			path:    "/tmp/notes/..",
+			wantErr: domain.ErrPathTraversal,
+		},
+		{
+			name:    "dots inside a name",
+			path:    "/tmp/v1..v2.md",
+			wantErr: nil,
+		},
+		{
+			name:    "name ending in dots",
+			path:    "notes...md",
+			wantErr: nil,
+		},
+		{
+			name:    "name starting with dots",
+			path:    "/tmp/..hidden/a.md",
+			wantErr: nil,
+		},
 		{
 			name:    "empty path",
 			path:    "",
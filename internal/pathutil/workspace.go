@@ -0,0 +1,236 @@
+package pathutil
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+)
+
+// maxSymlinks bounds the links followed while resolving one path, like the
+// kernel's ELOOP limit.
+const maxSymlinks = 40
+
+// workspaceRoot is an allowed directory, as given and with its symlinks
+// evaluated.
+type workspaceRoot struct {
+	path string
+	real string
+}
+
+var (
+	rootsMu sync.RWMutex
+	roots   []workspaceRoot
+)
+
+// SetRoots confines Resolve to the given directories and resolves relative
+// paths against the first of them, the primary root. Each root must be an
+// existing directory; paths are compared by where they really point, after
+// symlinks are evaluated. No roots leaves paths unconfined and relative to
+// the working directory.
+func SetRoots(dirs []string) error {
+	resolved := make([]workspaceRoot, 0, len(dirs))
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("%w: workspace root %s: %v", domain.ErrInvalidPath, dir, err)
+		}
+		real, err := filepath.EvalSymlinks(abs)
+		if err != nil {
+			return fmt.Errorf("%w: workspace root %s: %v", domain.ErrInvalidPath, dir, err)
+		}
+		info, err := os.Stat(real)
+		if err != nil {
+			return fmt.Errorf("%w: workspace root %s: %v", domain.ErrInvalidPath, dir, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%w: workspace root %s is not a directory", domain.ErrInvalidPath, dir)
+		}
+		resolved = append(resolved, workspaceRoot{path: filepath.Clean(abs), real: real})
+	}
+
+	rootsMu.Lock()
+	defer rootsMu.Unlock()
+	roots = resolved
+	return nil
+}
+
+// Roots returns the absolute paths of the workspace roots, primary first.
+func Roots() []string {
+	rootsMu.RLock()
+	defer rootsMu.RUnlock()
+	paths := make([]string, len(roots))
+	for i, root := range roots {
+		paths[i] = root.path
+	}
+	return paths
+}
+
+// Within reports whether path, after its symlinks are evaluated, lies in
+// dir or below it.
+func Within(dir, path string) bool {
+	realDir, err := realPath(filepath.Clean(dir))
+	if err != nil {
+		return false
+	}
+	real, err := realPath(filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	return within(realDir, real)
+}
+
+// primaryRoot returns the directory relative paths resolve against, or ""
+// when no roots are set.
+func primaryRoot() string {
+	rootsMu.RLock()
+	defer rootsMu.RUnlock()
+	if len(roots) == 0 {
+		return ""
+	}
+	return roots[0].path
+}
+
+// Confine returns ErrOutsideWorkspace unless the absolute path, after its
+// symlinks are evaluated, lies within one of the workspace roots. It checks
+// the path as it is now and is not race-free: a symlink swapped in after
+// the check can still redirect a later access. Files are therefore opened
+// through OpenDir, which holds them to the root as they are opened.
+func Confine(path string) error {
+	rootsMu.RLock()
+	allowed := roots
+	rootsMu.RUnlock()
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	real, err := realPath(path)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", domain.ErrOutsideWorkspace, path, err)
+	}
+	for _, root := range allowed {
+		if within(root.real, real) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", domain.ErrOutsideWorkspace, path)
+}
+
+// RealPath returns the absolute path with its symlinks evaluated, as
+// Confine sees it. Components that do not exist yet are kept as named.
+func RealPath(path string) (string, error) {
+	return realPath(filepath.Clean(path))
+}
+
+// RootRelative returns real, a path as returned by RealPath, relative to
+// the workspace root that contains it, the deepest one if roots nest.
+// Without roots, paths are relative to the working directory. It reports
+// false when no root, or the working directory, contains the path.
+func RootRelative(real string) (string, bool) {
+	rootsMu.RLock()
+	bases := make([]string, len(roots))
+	for i, root := range roots {
+		bases[i] = root.real
+	}
+	rootsMu.RUnlock()
+	if len(bases) == 0 {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", false
+		}
+		if wd, err = realPath(wd); err != nil {
+			return "", false
+		}
+		bases = []string{wd}
+	}
+
+	best := ""
+	for _, base := range bases {
+		if within(base, real) && len(base) > len(best) {
+			best = base
+		}
+	}
+	if best == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(best, real)
+	if err != nil {
+		return "", false
+	}
+	return rel, true
+}
+
+// within reports whether path is root or lies below it.
+func within(root, path string) bool {
+	if path == root {
+		return true
+	}
+	return strings.HasPrefix(path, strings.TrimSuffix(root, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// realPath evaluates the symlinks of an absolute path that may not exist
+// yet. Components are resolved one at a time, so a ".." in a link target
+// applies to where the link really points, and dangling links are followed
+// to the place a write would create. Missing components are kept as named.
+func realPath(path string) (string, error) {
+	volume := filepath.VolumeName(path)
+	resolved := volume + string(filepath.Separator)
+	pending := splitPath(path[len(volume):])
+
+	for links := 0; len(pending) > 0; {
+		name := pending[0]
+		pending = pending[1:]
+		if name == "." {
+			continue
+		}
+		if name == ".." {
+			resolved = filepath.Dir(resolved)
+			continue
+		}
+
+		next := filepath.Join(resolved, name)
+		info, err := os.Lstat(next)
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR) {
+			// A missing component is no link, nor is anything below it
+			resolved = next
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&fs.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+
+		if links++; links > maxSymlinks {
+			return "", errors.New("too many levels of symbolic links")
+		}
+		target, err := os.Readlink(next)
+		if err != nil {
+			return "", err
+		}
+		if filepath.IsAbs(target) {
+			volume = filepath.VolumeName(target)
+			resolved = volume + string(filepath.Separator)
+			target = target[len(volume):]
+		}
+		pending = append(splitPath(target), pending...)
+	}
+	return resolved, nil
+}
+
+// splitPath returns the non-empty components of a path.
+func splitPath(path string) []string {
+	return strings.FieldsFunc(path, isSeparator)
+}
+
+func isSeparator(r rune) bool {
+	return r == '/' || r == filepath.Separator
+}
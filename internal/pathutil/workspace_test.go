@@ -0,0 +1,123 @@
+package pathutil_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+)
+
+func TestResolve_WorkspaceRoots(t *testing.T) {
+	workspace := t.TempDir()
+	outside := t.TempDir()
+
+	mustMkdir(t, filepath.Join(workspace, "notes"))
+	mustSymlink(t, outside, filepath.Join(workspace, "escape"))
+	mustSymlink(t, filepath.Join(workspace, "notes"), filepath.Join(workspace, "alias"))
+	mustSymlink(t, filepath.Join(outside, "missing.md"), filepath.Join(workspace, "dangling.md"))
+	mustMkdir(t, filepath.Join(outside, "deep"))
+	mustSymlink(t, filepath.Join(outside, "deep"), filepath.Join(workspace, "deep"))
+	mustSymlink(t, "..", filepath.Join(outside, "deep", "up"))
+	mustSymlink(t, "../notes", filepath.Join(workspace, "notes", "self"))
+
+	if err := pathutil.SetRoots([]string{workspace}); err != nil {
+		t.Fatalf("SetRoots() unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = pathutil.SetRoots(nil) })
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{name: "root itself", path: workspace},
+		{name: "existing directory", path: filepath.Join(workspace, "notes")},
+		{name: "new file in new directory", path: filepath.Join(workspace, "drafts", "a.md")},
+		{name: "symlink within workspace", path: filepath.Join(workspace, "alias", "a.md")},
+		{name: "outside path", path: filepath.Join(outside, "a.md"), wantErr: domain.ErrOutsideWorkspace},
+		{name: "system file", path: "/etc/passwd", wantErr: domain.ErrOutsideWorkspace},
+		{name: "sibling with root as prefix", path: workspace + "-other/a.md", wantErr: domain.ErrOutsideWorkspace},
+		{name: "symlinked directory leading outside", path: filepath.Join(workspace, "escape", "a.md"), wantErr: domain.ErrOutsideWorkspace},
+		{name: "relative symlink within workspace", path: filepath.Join(workspace, "notes", "self", "a.md")},
+		{name: "name with dots", path: filepath.Join(workspace, "v1..v2.md")},
+		{name: "relative link target applied after symlinked parent", path: filepath.Join(workspace, "deep", "up", "a.md"), wantErr: domain.ErrOutsideWorkspace},
+		{name: "dangling symlink leading outside", path: filepath.Join(workspace, "dangling.md"), wantErr: domain.ErrOutsideWorkspace},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pathutil.Resolve(tt.path)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve() unexpected error: %v", err)
+			}
+			if got != filepath.Clean(tt.path) {
+				t.Errorf("Resolve() = %v, want %v", got, filepath.Clean(tt.path))
+			}
+		})
+	}
+}
+
+func TestResolve_RelativeToPrimaryRoot(t *testing.T) {
+	primary := t.TempDir()
+	secondary := t.TempDir()
+	if err := pathutil.SetRoots([]string{primary, secondary}); err != nil {
+		t.Fatalf("SetRoots() unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = pathutil.SetRoots(nil) })
+
+	got, err := pathutil.Resolve("docs/notes.md")
+	if err != nil {
+		t.Fatalf("Resolve() unexpected error: %v", err)
+	}
+	if want := filepath.Join(primary, "docs", "notes.md"); got != want {
+		t.Errorf("Resolve() = %v, want %v", got, want)
+	}
+}
+
+func TestSetRoots_Invalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file.md")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = pathutil.SetRoots(nil) })
+
+	tests := []struct {
+		name string
+		dir  string
+	}{
+		{name: "missing directory", dir: filepath.Join(t.TempDir(), "missing")},
+		{name: "regular file", dir: file},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := pathutil.SetRoots([]string{tt.dir}); !errors.Is(err, domain.ErrInvalidPath) {
+				t.Errorf("SetRoots() error = %v, wantErr %v", err, domain.ErrInvalidPath)
+			}
+		})
+	}
+}
+
+func mustMkdir(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustSymlink(t *testing.T, target, link string) {
+	t.Helper()
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+}
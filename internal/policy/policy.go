@@ -0,0 +1,132 @@
+// Package policy decides which tools may read or write which paths, from
+// the ordered allow/deny rules of the config file.
+package policy
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/config"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+)
+
+// Operation is what a tool does with a path.
+type Operation string
+
+const (
+	// Read covers tools that only read a file.
+	Read Operation = config.OperationRead
+	// Write covers tools that create or change a file.
+	Write Operation = config.OperationWrite
+)
+
+// Checker defines the behavior for authorizing tool operations on paths.
+// Interface is defined at the usage point (consumer-defined interface).
+type Checker interface {
+	Check(tool string, op Operation, path string) error
+}
+
+// RulePolicy implements Checker with the first matching config rule.
+type RulePolicy struct {
+	rules       []config.PolicyRule
+	defaultDeny bool
+}
+
+// New creates a policy from the config rules. Relative patterns match paths
+// relative to the workspace root that contains them, absolute patterns the
+// whole path; both after symlinks are evaluated.
+func New(p config.Policy) *RulePolicy {
+	rules := make([]config.PolicyRule, len(p.Rules))
+	for i, rule := range p.Rules {
+		rule.Patterns = slices.Clone(rule.Patterns)
+		for j, pattern := range rule.Patterns {
+			rule.Patterns[j] = realPattern(pattern)
+		}
+		rules[i] = rule
+	}
+	return &RulePolicy{
+		rules:       rules,
+		defaultDeny: p.Default == config.EffectDeny,
+	}
+}
+
+// Check returns ErrAccessDenied, naming the deciding rule, when tool may not
+// perform op on the absolute path. Rules see the path as it really is, so a
+// symlink into a denied directory is denied too.
+func (p *RulePolicy) Check(tool string, op Operation, path string) error {
+	real, err := pathutil.RealPath(path)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", domain.ErrAccessDenied, path, err)
+	}
+	// Relative patterns match within the containing workspace root; outside
+	// every root only patterns starting with ** can match
+	rel, ok := pathutil.RootRelative(real)
+	if !ok {
+		rel = strings.TrimLeft(filepath.ToSlash(real[len(filepath.VolumeName(real)):]), "/")
+	}
+
+	for i, rule := range p.rules {
+		if !matches(rule, tool, op, real, rel) {
+			continue
+		}
+		if rule.Effect == config.EffectAllow {
+			return nil
+		}
+		return fmt.Errorf("%w: %s may not %s %s (rule %s)", domain.ErrAccessDenied, tool, op, path, ruleName(i, rule))
+	}
+	if p.defaultDeny {
+		return fmt.Errorf("%w: %s may not %s %s (no rule allows it and the default is deny)", domain.ErrAccessDenied, tool, op, path)
+	}
+	return nil
+}
+
+// matches reports whether rule applies to tool performing op on the path
+// real, which is rel relative to its workspace root.
+func matches(rule config.PolicyRule, tool string, op Operation, real, rel string) bool {
+	if len(rule.Tools) > 0 && !slices.Contains(rule.Tools, tool) {
+		return false
+	}
+	if len(rule.Operations) > 0 && !slices.Contains(rule.Operations, string(op)) {
+		return false
+	}
+	for _, pattern := range rule.Patterns {
+		name := rel
+		if filepath.IsAbs(pattern) {
+			name = real
+		}
+		if pathutil.MatchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// realPattern evaluates the symlinks in the literal directories leading an
+// absolute pattern, so it compares with real paths. Relative patterns are
+// returned as they are.
+func realPattern(pattern string) string {
+	if !filepath.IsAbs(pattern) {
+		return pattern
+	}
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+	literal := 0
+	for literal < len(segments) && !strings.ContainsAny(segments[literal], "*?[") {
+		literal++
+	}
+	prefix, err := pathutil.RealPath(filepath.FromSlash(strings.Join(segments[:literal], "/")))
+	if err != nil {
+		return pattern
+	}
+	return filepath.Join(append([]string{prefix}, segments[literal:]...)...)
+}
+
+// ruleName identifies a rule by its name, or its position and effect.
+func ruleName(i int, rule config.PolicyRule) string {
+	if rule.Name != "" {
+		return fmt.Sprintf("%q", rule.Name)
+	}
+	return fmt.Sprintf("#%d: %s %s", i+1, rule.Effect, strings.Join(rule.Patterns, ", "))
+}
@@ -0,0 +1,130 @@
+package policy_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/config"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
+)
+
+func TestRulePolicy_Check(t *testing.T) {
+	p := policy.New(config.Policy{
+		Rules: []config.PolicyRule{
+			{Name: "protect repo internals", Effect: "deny", Operations: []string{"write"}, Patterns: []string{"/repo/.git/**", "/repo/**/.env", "/repo/go.sum"}},
+			{Effect: "allow", Operations: []string{"read"}, Patterns: []string{"/repo/**"}},
+			{Effect: "allow", Tools: []string{"json_write"}, Patterns: []string{"/repo/data/**"}},
+			{Effect: "deny", Tools: []string{"json_write"}, Patterns: []string{"/**"}},
+		},
+	})
+
+	tests := []struct {
+		name     string
+		tool     string
+		op       policy.Operation
+		path     string
+		wantErr  error
+		wantRule string
+	}{
+		{name: "write to .git denied", tool: "write", op: policy.Write, path: "/repo/.git/config", wantErr: domain.ErrAccessDenied, wantRule: `"protect repo internals"`},
+		{name: "nested .env denied", tool: "json_write", op: policy.Write, path: "/repo/app/.env", wantErr: domain.ErrAccessDenied, wantRule: `"protect repo internals"`},
+		{name: "go.sum denied", tool: "write", op: policy.Write, path: "/repo/go.sum", wantErr: domain.ErrAccessDenied},
+		{name: "reading .env allowed", tool: "json_read", op: policy.Read, path: "/repo/app/.env"},
+		{name: "json_write under data allowed", tool: "json_write", op: policy.Write, path: "/repo/data/items.json"},
+		{name: "json_write elsewhere denied", tool: "json_write", op: policy.Write, path: "/repo/config.json", wantErr: domain.ErrAccessDenied, wantRule: "#4: deny /**"},
+		{name: "other tools fall through to default allow", tool: "write", op: policy.Write, path: "/repo/README.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Check(tt.tool, tt.op, tt.path)
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Check() unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantRule) {
+				t.Errorf("Check() error = %q, want it to name rule %q", err, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestRulePolicy_DefaultDeny(t *testing.T) {
+	p := policy.New(config.Policy{
+		Default: "deny",
+		Rules: []config.PolicyRule{
+			{Effect: "allow", Patterns: []string{"/repo/**"}},
+		},
+	})
+
+	if err := p.Check("json_read", policy.Read, "/repo/a.json"); err != nil {
+		t.Errorf("Check() unexpected error: %v", err)
+	}
+	if err := p.Check("json_read", policy.Read, "/etc/a.json"); !errors.Is(err, domain.ErrAccessDenied) {
+		t.Errorf("Check() error = %v, wantErr %v", err, domain.ErrAccessDenied)
+	}
+}
+
+func TestRulePolicy_WorkspaceRelative(t *testing.T) {
+	workspace := t.TempDir()
+	other := t.TempDir()
+	for _, dir := range []string{".git", "docs"} {
+		if err := os.Mkdir(filepath.Join(workspace, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("../.git", filepath.Join(workspace, "docs", "g")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if err := pathutil.SetRoots([]string{workspace, other}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = pathutil.SetRoots(nil) })
+
+	p := policy.New(config.Policy{
+		Rules: []config.PolicyRule{
+			{Effect: "deny", Operations: []string{"write"}, Patterns: []string{".git/**", "**/.env"}},
+			{Effect: "deny", Patterns: []string{filepath.Join(other, "private", "**")}},
+		},
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr error
+	}{
+		{name: "relative pattern in the primary root", path: filepath.Join(workspace, ".git", "config"), wantErr: domain.ErrAccessDenied},
+		{name: "relative pattern in another root", path: filepath.Join(other, ".git", "config"), wantErr: domain.ErrAccessDenied},
+		{name: "nested .env", path: filepath.Join(other, "app", ".env"), wantErr: domain.ErrAccessDenied},
+		{name: "symlink into a denied directory", path: filepath.Join(workspace, "docs", "g", "config"), wantErr: domain.ErrAccessDenied},
+		{name: "relative pattern is anchored at the root", path: filepath.Join(workspace, "docs", ".git", "x")},
+		{name: "absolute pattern", path: filepath.Join(other, "private", "a.md"), wantErr: domain.ErrAccessDenied},
+		{name: "unmatched path", path: filepath.Join(workspace, "docs", "a.md")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Check("write", policy.Write, tt.path)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Check() unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
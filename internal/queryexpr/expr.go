@@ -0,0 +1,156 @@
+// Package queryexpr evaluates JMESPath expressions (https://jmespath.org)
+// over the document model of the JSON tools. Expressions are compiled up
+// front, so syntax errors and unknown functions are reported, with their
+// offset in the expression, before any file is read.
+package queryexpr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmespath/go-jmespath"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+)
+
+// functions are the built-in JMESPath functions.
+var functions = map[string]bool{
+	"abs": true, "avg": true, "ceil": true, "contains": true, "ends_with": true,
+	"floor": true, "join": true, "keys": true, "length": true, "map": true,
+	"max": true, "max_by": true, "merge": true, "min": true, "min_by": true,
+	"not_null": true, "reverse": true, "sort": true, "sort_by": true,
+	"starts_with": true, "sum": true, "to_array": true, "to_number": true,
+	"to_string": true, "type": true, "values": true,
+}
+
+// tokenNames spells out the parser's token names in its error messages.
+var tokenNames = strings.NewReplacer(
+	"tUnknown", "unknown token", "tStar", "'*'", "tDot", "'.'", "tFilter", "'[?'",
+	"tFlatten", "'[]'", "tLparen", "'('", "tRparen", "')'", "tLbracket", "'['",
+	"tRbracket", "']'", "tLbrace", "'{'", "tRbrace", "'}'", "tOr", "'||'",
+	"tPipe", "'|'", "tNumber", "number", "tUnquotedIdentifier", "identifier",
+	"tQuotedIdentifier", "quoted identifier", "tComma", "','", "tColon", "':'",
+	"tLTE", "'<='", "tLT", "'<'", "tGTE", "'>='", "tGT", "'>'", "tEQ", "'=='",
+	"tNE", "'!='", "tJSONLiteral", "literal", "tStringLiteral", "string",
+	"tCurrent", "'@'", "tExpref", "'&'", "tAnd", "'&&'", "tNot", "'!'",
+	"tEOF", "end of expression",
+)
+
+// Expr is a compiled JMESPath expression.
+type Expr struct {
+	source   string
+	compiled *jmespath.JMESPath
+}
+
+// Compile parses a JMESPath expression.
+func Compile(source string) (*Expr, error) {
+	compiled, err := jmespath.Compile(source)
+	if err != nil {
+		var syntaxErr jmespath.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			msg := tokenNames.Replace(strings.TrimPrefix(syntaxErr.Error(), "SyntaxError: "))
+			return nil, positionError(source, syntaxErr.Offset, msg)
+		}
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidExpression, err)
+	}
+	if err := checkFunctions(source); err != nil {
+		return nil, err
+	}
+	return &Expr{source: source, compiled: compiled}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Search evaluates the expression against data. Numbers in data, which may
+// be json.Number, are compared as float64, so results hold float64 numbers.
+func (e *Expr) Search(data any) (any, error) {
+	result, err := e.compiled.Search(normalize(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidExpression, err)
+	}
+	return result, nil
+}
+
+// positionError reports an error at a byte offset of the expression, with
+// a caret under the offending character.
+func positionError(source string, offset int, msg string) error {
+	offset = max(0, min(offset, len(source)))
+	return fmt.Errorf("%w: %s at offset %d\n%s\n%s^", domain.ErrInvalidExpression, msg, offset, source, strings.Repeat(" ", offset))
+}
+
+// checkFunctions rejects calls to functions that JMESPath does not define,
+// which the parser only detects when the expression is evaluated.
+func checkFunctions(source string) error {
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(source, i)
+		case isIdentStart(c):
+			start := i
+			for i < len(source) && isIdentChar(source[i]) {
+				i++
+			}
+			next := i
+			for next < len(source) && strings.IndexByte(" \t\r\n", source[next]) >= 0 {
+				next++
+			}
+			if name := source[start:i]; next < len(source) && source[next] == '(' && !functions[name] {
+				return positionError(source, start, fmt.Sprintf("unknown function %s()", name))
+			}
+		default:
+			i++
+		}
+	}
+	return nil
+}
+
+// skipQuoted returns the offset after the quoted section starting at i.
+func skipQuoted(source string, i int) int {
+	quote := source[i]
+	for i++; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return len(source)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+// normalize returns a copy of v with json.Number values converted to
+// float64, the only number type JMESPath functions and comparisons accept.
+func normalize(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, child := range val {
+			out[k] = normalize(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, child := range val {
+			out[i] = normalize(child)
+		}
+		return out
+	case json.Number:
+		if f, err := val.Float64(); err == nil {
+			return f
+		}
+	}
+	return v
+}
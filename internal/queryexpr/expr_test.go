@@ -0,0 +1,94 @@
+package queryexpr_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/queryexpr"
+)
+
+const investorsJSON = `[
+	{"id": "a", "type": "vc", "funds": 3, "regions": ["eu", "us"]},
+	{"id": "b", "type": "angel", "funds": 1, "regions": ["us"]},
+	{"id": "c", "type": "vc", "funds": 12, "regions": []}
+]`
+
+func TestSearch(t *testing.T) {
+	dec := json.NewDecoder(strings.NewReader(investorsJSON))
+	dec.UseNumber()
+	var data any
+	if err := dec.Decode(&data); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		want string
+	}{
+		{name: "filter", expr: "[?type == 'vc'].id", want: `["a","c"]`},
+		{name: "numeric comparison on json.Number", expr: "[?funds > `2`].id", want: `["a","c"]`},
+		{name: "projection", expr: "[*].{id: id, regions: length(regions)}", want: `[{"id":"a","regions":2},{"id":"b","regions":1},{"id":"c","regions":0}]`},
+		{name: "sort and slice", expr: "sort_by(@, &funds)[-2:].id", want: `["a","c"]`},
+		{name: "contains", expr: "[?contains(regions, 'eu')].id", want: `["a"]`},
+		{name: "aggregate", expr: "sum([*].funds)", want: `16`},
+		{name: "pipe", expr: "[?type == 'vc'] | [0].id", want: `"a"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := queryexpr.Compile(tt.expr)
+			if err != nil {
+				t.Fatalf("Compile() unexpected error: %v", err)
+			}
+			result, err := expr.Search(data)
+			if err != nil {
+				t.Fatalf("Search() unexpected error: %v", err)
+			}
+			got, err := json.Marshal(result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Search() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		expr       string
+		wantOffset string
+	}{
+		{name: "unclosed filter", expr: "[?type == 'vc'", wantOffset: "at offset 14"},
+		{name: "bad token", expr: "items[?a ==]", wantOffset: "at offset 11"},
+		{name: "unknown function", expr: "[?type == 'count('] | count(@)", wantOffset: "at offset 22"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := queryexpr.Compile(tt.expr)
+			if !errors.Is(err, domain.ErrInvalidExpression) {
+				t.Fatalf("Compile() error = %v, want %v", err, domain.ErrInvalidExpression)
+			}
+			if !strings.Contains(err.Error(), tt.wantOffset) {
+				t.Errorf("Compile() error = %q, want it to contain %q", err, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestSearch_TypeError(t *testing.T) {
+	expr, err := queryexpr.Compile("abs(@)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := expr.Search("text"); !errors.Is(err, domain.ErrInvalidExpression) {
+		t.Errorf("Search() error = %v, want %v", err, domain.ErrInvalidExpression)
+	}
+}
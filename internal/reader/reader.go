@@ -2,10 +2,14 @@ package reader
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
 )
 
 // FileReader defines the behavior for reading files.
@@ -14,12 +18,22 @@ type FileReader interface {
 	Read(ctx context.Context, path string) (string, error)
 }
 
+// StreamReader defines the behavior for reading files incrementally, for
+// files too large to hold in memory as a single string.
+type StreamReader interface {
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
+}
+
 // OSFileReader implements FileReader using the OS file system.
-type OSFileReader struct{}
+type OSFileReader struct {
+	maxBytes int64
+}
 
-// NewOSFileReader creates a new OS file system reader.
-func NewOSFileReader() *OSFileReader {
-	return &OSFileReader{}
+// NewOSFileReader creates a new OS file system reader. Read refuses files
+// larger than maxBytes; zero means no limit. Streaming reads are not
+// limited.
+func NewOSFileReader(maxBytes int64) *OSFileReader {
+	return &OSFileReader{maxBytes: maxBytes}
 }
 
 // Read reads the entire content of a file and returns it as a string.
@@ -28,16 +42,75 @@ func (r *OSFileReader) Read(ctx context.Context, path string) (string, error) {
 	if err := ctx.Err(); err != nil {
 		return "", err
 	}
-	content, err := os.ReadFile(path)
+	f, err := open(path)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return "", domain.ErrFileNotFound
+		return "", err
+	}
+	defer f.Close()
+
+	// Check the size up front, and bound the read in case the file grows
+	var src io.Reader = f
+	if r.maxBytes > 0 {
+		info, err := f.Stat()
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", domain.ErrReadFailed, err)
 		}
+		if info.Size() > r.maxBytes {
+			return "", TooLargeError(path, r.maxBytes)
+		}
+		src = io.LimitReader(f, r.maxBytes+1)
+	}
+	content, err := io.ReadAll(src)
+	if err != nil {
 		return "", fmt.Errorf("%w: %v", domain.ErrReadFailed, err)
 	}
+	if r.maxBytes > 0 && int64(len(content)) > r.maxBytes {
+		return "", TooLargeError(path, r.maxBytes)
+	}
 	return string(content), nil
 }
 
+// TooLargeError reports a file over the read size limit, with a hint on
+// reading it in parts.
+func TooLargeError(path string, limit int64) error {
+	return fmt.Errorf("%w: %s is larger than %d bytes; query parts of it with json_query (filters and limit) or jsonl_query, which stream the file",
+		domain.ErrReadTooLarge, path, limit)
+}
+
+// Open opens a file for streaming reads. The caller must close it.
+func (r *OSFileReader) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens a file for reading through its workspace root, so a symlink
+// swapped in after the path was resolved cannot lead the read out of it.
+func open(path string) (*os.File, error) {
+	dir, name, err := pathutil.OpenDir(path)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+
+	f, err := dir.OpenFile(name, os.O_RDONLY, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, domain.ErrFileNotFound
+		}
+		if errors.Is(err, domain.ErrOutsideWorkspace) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", domain.ErrReadFailed, err)
+	}
+	return f, nil
+}
+
 // InMemoryFileReader is a fake implementation for testing.
 // It stores files in memory rather than on disk.
 type InMemoryFileReader struct {
@@ -59,3 +132,12 @@ func (r *InMemoryFileReader) Read(ctx context.Context, path string) (string, err
 	}
 	return content, nil
 }
+
+// Open returns a reader over the content from memory.
+func (r *InMemoryFileReader) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	content, ok := r.Files[path]
+	if !ok {
+		return nil, domain.ErrFileNotFound
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
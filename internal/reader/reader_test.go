@@ -3,6 +3,7 @@ package reader_test
 import (
 	"context"
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -79,7 +80,7 @@ func TestOSFileReader_Read(t *testing.T) {
 			t.Fatalf("Failed to create test file: %v", err)
 		}
 
-		r := reader.NewOSFileReader()
+		r := reader.NewOSFileReader(0)
 		got, err := r.Read(context.Background(), path)
 		if err != nil {
 			t.Errorf("Read() error = %v", err)
@@ -92,7 +93,7 @@ func TestOSFileReader_Read(t *testing.T) {
 	})
 
 	t.Run("read non-existent file", func(t *testing.T) {
-		r := reader.NewOSFileReader()
+		r := reader.NewOSFileReader(0)
 		_, err := r.Read(context.Background(), filepath.Join(tmpDir, "missing.json"))
 
 		if !errors.Is(err, domain.ErrFileNotFound) {
@@ -110,7 +111,7 @@ func TestOSFileReader_Read(t *testing.T) {
 			t.Fatalf("Failed to create test file: %v", err)
 		}
 
-		r := reader.NewOSFileReader()
+		r := reader.NewOSFileReader(0)
 		got, err := r.Read(context.Background(), path)
 		if err != nil {
 			t.Errorf("Read() error = %v", err)
@@ -122,11 +123,26 @@ func TestOSFileReader_Read(t *testing.T) {
 		}
 	})
 
+	t.Run("read size limit", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "limited.json")
+		if err := os.WriteFile(path, []byte(`[1, 2, 3]`), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		if _, err := reader.NewOSFileReader(9).Read(context.Background(), path); err != nil {
+			t.Errorf("Read() at the limit error = %v", err)
+		}
+		_, err := reader.NewOSFileReader(8).Read(context.Background(), path)
+		if !errors.Is(err, domain.ErrReadTooLarge) {
+			t.Errorf("Read() error = %v, want %v", err, domain.ErrReadTooLarge)
+		}
+	})
+
 	t.Run("context cancelled", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel() // Cancel immediately
 
-		r := reader.NewOSFileReader()
+		r := reader.NewOSFileReader(0)
 		_, err := r.Read(ctx, filepath.Join(tmpDir, "any.json"))
 
 		if !errors.Is(err, context.Canceled) {
@@ -134,3 +150,37 @@ func TestOSFileReader_Read(t *testing.T) {
 		}
 	})
 }
+
+func TestOSFileReader_Open(t *testing.T) {
+	tmpDir := t.TempDir()
+	r := reader.NewOSFileReader(0)
+
+	t.Run("stream existing file", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "events.jsonl")
+		content := "{\"n\": 1}\n{\"n\": 2}\n"
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		rc, err := r.Open(context.Background(), path)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		defer rc.Close()
+
+		got, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		if string(got) != content {
+			t.Errorf("Open() content = %q, want %q", got, content)
+		}
+	})
+
+	t.Run("open non-existent file", func(t *testing.T) {
+		_, err := r.Open(context.Background(), filepath.Join(tmpDir, "missing.jsonl"))
+		if !errors.Is(err, domain.ErrFileNotFound) {
+			t.Errorf("Open() error = %v, want %v", err, domain.ErrFileNotFound)
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/config"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpointer"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
+)
+
+// Violation is a single schema failure at a location within the document.
+type Violation struct {
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+// Result describes the outcome of validating a document.
+// SchemaPath is empty when no schema applies to the document.
+type Result struct {
+	SchemaPath string
+	Violations []Violation
+}
+
+// Validator defines the behavior for validating JSON documents against JSON Schemas.
+// Interface is defined at the usage point (consumer-defined interface).
+type Validator interface {
+	Validate(ctx context.Context, docPath string, doc any, schemaPath string, guard PathGuard) (*Result, error)
+}
+
+// PathGuard resolves the path of a schema file about to be read, failing
+// when it may not be read, and returns the path to read.
+type PathGuard func(path string) (string, error)
+
+// FileValidator implements Validator for draft 2020-12 schemas stored as files.
+// The schema is chosen from, in order: the explicit schemaPath, a local path
+// in the document's "$schema" member, or the first matching config mapping.
+type FileValidator struct {
+	reader   reader.FileReader
+	mappings []config.SchemaMapping
+}
+
+// NewFileValidator creates a validator that loads schemas through r.
+func NewFileValidator(r reader.FileReader, mappings []config.SchemaMapping) *FileValidator {
+	return &FileValidator{
+		reader:   r,
+		mappings: mappings,
+	}
+}
+
+// Validate validates doc, which was (or will be) stored at docPath. An empty
+// schemaPath lets the validator pick the schema itself. Every schema file
+// read, including those named by "$schema" and "$ref", passes through
+// guard first; a nil guard reads paths as they are.
+func (v *FileValidator) Validate(ctx context.Context, docPath string, doc any, schemaPath string, guard PathGuard) (*Result, error) {
+	if schemaPath == "" {
+		schemaPath = v.resolveSchema(docPath, doc)
+	}
+	if schemaPath == "" {
+		return &Result{}, nil
+	}
+	if guard != nil {
+		guarded, err := guard(schemaPath)
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", schemaPath, err)
+		}
+		schemaPath = guarded
+	}
+
+	loader := &readerLoader{ctx: ctx, reader: v.reader, guard: guard}
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.UseLoader(loader)
+
+	compiled, err := compiler.Compile(schemaPath)
+	if err != nil {
+		if loader.guardErr != nil {
+			return nil, loader.guardErr
+		}
+		if errors.Is(err, domain.ErrFileNotFound) {
+			return nil, fmt.Errorf("%w: %s: schema file not found", domain.ErrInvalidSchema, schemaPath)
+		}
+		return nil, fmt.Errorf("%w: %s: %v", domain.ErrInvalidSchema, schemaPath, err)
+	}
+
+	result := &Result{SchemaPath: schemaPath, Violations: []Violation{}}
+	err = compiled.Validate(doc)
+	if err == nil {
+		return result, nil
+	}
+
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidSchema, err)
+	}
+	collectViolations(verr, &result.Violations)
+	sort.SliceStable(result.Violations, func(i, j int) bool {
+		return result.Violations[i].Pointer < result.Violations[j].Pointer
+	})
+	return result, nil
+}
+
+// resolveSchema picks the schema for a document when none was given explicitly.
+func (v *FileValidator) resolveSchema(docPath string, doc any) string {
+	if obj, ok := doc.(map[string]any); ok {
+		if ref, ok := obj["$schema"].(string); ok {
+			if local := localSchemaPath(docPath, ref); local != "" {
+				return local
+			}
+		}
+	}
+
+	for _, m := range v.mappings {
+		if pathutil.MatchGlob(m.Pattern, docPath) {
+			return m.Schema
+		}
+	}
+	return ""
+}
+
+// localSchemaPath turns a "$schema" value into a file path. Remote URLs are
+// ignored; relative paths are resolved against the document's directory.
+func localSchemaPath(docPath, ref string) string {
+	if strings.HasPrefix(ref, "file://") {
+		path, err := jsonschema.FileLoader{}.ToFile(ref)
+		if err != nil {
+			return ""
+		}
+		return path
+	}
+	if ref == "" || strings.Contains(ref, "://") {
+		return ""
+	}
+	if filepath.IsAbs(ref) {
+		return filepath.Clean(ref)
+	}
+	return filepath.Join(filepath.Dir(docPath), ref)
+}
+
+// printer renders violation messages
+var printer = message.NewPrinter(language.English)
+
+// collectViolations flattens a validation error tree into its leaf failures.
+func collectViolations(verr *jsonschema.ValidationError, out *[]Violation) {
+	if len(verr.Causes) == 0 {
+		*out = append(*out, Violation{
+			Pointer: jsonpointer.Pointer(verr.InstanceLocation).String(),
+			Message: verr.ErrorKind.LocalizedString(printer),
+		})
+		return
+	}
+	for _, cause := range verr.Causes {
+		collectViolations(cause, out)
+	}
+}
+
+// readerLoader loads schema files through a FileReader, passing each path,
+// including those of referenced schemas, through the guard first.
+type readerLoader struct {
+	ctx    context.Context
+	reader reader.FileReader
+	guard  PathGuard
+	// guardErr is the first refusal of the guard
+	guardErr error
+}
+
+// Load loads a schema from a file:// URL.
+func (l *readerLoader) Load(url string) (any, error) {
+	path, err := jsonschema.FileLoader{}.ToFile(url)
+	if err != nil {
+		return nil, fmt.Errorf("only local schema files are supported: %w", err)
+	}
+	if l.guard != nil {
+		if path, err = l.guard(path); err != nil {
+			if l.guardErr == nil {
+				l.guardErr = fmt.Errorf("schema %s: %w", url, err)
+			}
+			return nil, err
+		}
+	}
+	content, err := l.reader.Read(l.ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return jsonschema.UnmarshalJSON(strings.NewReader(content))
+}
@@ -0,0 +1,155 @@
+package schema_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/config"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
+	"github.com/robertbagge/markdown-writer-mcp/internal/schema"
+)
+
+const personSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "required": ["name"],
+  "properties": {
+    "name": {"type": "string"},
+    "age": {"type": "integer", "minimum": 0},
+    "tags": {"type": "array", "items": {"type": "string"}}
+  },
+  "additionalProperties": false
+}`
+
+func decode(t *testing.T, s string) any {
+	t.Helper()
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		t.Fatalf("failed to decode %q: %v", s, err)
+	}
+	return v
+}
+
+func TestFileValidator_Validate(t *testing.T) {
+	files := map[string]string{
+		"/schemas/person.json": personSchema,
+		"/schemas/ref.json":    `{"$ref": "person.json"}`,
+		"/schemas/broken.json": `{"type": 12}`,
+	}
+	mappings := []config.SchemaMapping{
+		{Pattern: "/data/people/**/*.json", Schema: "/schemas/person.json"},
+	}
+
+	tests := []struct {
+		name           string
+		docPath        string
+		doc            string
+		schemaPath     string
+		wantErr        error
+		wantSchema     string
+		wantViolations []schema.Violation
+	}{
+		{
+			name:           "explicit schema valid document",
+			docPath:        "/tmp/a.json",
+			doc:            `{"name": "Ada", "age": 36}`,
+			schemaPath:     "/schemas/person.json",
+			wantSchema:     "/schemas/person.json",
+			wantViolations: []schema.Violation{},
+		},
+		{
+			name:       "every violation is reported with its pointer",
+			docPath:    "/tmp/a.json",
+			doc:        `{"age": -1, "tags": ["ok", 7], "extra": true}`,
+			schemaPath: "/schemas/person.json",
+			wantSchema: "/schemas/person.json",
+			wantViolations: []schema.Violation{
+				{Pointer: "", Message: "missing property 'name'"},
+				{Pointer: "", Message: "additional properties 'extra' not allowed"},
+				{Pointer: "/age", Message: "minimum: got -1, want 0"},
+				{Pointer: "/tags/1", Message: "got number, want string"},
+			},
+		},
+		{
+			name:       "document $schema relative to document",
+			docPath:    "/data/person.json",
+			doc:        `{"$schema": "../schemas/ref.json", "name": 5}`,
+			wantSchema: "/schemas/ref.json",
+			wantViolations: []schema.Violation{
+				{Pointer: "", Message: "additional properties '$schema' not allowed"},
+				{Pointer: "/name", Message: "got number, want string"},
+			},
+		},
+		{
+			name:           "remote $schema falls back to config mapping",
+			docPath:        "/data/people/team/ada.json",
+			doc:            `{"$schema": "https://example.com/person.json", "name": "Ada"}`,
+			wantSchema:     "/schemas/person.json",
+			wantViolations: []schema.Violation{{Pointer: "", Message: "additional properties '$schema' not allowed"}},
+		},
+		{
+			name:           "config mapping",
+			docPath:        "/data/people/ada.json",
+			doc:            `{"name": "Ada"}`,
+			wantSchema:     "/schemas/person.json",
+			wantViolations: []schema.Violation{},
+		},
+		{
+			name:       "no schema applies",
+			docPath:    "/data/other.json",
+			doc:        `{"anything": true}`,
+			wantSchema: "",
+		},
+		{
+			name:       "missing schema file",
+			docPath:    "/tmp/a.json",
+			doc:        `{}`,
+			schemaPath: "/schemas/missing.json",
+			wantErr:    domain.ErrInvalidSchema,
+		},
+		{
+			name:       "invalid schema",
+			docPath:    "/tmp/a.json",
+			doc:        `{}`,
+			schemaPath: "/schemas/broken.json",
+			wantErr:    domain.ErrInvalidSchema,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memReader := reader.NewInMemoryFileReader()
+			memReader.Files = files
+			v := schema.NewFileValidator(memReader, mappings)
+
+			result, err := v.Validate(context.Background(), tt.docPath, decode(t, tt.doc), tt.schemaPath, nil)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Validate() unexpected error = %v", err)
+				return
+			}
+
+			if result.SchemaPath != tt.wantSchema {
+				t.Errorf("Validate() schema = %v, want %v", result.SchemaPath, tt.wantSchema)
+			}
+
+			if !reflect.DeepEqual(result.Violations, tt.wantViolations) {
+				t.Errorf("Validate() violations = %#v, want %#v", result.Violations, tt.wantViolations)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+// Package secrets finds likely credentials, such as private keys, cloud and
+// chat tokens and .env-style password assignments, in content about to be
+// written to disk.
+package secrets
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/config"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+)
+
+// Action is what the write tools do with detected secrets.
+type Action string
+
+const (
+	// ActionBlock fails the write.
+	ActionBlock Action = config.SecretsBlock
+	// ActionRedact replaces each secret before writing.
+	ActionRedact Action = config.SecretsRedact
+	// ActionWarn writes the content unchanged and reports the secrets.
+	ActionWarn Action = config.SecretsWarn
+)
+
+// Detector defines the behavior for finding secrets in content.
+// Interface is defined at the usage point (consumer-defined interface).
+type Detector interface {
+	Scan(content string) []Finding
+}
+
+// Finding is one likely secret in scanned content.
+type Finding struct {
+	// Rule names the detector that matched, e.g. "github-token".
+	Rule string
+	// Start and End are the byte offsets of the secret in the content.
+	Start, End int
+	// Line and Column locate Start, both 1-based.
+	Line, Column int
+}
+
+// String describes the finding without revealing the secret.
+func (f Finding) String() string {
+	return fmt.Sprintf("%s at line %d, column %d", f.Rule, f.Line, f.Column)
+}
+
+// rule is a named detector. The secret is the first capture group of
+// pattern, or the whole match when it has none.
+type rule struct {
+	name    string
+	pattern *regexp.Regexp
+	// valid, when set, rejects matches that are not secrets
+	valid func(secret string) bool
+}
+
+// rules lists the detectors, most specific first; a match overlapping an
+// earlier finding is dropped.
+var rules = []rule{
+	{
+		// The body is optional so an unterminated header is still caught;
+		// its base64 lines then fall to high-entropy.
+		name:    "private-key",
+		pattern: regexp.MustCompile(`-----BEGIN[A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----(?:[\s\S]*?-----END[A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----)?`),
+	},
+	{
+		name:    "aws-access-key-id",
+		pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`),
+	},
+	{
+		name:    "aws-secret-access-key",
+		pattern: regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`),
+	},
+	{
+		name:    "gcp-api-key",
+		pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}`),
+	},
+	{
+		name:    "gcp-oauth-token",
+		pattern: regexp.MustCompile(`\bya29\.[0-9A-Za-z_\-]{20,}`),
+	},
+	{
+		name:    "github-token",
+		pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`),
+	},
+	{
+		name:    "slack-token",
+		pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`),
+	},
+	{
+		name:    "slack-webhook",
+		pattern: regexp.MustCompile(`https://hooks\.slack\.com/services/T[A-Za-z0-9_]+/B[A-Za-z0-9_]+/[A-Za-z0-9_]+`),
+	},
+	{
+		name:    "env-assignment",
+		pattern: regexp.MustCompile(`(?m)^[ \t]*(?:export[ \t]+)?[A-Za-z0-9_]*(?i:secret|passwd|password|token|api_?key|private_?key|access_?key|credentials?)[A-Za-z0-9_]*[ \t]*=[ \t]*["']?([^\s"'#]{8,})`),
+		valid:   notPlaceholder,
+	},
+	{
+		name:    "high-entropy",
+		pattern: regexp.MustCompile(`[A-Za-z0-9+/_=\-]{32,}`),
+		valid:   highEntropy,
+	},
+}
+
+// Names returns the names of every detector.
+func Names() []string {
+	names := make([]string, len(rules))
+	for i, r := range rules {
+		names[i] = r.name
+	}
+	return names
+}
+
+// Scanner implements Detector with the built-in detectors.
+type Scanner struct {
+	rules []rule
+	allow []*regexp.Regexp
+}
+
+// New creates a scanner running every detector not disabled in cfg, and
+// ignoring secrets matched by one of its allow expressions. Unknown
+// detector names and invalid expressions are ErrInvalidConfig.
+func New(cfg config.Secrets) (*Scanner, error) {
+	for _, name := range cfg.Disable {
+		if !slices.Contains(Names(), name) {
+			return nil, fmt.Errorf("%w: unknown secret detector %q in writes.secrets.disable (want one of %s)",
+				domain.ErrInvalidConfig, name, strings.Join(Names(), ", "))
+		}
+	}
+	s := &Scanner{}
+	for _, r := range rules {
+		if !slices.Contains(cfg.Disable, r.name) {
+			s.rules = append(s.rules, r)
+		}
+	}
+	for i, expr := range cfg.Allow {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: writes.secrets.allow[%d]: %v", domain.ErrInvalidConfig, i, err)
+		}
+		s.allow = append(s.allow, re)
+	}
+	return s, nil
+}
+
+// Scan returns the secrets in content, in order of position.
+func (s *Scanner) Scan(content string) []Finding {
+	var findings []Finding
+	for _, r := range s.rules {
+		for _, m := range r.pattern.FindAllStringSubmatchIndex(content, -1) {
+			start, end := m[0], m[1]
+			if len(m) > 2 && m[2] >= 0 {
+				start, end = m[2], m[3]
+			}
+			secret := content[start:end]
+			if r.valid != nil && !r.valid(secret) {
+				continue
+			}
+			if s.allowed(secret) || overlaps(findings, start, end) {
+				continue
+			}
+			findings = append(findings, Finding{Rule: r.name, Start: start, End: end})
+		}
+	}
+
+	slices.SortFunc(findings, func(a, b Finding) int { return a.Start - b.Start })
+	for i := range findings {
+		before := content[:findings[i].Start]
+		findings[i].Line = strings.Count(before, "\n") + 1
+		findings[i].Column = len(before) - strings.LastIndexByte(before, '\n')
+	}
+	return findings
+}
+
+// allowed reports whether an allow expression matches secret.
+func (s *Scanner) allowed(secret string) bool {
+	for _, re := range s.allow {
+		if re.MatchString(secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// overlaps reports whether [start, end) overlaps one of findings.
+func overlaps(findings []Finding, start, end int) bool {
+	for _, f := range findings {
+		if start < f.End && f.Start < end {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns content with each finding replaced by a marker naming its
+// detector. The findings must come from scanning content.
+func Redact(content string, findings []Finding) string {
+	var b strings.Builder
+	last := 0
+	for _, f := range findings {
+		b.WriteString(content[last:f.Start])
+		b.WriteString("[REDACTED:" + f.Rule + "]")
+		last = f.End
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// placeholders mark assignment values that are not real secrets.
+var placeholders = []string{"changeme", "example", "placeholder", "xxxx", "your", "redacted", "****"}
+
+// notPlaceholder rejects variable references and placeholder values.
+func notPlaceholder(value string) bool {
+	if strings.ContainsAny(value[:1], "$<{%") {
+		return false
+	}
+	lower := strings.ToLower(value)
+	for _, p := range placeholders {
+		if strings.Contains(lower, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// minEntropy is the Shannon entropy, in bits per character, above which a
+// long token counts as random. Hex digests stay below it, at 4 at most.
+const minEntropy = 4.5
+
+// integrityHash matches a Subresource Integrity value, as lockfiles such
+// as npm's package-lock.json record for every package. It is a checksum,
+// however random it looks.
+var integrityHash = regexp.MustCompile(`^sha(?:1|256|384|512)-`)
+
+// highEntropy reports whether token mixes upper case letters, lower case
+// letters and digits and looks random. Integrity hashes are not secrets.
+func highEntropy(token string) bool {
+	if integrityHash.MatchString(token) {
+		return false
+	}
+	var upper, lower, digit bool
+	counts := make(map[rune]int)
+	for _, c := range token {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= '0' && c <= '9':
+			digit = true
+		}
+		counts[c]++
+	}
+	if !upper || !lower || !digit {
+		return false
+	}
+	entropy := 0.0
+	for _, n := range counts {
+		p := float64(n) / float64(len(token))
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= minEntropy
+}
@@ -0,0 +1,184 @@
+// Package tomldoc maps TOML files onto the document model of the JSON
+// tools: map[string]any, []any, string, json.Number, bool and nil.
+package tomldoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpointer"
+)
+
+// Decode parses a TOML document into a map. Integers and floats become
+// json.Number; dates and times become strings in their TOML form.
+func Decode(src []byte) (any, error) {
+	var doc map[string]any
+	if _, err := toml.Decode(string(src), &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidTOML, err)
+	}
+	return normalize(doc)
+}
+
+// Encode serializes a document as TOML. The root must be an object, and
+// null values, which TOML cannot represent, are rejected. Keys are written
+// in sorted order, plain values before tables; comments of an existing
+// file are not kept. Strings holding a TOML date or time, as produced by
+// Decode, are written as dates and times again.
+func Encode(doc any) ([]byte, error) {
+	root, ok := doc.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: the document root must be an object", domain.ErrInvalidTOML)
+	}
+	if err := checkNulls(root, jsonpointer.Pointer{}); err != nil {
+		return nil, err
+	}
+	root = restoreDates(root).(map[string]any)
+
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+	if err := enc.Encode(root); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidTOML, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// normalize converts decoded TOML values to the document model.
+func normalize(v any) (any, error) {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			n, err := normalize(child)
+			if err != nil {
+				return nil, err
+			}
+			val[k] = n
+		}
+		return val, nil
+	case []map[string]any:
+		arr := make([]any, len(val))
+		for i, table := range val {
+			n, err := normalize(table)
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = n
+		}
+		return arr, nil
+	case []any:
+		for i, child := range val {
+			n, err := normalize(child)
+			if err != nil {
+				return nil, err
+			}
+			val[i] = n
+		}
+		return val, nil
+	case int64:
+		return json.Number(strconv.FormatInt(val, 10)), nil
+	case float64:
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			return nil, fmt.Errorf("%w: %v cannot be represented in JSON", domain.ErrInvalidTOML, val)
+		}
+		return json.Number(formatFloat(val)), nil
+	case time.Time:
+		return formatTime(val), nil
+	}
+	return v, nil
+}
+
+// formatFloat writes a float so that it still reads as a float, keeping
+// the decimal point of whole numbers.
+func formatFloat(f float64) string {
+	if abs := math.Abs(f); abs != 0 && (abs >= 1e21 || abs < 1e-6) {
+		return strconv.FormatFloat(f, 'e', -1, 64)
+	}
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if math.Trunc(f) == f {
+		s += ".0"
+	}
+	return s
+}
+
+// formatTime writes a date or time in its TOML form. The decoder marks
+// local dates and times with dedicated locations.
+func formatTime(t time.Time) string {
+	switch t.Location().String() {
+	case "datetime-local":
+		return t.Format("2006-01-02T15:04:05.999999999")
+	case "date-local":
+		return t.Format("2006-01-02")
+	case "time-local":
+		return t.Format("15:04:05.999999999")
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// dateLayouts are the date and time forms TOML accepts.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+	"15:04:05.999999999",
+}
+
+// datetime is a date or time written verbatim by the encoder.
+type datetime string
+
+// MarshalTOML implements toml.Marshaler.
+func (d datetime) MarshalTOML() ([]byte, error) {
+	return []byte(d), nil
+}
+
+// restoreDates returns a copy of v with date and time strings replaced by
+// datetime values.
+func restoreDates(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, child := range val {
+			out[k] = restoreDates(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, child := range val {
+			out[i] = restoreDates(child)
+		}
+		return out
+	case string:
+		for _, layout := range dateLayouts {
+			if _, err := time.Parse(layout, val); err == nil {
+				return datetime(val)
+			}
+		}
+	}
+	return v
+}
+
+// checkNulls rejects null values, which have no TOML form.
+func checkNulls(v any, path jsonpointer.Pointer) error {
+	switch val := v.(type) {
+	case nil:
+		return fmt.Errorf("%w: TOML cannot represent null at %q", domain.ErrInvalidTOML, path.String())
+	case map[string]any:
+		for k, child := range val {
+			if err := checkNulls(child, path.Append(k)); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, child := range val {
+			if err := checkNulls(child, path.Append(strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package tomldoc_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tomldoc"
+)
+
+const pyproject = `# Project metadata
+[project]
+name = "app"
+version = "1.2.0"
+requires = 3
+ratio = 2.0
+released = 2024-05-01
+updated = 2024-05-01T10:00:00Z
+
+[[project.authors]]
+name = "Ada"
+
+[[project.authors]]
+name = "Grace"
+`
+
+func TestDecode(t *testing.T) {
+	got, err := tomldoc.Decode([]byte(pyproject))
+	if err != nil {
+		t.Fatalf("Decode() unexpected error: %v", err)
+	}
+	want := map[string]any{
+		"project": map[string]any{
+			"name":     "app",
+			"version":  "1.2.0",
+			"requires": json.Number("3"),
+			"ratio":    json.Number("2.0"),
+			"released": "2024-05-01",
+			"updated":  "2024-05-01T10:00:00Z",
+			"authors":  []any{map[string]any{"name": "Ada"}, map[string]any{"name": "Grace"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode() = %#v, want %#v", got, want)
+	}
+
+	for _, input := range []string{"a = ", "a = nan"} {
+		if _, err := tomldoc.Decode([]byte(input)); !errors.Is(err, domain.ErrInvalidTOML) {
+			t.Errorf("Decode(%q) error = %v, want %v", input, err, domain.ErrInvalidTOML)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	doc, err := tomldoc.Decode([]byte(pyproject))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := tomldoc.Encode(doc)
+	if err != nil {
+		t.Fatalf("Encode() unexpected error: %v", err)
+	}
+	want := `[project]
+name = "app"
+ratio = 2.0
+released = 2024-05-01
+requires = 3
+updated = 2024-05-01T10:00:00Z
+version = "1.2.0"
+
+[[project.authors]]
+name = "Ada"
+
+[[project.authors]]
+name = "Grace"
+`
+	if string(got) != want {
+		t.Errorf("Encode() =\n%s\nwant\n%s", got, want)
+	}
+
+	// The round trip keeps types
+	again, err := tomldoc.Decode(got)
+	if err != nil || !reflect.DeepEqual(again, doc) {
+		t.Errorf("Decode(Encode()) = %v, %v, want %v", again, err, doc)
+	}
+}
+
+func TestEncode_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  any
+	}{
+		{name: "array root", doc: []any{1}},
+		{name: "null value", doc: map[string]any{"a": map[string]any{"b": nil}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tomldoc.Encode(tt.doc); !errors.Is(err, domain.ErrInvalidTOML) {
+				t.Errorf("Encode() error = %v, want %v", err, domain.ErrInvalidTOML)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/csvdoc"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
+)
+
+// CSVToJSONTool defines the csv_to_json tool metadata
+var CSVToJSONTool = &mcp.Tool{
+	Name:        "csv_to_json",
+	Description: "Convert a CSV file with a header row into a JSON array of objects with atomic writes. Numbers, booleans and empty cells are inferred, and dot-separated column names build nested objects",
+}
+
+// CSVToJSONArgs defines the input parameters for the csv_to_json tool
+type CSVToJSONArgs struct {
+	Path           string   `json:"path" jsonschema:"Absolute or relative path to the CSV file to convert"`
+	OutputPath     string   `json:"outputPath" jsonschema:"Absolute or relative path of the JSON file to write; .yaml, .yml and .toml paths are written in that format"`
+	Delimiter      string   `json:"delimiter,omitempty" jsonschema:"Field delimiter: a single character such as ; or tab (default ,)"`
+	ArrayColumns   []string `json:"arrayColumns,omitempty" jsonschema:"Columns whose cells hold arrays joined with arraySeparator"`
+	ArraySeparator string   `json:"arraySeparator,omitempty" jsonschema:"Separator splitting the cells of arrayColumns (default ;)"`
+	KeepStrings    bool     `json:"keepStrings,omitempty" jsonschema:"Keep every cell as a string instead of inferring numbers, booleans and nulls"`
+}
+
+// CSVToJSONOutput defines the output structure for the csv_to_json tool
+type CSVToJSONOutput struct {
+	Path    string   `json:"path"`
+	Rows    int      `json:"rows"`
+	Columns []string `json:"columns"`
+	Size    int64    `json:"size"`
+	Secrets []string `json:"secrets,omitempty"`
+}
+
+// CSVToJSONHandler handles the csv_to_json tool invocation
+func CSVToJSONHandler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args CSVToJSONArgs,
+) (*mcp.CallToolResult, CSVToJSONOutput, error) {
+	// Resolve paths (validates and converts to absolute)
+	absPath, err := pathutil.Resolve(args.Path)
+	if err != nil {
+		return nil, CSVToJSONOutput{}, err
+	}
+	if err := authorize(CSVToJSONTool, policy.Read, absPath); err != nil {
+		return nil, CSVToJSONOutput{}, err
+	}
+	outPath, err := pathutil.Resolve(args.OutputPath)
+	if err != nil {
+		return nil, CSVToJSONOutput{}, err
+	}
+	if err := authorize(CSVToJSONTool, policy.Write, outPath); err != nil {
+		return nil, CSVToJSONOutput{}, err
+	}
+	delimiter, err := parseDelimiter(args.Delimiter)
+	if err != nil {
+		return nil, CSVToJSONOutput{}, err
+	}
+
+	slog.Info("csv_to_json tool called",
+		slog.String("path", absPath),
+		slog.String("outputPath", outPath),
+		slog.Bool("keepStrings", args.KeepStrings),
+	)
+
+	content, err := fileReader.Read(ctx, absPath)
+	if err != nil {
+		return nil, CSVToJSONOutput{}, err
+	}
+	records, columns, err := csvdoc.Decode([]byte(content), csvdoc.DecodeOptions{
+		Delimiter:      delimiter,
+		ArrayColumns:   args.ArrayColumns,
+		ArraySeparator: args.ArraySeparator,
+		KeepStrings:    args.KeepStrings,
+	})
+	if err != nil {
+		return nil, CSVToJSONOutput{}, err
+	}
+
+	unlock := lockPath(outPath)
+	defer unlock()
+
+	// The output replaces any existing file, so it is encoded as new and
+	// validated against the JSON Schema that applies to it, if any
+	updated, err := encodeForWrite(ctx, CSVToJSONTool, outPath, records, "")
+	if err != nil {
+		return nil, CSVToJSONOutput{}, err
+	}
+	updated, notes, err := guardSecrets(CSVToJSONTool, outPath, replacedContent(ctx, outPath), updated)
+	if err != nil {
+		return nil, CSVToJSONOutput{}, err
+	}
+	size, err := fileWriter.Write(ctx, outPath, updated)
+	if err != nil {
+		return nil, CSVToJSONOutput{}, err
+	}
+
+	output := CSVToJSONOutput{
+		Path:    outPath,
+		Rows:    len(records),
+		Columns: columns,
+		Size:    size,
+		Secrets: notes,
+	}
+
+	message := fmt.Sprintf("Successfully converted %d rows with %d columns to %s", output.Rows, len(columns), outPath)
+	return textResult(withSecretNotes(message, notes)), output, nil
+}
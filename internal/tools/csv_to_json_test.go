@@ -0,0 +1,124 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tools"
+	"github.com/robertbagge/markdown-writer-mcp/internal/writer"
+)
+
+func TestCSVToJSONHandler(t *testing.T) {
+	const seedCSV = "id,name,zip,active,hq.city,tags\n1,Acme,0150,true,Oslo,b2b;eu\n2,Beta,,false,,\n"
+
+	tests := []struct {
+		name        string
+		args        tools.CSVToJSONArgs
+		wantErr     error
+		wantRows    int
+		wantPath    string
+		wantContent string
+	}{
+		{
+			name:     "inferred types",
+			args:     tools.CSVToJSONArgs{Path: "/tmp/seed.csv", OutputPath: "/tmp/seed.json", ArrayColumns: []string{"tags"}},
+			wantRows: 2,
+			wantPath: "/tmp/seed.json",
+			wantContent: `[
+  {
+    "active": true,
+    "hq": {
+      "city": "Oslo"
+    },
+    "id": 1,
+    "name": "Acme",
+    "tags": [
+      "b2b",
+      "eu"
+    ],
+    "zip": "0150"
+  },
+  {
+    "active": false,
+    "hq": {
+      "city": null
+    },
+    "id": 2,
+    "name": "Beta",
+    "tags": [],
+    "zip": null
+  }
+]
+`,
+		},
+		{
+			name:     "strings to YAML",
+			args:     tools.CSVToJSONArgs{Path: "/tmp/seed.csv", OutputPath: "/tmp/seed.yaml", KeepStrings: true},
+			wantRows: 2,
+			wantPath: "/tmp/seed.yaml",
+			wantContent: `- active: "true"
+  hq:
+    city: Oslo
+  id: "1"
+  name: Acme
+  tags: b2b;eu
+  zip: "0150"
+- active: "false"
+  hq:
+    city: ""
+  id: "2"
+  name: Beta
+  tags: ""
+  zip: ""
+`,
+		},
+		{
+			name:    "missing file",
+			args:    tools.CSVToJSONArgs{Path: "/tmp/missing.csv", OutputPath: "/tmp/seed.json"},
+			wantErr: domain.ErrFileNotFound,
+		},
+		{
+			name:    "malformed CSV",
+			args:    tools.CSVToJSONArgs{Path: "/tmp/bad.csv", OutputPath: "/tmp/seed.json"},
+			wantErr: domain.ErrInvalidCSV,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memReader := reader.NewInMemoryFileReader()
+			memReader.Files = map[string]string{
+				"/tmp/seed.csv": seedCSV,
+				"/tmp/bad.csv":  "a,b\n1,2,3\n",
+			}
+			tools.SetFileReader(memReader)
+			memWriter := writer.NewInMemoryFileWriter()
+			tools.SetFileWriter(memWriter)
+
+			_, output, err := tools.CSVToJSONHandler(context.Background(), &mcp.CallToolRequest{}, tt.args)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("CSVToJSONHandler() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if len(memWriter.Files) != 0 {
+					t.Errorf("CSVToJSONHandler() wrote files on error: %v", memWriter.Files)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CSVToJSONHandler() unexpected error = %v", err)
+			}
+
+			if output.Rows != tt.wantRows {
+				t.Errorf("CSVToJSONHandler() rows = %v, want %v", output.Rows, tt.wantRows)
+			}
+			if got := memWriter.Files[tt.wantPath]; got != tt.wantContent {
+				t.Errorf("CSVToJSONHandler() content =\n%s\nwant\n%s", got, tt.wantContent)
+			}
+		})
+	}
+}
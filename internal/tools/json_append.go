@@ -0,0 +1,214 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpointer"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
+)
+
+// JSONAppendTool defines the json_append tool metadata
+var JSONAppendTool = &mcp.Tool{
+	Name:        "json_append",
+	Description: "Append items to a JSON array with atomic writes, optionally creating the file or array and rejecting duplicates by a unique key field",
+	InputSchema: inputSchemaFor[JSONAppendArgs](),
+}
+
+// JSONAppendArgs defines the input parameters for the json_append tool
+type JSONAppendArgs struct {
+	Path           string    `json:"path" jsonschema:"Absolute or relative path to the JSON file"`
+	ArrayPath      ArrayPath `json:"arrayPath,omitempty" jsonschema:"Path to array in JSON structure, as a list of keys (e.g., [\"data\", \"items\"]) or a JSON Pointer (e.g., \"/data/items\")"`
+	Items          []any     `json:"items" jsonschema:"Items to append, in order"`
+	UniqueKey      string    `json:"uniqueKey,omitempty" jsonschema:"Field whose value must be unique across the array (e.g., 'id'); items must then be objects. '$' makes the items themselves unique"`
+	SkipDuplicates bool      `json:"skipDuplicates,omitempty" jsonschema:"Skip items whose uniqueKey already exists instead of failing"`
+	Create         bool      `json:"create,omitempty" jsonschema:"Create the file and any missing objects along arrayPath if they do not exist"`
+}
+
+// JSONAppendOutput defines the output structure for the json_append tool
+type JSONAppendOutput struct {
+	Path     string   `json:"path"`
+	Appended int      `json:"appended"`
+	Skipped  []int    `json:"skipped"`
+	Length   int      `json:"length"`
+	Size     int64    `json:"size"`
+	Secrets  []string `json:"secrets,omitempty"`
+}
+
+// JSONAppendHandler handles the json_append tool invocation
+func JSONAppendHandler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args JSONAppendArgs,
+) (*mcp.CallToolResult, JSONAppendOutput, error) {
+	// Resolve path (validates and converts to absolute)
+	absPath, err := pathutil.Resolve(args.Path)
+	if err != nil {
+		return nil, JSONAppendOutput{}, err
+	}
+	if err := authorize(JSONAppendTool, policy.Write, absPath); err != nil {
+		return nil, JSONAppendOutput{}, err
+	}
+
+	if len(args.Items) == 0 {
+		return nil, JSONAppendOutput{}, fmt.Errorf("%w: items must contain at least one element", domain.ErrInvalidJSON)
+	}
+
+	// Validate every item before touching the file
+	for i, item := range args.Items {
+		if _, err := json.Marshal(item); err != nil {
+			return nil, JSONAppendOutput{}, fmt.Errorf("%w: item %d: %v", domain.ErrInvalidJSON, i, err)
+		}
+		if args.UniqueKey != "" && args.UniqueKey != elementField {
+			obj, ok := item.(map[string]any)
+			if !ok {
+				return nil, JSONAppendOutput{}, fmt.Errorf("%w: item %d is not an object", domain.ErrInvalidJSON, i)
+			}
+			if _, ok := obj[args.UniqueKey]; !ok {
+				return nil, JSONAppendOutput{}, fmt.Errorf("%w: item %d has no %q field", domain.ErrInvalidJSON, i, args.UniqueKey)
+			}
+		}
+	}
+
+	slog.Info("json_append tool called",
+		slog.String("path", absPath),
+		slog.Any("arrayPath", args.ArrayPath),
+		slog.Int("itemCount", len(args.Items)),
+		slog.String("uniqueKey", args.UniqueKey),
+	)
+
+	// Hold the file lock across the read-modify-write cycle
+	unlock := lockPath(absPath)
+	defer unlock()
+
+	content, doc, err := loadOrCreateDocument(ctx, absPath, args.ArrayPath, args.Create)
+	if err != nil {
+		return nil, JSONAppendOutput{}, err
+	}
+	if args.Create {
+		doc, err = ensureArray(doc, args.ArrayPath)
+		if err != nil {
+			return nil, JSONAppendOutput{}, err
+		}
+	}
+
+	target, err := navigateToPath(doc, args.ArrayPath)
+	if err != nil {
+		return nil, JSONAppendOutput{}, err
+	}
+	arr, ok := target.([]any)
+	if !ok {
+		return nil, JSONAppendOutput{}, domain.ErrNotAnArray
+	}
+
+	// Append items, enforcing the unique key against existing and new items
+	output := JSONAppendOutput{Path: absPath, Skipped: []int{}}
+	for i, item := range args.Items {
+		if args.UniqueKey != "" {
+			key, _ := getFieldValue(item, args.UniqueKey)
+			dupes := matchingIndices(arr, []Filter{{Field: args.UniqueKey, Op: "eq", Value: key}})
+			if len(dupes) > 0 {
+				if !args.SkipDuplicates {
+					return nil, JSONAppendOutput{}, fmt.Errorf("%w: item %d has %s=%v, already at index %d",
+						domain.ErrDuplicateKey, i, args.UniqueKey, key, dupes[0])
+				}
+				output.Skipped = append(output.Skipped, i)
+				continue
+			}
+		}
+		arr = append(arr, item)
+		output.Appended++
+	}
+	output.Length = len(arr)
+
+	if output.Appended == 0 {
+		message := fmt.Sprintf("All %d items were duplicates, nothing appended to %s", len(args.Items), absPath)
+		return textResult(message), output, nil
+	}
+
+	doc, err = replaceArray(doc, args.ArrayPath, arr)
+	if err != nil {
+		return nil, JSONAppendOutput{}, err
+	}
+	updated, err := encodeForWrite(ctx, JSONAppendTool, absPath, doc, content)
+	if err != nil {
+		return nil, JSONAppendOutput{}, err
+	}
+	updated, notes, err := guardSecrets(JSONAppendTool, absPath, content, updated)
+	if err != nil {
+		return nil, JSONAppendOutput{}, err
+	}
+	size, err := fileWriter.Write(ctx, absPath, updated)
+	if err != nil {
+		return nil, JSONAppendOutput{}, err
+	}
+	output.Secrets = notes
+	output.Size = size
+
+	message := fmt.Sprintf("Successfully appended %d items to %s (%d skipped, array length %d)",
+		output.Appended, absPath, len(output.Skipped), output.Length)
+	return textResult(withSecretNotes(message, notes)), output, nil
+}
+
+// loadOrCreateDocument reads and parses a JSON file, returning its raw
+// content alongside the document. When create is set, a missing file yields
+// empty content and an empty array (or an empty object when the array is
+// nested under arrayPath).
+func loadOrCreateDocument(ctx context.Context, path string, arrayPath []string, create bool) (string, any, error) {
+	content, err := fileReader.Read(ctx, path)
+	if err != nil {
+		if create && errors.Is(err, domain.ErrFileNotFound) {
+			if len(arrayPath) == 0 {
+				return "", []any{}, nil
+			}
+			return "", map[string]any{}, nil
+		}
+		return "", nil, err
+	}
+	doc, err := parseFileDocument(path, content)
+	if err != nil {
+		return "", nil, err
+	}
+	return content, doc, nil
+}
+
+// ensureArray creates any missing objects along arrayPath and an empty array
+// at its end. Existing values are never replaced.
+func ensureArray(doc any, arrayPath []string) (any, error) {
+	if len(arrayPath) == 0 {
+		return doc, nil
+	}
+
+	current := doc
+	for i, key := range arrayPath {
+		last := i == len(arrayPath)-1
+		switch node := current.(type) {
+		case map[string]any:
+			child, exists := node[key]
+			if !exists {
+				if last {
+					child = []any{}
+				} else {
+					child = map[string]any{}
+				}
+				node[key] = child
+			}
+			current = child
+		case []any:
+			idx, err := jsonpointer.ArrayIndex(key, len(node))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", domain.ErrArrayPathNotFound, err)
+			}
+			current = node[idx]
+		default:
+			return nil, domain.ErrArrayPathNotFound
+		}
+	}
+	return doc, nil
+}
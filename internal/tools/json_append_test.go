@@ -0,0 +1,295 @@
+package tools_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tools"
+	"github.com/robertbagge/markdown-writer-mcp/internal/writer"
+)
+
+func TestJSONAppendHandler(t *testing.T) {
+	const findingsJSON = `{"findings": [{"id": "f1", "severity": "high"}]}`
+
+	tests := []struct {
+		name         string
+		files        map[string]string
+		args         tools.JSONAppendArgs
+		wantErr      error
+		wantAppended int
+		wantSkipped  []int
+		wantLength   int
+		wantContent  string // empty means nothing should be written
+	}{
+		{
+			name:  "append to nested array",
+			files: map[string]string{"/tmp/findings.json": findingsJSON},
+			args: tools.JSONAppendArgs{
+				Path:      "/tmp/findings.json",
+				ArrayPath: tools.ArrayPath{"findings"},
+				Items: []any{
+					map[string]any{"id": "f2", "severity": "low"},
+					"free-form note",
+				},
+			},
+			wantAppended: 2,
+			wantSkipped:  []int{},
+			wantLength:   3,
+			wantContent:  `{"findings":[{"id":"f1","severity":"high"},{"id":"f2","severity":"low"},"free-form note"]}`,
+		},
+		{
+			name:  "duplicate key rejected",
+			files: map[string]string{"/tmp/findings.json": findingsJSON},
+			args: tools.JSONAppendArgs{
+				Path:      "/tmp/findings.json",
+				ArrayPath: tools.ArrayPath{"findings"},
+				UniqueKey: "id",
+				Items: []any{
+					map[string]any{"id": "f2"},
+					map[string]any{"id": "f1"},
+				},
+			},
+			wantErr: domain.ErrDuplicateKey,
+		},
+		{
+			name:  "duplicate key within batch rejected",
+			files: map[string]string{"/tmp/findings.json": findingsJSON},
+			args: tools.JSONAppendArgs{
+				Path:      "/tmp/findings.json",
+				ArrayPath: tools.ArrayPath{"findings"},
+				UniqueKey: "id",
+				Items: []any{
+					map[string]any{"id": "f2"},
+					map[string]any{"id": "f2"},
+				},
+			},
+			wantErr: domain.ErrDuplicateKey,
+		},
+		{
+			name:  "duplicates skipped",
+			files: map[string]string{"/tmp/findings.json": findingsJSON},
+			args: tools.JSONAppendArgs{
+				Path:           "/tmp/findings.json",
+				ArrayPath:      tools.ArrayPath{"findings"},
+				UniqueKey:      "id",
+				SkipDuplicates: true,
+				Items: []any{
+					map[string]any{"id": "f1"},
+					map[string]any{"id": "f2"},
+				},
+			},
+			wantAppended: 1,
+			wantSkipped:  []int{0},
+			wantLength:   2,
+			wantContent:  `{"findings":[{"id":"f1","severity":"high"},{"id":"f2"}]}`,
+		},
+		{
+			name:  "all duplicates does not write",
+			files: map[string]string{"/tmp/findings.json": findingsJSON},
+			args: tools.JSONAppendArgs{
+				Path:           "/tmp/findings.json",
+				ArrayPath:      tools.ArrayPath{"findings"},
+				UniqueKey:      "id",
+				SkipDuplicates: true,
+				Items:          []any{map[string]any{"id": "f1"}},
+			},
+			wantAppended: 0,
+			wantSkipped:  []int{0},
+			wantLength:   1,
+		},
+		{
+			name:  "unique primitive values",
+			files: map[string]string{"/tmp/tags.json": `{"tags": ["a", "b"]}`},
+			args: tools.JSONAppendArgs{
+				Path:           "/tmp/tags.json",
+				ArrayPath:      tools.ArrayPath{"tags"},
+				UniqueKey:      "$",
+				SkipDuplicates: true,
+				Items:          []any{"b", "c", "c"},
+			},
+			wantAppended: 1,
+			wantSkipped:  []int{0, 2},
+			wantLength:   3,
+			wantContent:  `{"tags":["a","b","c"]}`,
+		},
+		{
+			name:  "unique key requires object items",
+			files: map[string]string{"/tmp/findings.json": findingsJSON},
+			args: tools.JSONAppendArgs{
+				Path:      "/tmp/findings.json",
+				ArrayPath: tools.ArrayPath{"findings"},
+				UniqueKey: "id",
+				Items:     []any{"f3"},
+			},
+			wantErr: domain.ErrInvalidJSON,
+		},
+		{
+			name:  "create missing file and array",
+			files: map[string]string{},
+			args: tools.JSONAppendArgs{
+				Path:      "/tmp/new.json",
+				ArrayPath: tools.ArrayPath{"data", "items"},
+				Items:     []any{1},
+				Create:    true,
+			},
+			wantAppended: 1,
+			wantSkipped:  []int{},
+			wantLength:   1,
+			wantContent:  "{\n  \"data\": {\n    \"items\": [\n      1\n    ]\n  }\n}\n",
+		},
+		{
+			name:  "create top-level array file",
+			files: map[string]string{},
+			args: tools.JSONAppendArgs{
+				Path:   "/tmp/new.json",
+				Items:  []any{"a"},
+				Create: true,
+			},
+			wantAppended: 1,
+			wantSkipped:  []int{},
+			wantLength:   1,
+			wantContent:  "[\n  \"a\"\n]\n",
+		},
+		{
+			name:  "missing file without create",
+			files: map[string]string{},
+			args: tools.JSONAppendArgs{
+				Path:  "/tmp/new.json",
+				Items: []any{"a"},
+			},
+			wantErr: domain.ErrFileNotFound,
+		},
+		{
+			name:  "missing array without create",
+			files: map[string]string{"/tmp/findings.json": findingsJSON},
+			args: tools.JSONAppendArgs{
+				Path:      "/tmp/findings.json",
+				ArrayPath: tools.ArrayPath{"notes"},
+				Items:     []any{"a"},
+			},
+			wantErr: domain.ErrArrayPathNotFound,
+		},
+		{
+			name:  "target is not an array",
+			files: map[string]string{"/tmp/findings.json": findingsJSON},
+			args: tools.JSONAppendArgs{
+				Path:   "/tmp/findings.json",
+				Items:  []any{"a"},
+				Create: true,
+			},
+			wantErr: domain.ErrNotAnArray,
+		},
+		{
+			name:  "no items",
+			files: map[string]string{"/tmp/findings.json": findingsJSON},
+			args: tools.JSONAppendArgs{
+				Path: "/tmp/findings.json",
+			},
+			wantErr: domain.ErrInvalidJSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memReader := reader.NewInMemoryFileReader()
+			memReader.Files = tt.files
+			tools.SetFileReader(memReader)
+			memWriter := writer.NewInMemoryFileWriter()
+			tools.SetFileWriter(memWriter)
+
+			_, output, err := tools.JSONAppendHandler(
+				context.Background(),
+				&mcp.CallToolRequest{},
+				tt.args,
+			)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("JSONAppendHandler() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if len(memWriter.Files) != 0 {
+					t.Errorf("JSONAppendHandler() wrote files on error: %v", memWriter.Files)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("JSONAppendHandler() unexpected error = %v", err)
+				return
+			}
+
+			if output.Appended != tt.wantAppended {
+				t.Errorf("JSONAppendHandler() appended = %v, want %v", output.Appended, tt.wantAppended)
+			}
+			if !reflect.DeepEqual(output.Skipped, tt.wantSkipped) {
+				t.Errorf("JSONAppendHandler() skipped = %v, want %v", output.Skipped, tt.wantSkipped)
+			}
+			if output.Length != tt.wantLength {
+				t.Errorf("JSONAppendHandler() length = %v, want %v", output.Length, tt.wantLength)
+			}
+
+			got, written := memWriter.Files[output.Path]
+			if tt.wantContent == "" {
+				if written {
+					t.Errorf("JSONAppendHandler() wrote %q, want no write", got)
+				}
+				return
+			}
+			if got != tt.wantContent {
+				t.Errorf("JSONAppendHandler() written content = %q, want %q", got, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestJSONAppendHandler_ConcurrentCalls(t *testing.T) {
+	tools.SetFileReader(reader.NewOSFileReader(0))
+	tools.SetFileWriter(writer.NewOSFileWriter(writer.Limits{}))
+
+	path := filepath.Join(t.TempDir(), "log.json")
+	const calls = 25
+
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, err := tools.JSONAppendHandler(
+				context.Background(),
+				&mcp.CallToolRequest{},
+				tools.JSONAppendArgs{
+					Path:      path,
+					Items:     []any{map[string]any{"id": fmt.Sprintf("entry-%d", i)}},
+					UniqueKey: "id",
+					Create:    true,
+				},
+			)
+			if err != nil {
+				t.Errorf("JSONAppendHandler() unexpected error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read result: %v", err)
+	}
+	var items []any
+	if err := json.Unmarshal(content, &items); err != nil {
+		t.Fatalf("result is not a JSON array: %v", err)
+	}
+	if len(items) != calls {
+		t.Errorf("concurrent appends kept %d items, want %d", len(items), calls)
+	}
+}
@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
+)
+
+// JSONDeleteTool defines the json_delete tool metadata
+var JSONDeleteTool = &mcp.Tool{
+	Name:        "json_delete",
+	Description: "Delete JSON array elements matching filters with atomic writes. Supports dryRun and a maxAffected safety cap",
+	InputSchema: inputSchemaFor[JSONDeleteArgs](),
+}
+
+// JSONDeleteArgs defines the input parameters for the json_delete tool
+type JSONDeleteArgs struct {
+	Path        string    `json:"path" jsonschema:"Absolute or relative path to the JSON file"`
+	ArrayPath   ArrayPath `json:"arrayPath,omitempty" jsonschema:"Path to array in JSON structure, as a list of keys (e.g., [\"data\", \"items\"]) or a JSON Pointer (e.g., \"/data/items\")"`
+	Filters     []Filter  `json:"filters" jsonschema:"Array of filter conditions (AND logic) selecting the elements to delete"`
+	DryRun      bool      `json:"dryRun,omitempty" jsonschema:"Report matching elements without writing the file"`
+	MaxAffected *int      `json:"maxAffected,omitempty" jsonschema:"Fail without writing if more than this many elements match"`
+}
+
+// JSONDeleteOutput defines the output structure for the json_delete tool
+type JSONDeleteOutput struct {
+	Path    string   `json:"path"`
+	Deleted int      `json:"deleted"`
+	Indices []int    `json:"indices"`
+	Matches []any    `json:"matches,omitempty"`
+	DryRun  bool     `json:"dryRun"`
+	Size    int64    `json:"size"`
+	Secrets []string `json:"secrets,omitempty"`
+}
+
+// JSONDeleteHandler handles the json_delete tool invocation
+func JSONDeleteHandler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args JSONDeleteArgs,
+) (*mcp.CallToolResult, JSONDeleteOutput, error) {
+	// Resolve path (validates and converts to absolute)
+	absPath, err := pathutil.Resolve(args.Path)
+	if err != nil {
+		return nil, JSONDeleteOutput{}, err
+	}
+	if err := authorize(JSONDeleteTool, policy.Write, absPath); err != nil {
+		return nil, JSONDeleteOutput{}, err
+	}
+
+	// Refuse to silently wipe the whole array
+	if len(args.Filters) == 0 {
+		return nil, JSONDeleteOutput{}, fmt.Errorf("%w: json_delete requires at least one filter", domain.ErrInvalidFilter)
+	}
+
+	slog.Info("json_delete tool called",
+		slog.String("path", absPath),
+		slog.Any("arrayPath", args.ArrayPath),
+		slog.Int("filterCount", len(args.Filters)),
+		slog.Bool("dryRun", args.DryRun),
+	)
+
+	// Hold the file lock across the read-modify-write cycle
+	unlock := lockPath(absPath)
+	defer unlock()
+
+	content, doc, arr, err := loadJSONArray(ctx, absPath, args.ArrayPath)
+	if err != nil {
+		return nil, JSONDeleteOutput{}, err
+	}
+
+	indices := matchingIndices(arr, args.Filters)
+	if err := checkMaxAffected(len(indices), args.MaxAffected); err != nil {
+		return nil, JSONDeleteOutput{}, err
+	}
+
+	output := JSONDeleteOutput{
+		Path:    absPath,
+		Indices: indices,
+		DryRun:  args.DryRun,
+	}
+
+	if args.DryRun {
+		output.Matches = make([]any, len(indices))
+		for i, idx := range indices {
+			output.Matches[i] = arr[idx]
+		}
+		message := fmt.Sprintf("Dry run: %d elements would be deleted from %s", len(indices), absPath)
+		return textResult(message), output, nil
+	}
+
+	if len(indices) == 0 {
+		message := fmt.Sprintf("No elements matched in %s, nothing deleted", absPath)
+		return textResult(message), output, nil
+	}
+
+	// Keep every element that was not matched
+	kept := make([]any, 0, len(arr)-len(indices))
+	next := 0
+	for i, item := range arr {
+		if next < len(indices) && indices[next] == i {
+			next++
+			continue
+		}
+		kept = append(kept, item)
+	}
+
+	doc, err = replaceArray(doc, args.ArrayPath, kept)
+	if err != nil {
+		return nil, JSONDeleteOutput{}, err
+	}
+	updated, err := encodeForWrite(ctx, JSONDeleteTool, absPath, doc, content)
+	if err != nil {
+		return nil, JSONDeleteOutput{}, err
+	}
+	updated, notes, err := guardSecrets(JSONDeleteTool, absPath, content, updated)
+	if err != nil {
+		return nil, JSONDeleteOutput{}, err
+	}
+	size, err := fileWriter.Write(ctx, absPath, updated)
+	if err != nil {
+		return nil, JSONDeleteOutput{}, err
+	}
+	output.Secrets = notes
+	output.Deleted = len(indices)
+	output.Size = size
+
+	message := fmt.Sprintf("Successfully deleted %d elements from %s (%d bytes)", len(indices), absPath, size)
+	return textResult(withSecretNotes(message, notes)), output, nil
+}
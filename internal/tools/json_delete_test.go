@@ -0,0 +1,155 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tools"
+	"github.com/robertbagge/markdown-writer-mcp/internal/writer"
+)
+
+const tagsJSON = `{"tags": ["a", "b", "a"]}`
+
+func TestJSONDeleteHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        tools.JSONDeleteArgs
+		wantErr     error
+		wantDeleted int
+		wantIndices []int
+		wantContent string // empty means nothing should be written
+	}{
+		{
+			name: "delete matching elements",
+			args: tools.JSONDeleteArgs{
+				Path:      "/tmp/registry.json",
+				ArrayPath: tools.ArrayPath{"investors"},
+				Filters:   []tools.Filter{{Field: "type", Op: "eq", Value: "vc"}},
+			},
+			wantDeleted: 2,
+			wantIndices: []int{0, 2},
+			wantContent: `{
+  "investors": [
+    {
+      "id": "b",
+      "type": "angel",
+      "stage": "seed"
+    }
+  ]
+}`,
+		},
+		{
+			name: "no matches does not write",
+			args: tools.JSONDeleteArgs{
+				Path:      "/tmp/registry.json",
+				ArrayPath: tools.ArrayPath{"investors"},
+				Filters:   []tools.Filter{{Field: "type", Op: "eq", Value: "pe"}},
+			},
+			wantDeleted: 0,
+			wantIndices: []int{},
+		},
+		{
+			name: "dry run does not write",
+			args: tools.JSONDeleteArgs{
+				Path:      "/tmp/registry.json",
+				ArrayPath: tools.ArrayPath{"investors"},
+				Filters:   []tools.Filter{{Field: "stage", Op: "is_null"}},
+				DryRun:    true,
+			},
+			wantDeleted: 0,
+			wantIndices: []int{2},
+		},
+		{
+			name: "maxAffected exceeded",
+			args: tools.JSONDeleteArgs{
+				Path:        "/tmp/registry.json",
+				ArrayPath:   tools.ArrayPath{"investors"},
+				Filters:     []tools.Filter{{Field: "id", Op: "is_not_null"}},
+				MaxAffected: intPtr(1),
+			},
+			wantErr: domain.ErrTooManyMatches,
+		},
+		{
+			name: "filters are required",
+			args: tools.JSONDeleteArgs{
+				Path:      "/tmp/registry.json",
+				ArrayPath: tools.ArrayPath{"investors"},
+			},
+			wantErr: domain.ErrInvalidFilter,
+		},
+		{
+			name: "delete from a primitive array",
+			args: tools.JSONDeleteArgs{
+				Path:      "/tmp/tags.json",
+				ArrayPath: tools.ArrayPath{"tags"},
+				Filters:   []tools.Filter{{Field: "$", Op: "eq", Value: "a"}},
+			},
+			wantDeleted: 2,
+			wantIndices: []int{0, 2},
+			wantContent: `{"tags":["b"]}`,
+		},
+		{
+			name: "invalid arrayPath",
+			args: tools.JSONDeleteArgs{
+				Path:      "/tmp/registry.json",
+				ArrayPath: tools.ArrayPath{"funds"},
+				Filters:   []tools.Filter{{Field: "type", Op: "eq", Value: "vc"}},
+			},
+			wantErr: domain.ErrArrayPathNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memReader := reader.NewInMemoryFileReader()
+			memReader.Files = map[string]string{"/tmp/registry.json": registryJSON, "/tmp/tags.json": tagsJSON}
+			tools.SetFileReader(memReader)
+			memWriter := writer.NewInMemoryFileWriter()
+			tools.SetFileWriter(memWriter)
+
+			_, output, err := tools.JSONDeleteHandler(
+				context.Background(),
+				&mcp.CallToolRequest{},
+				tt.args,
+			)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("JSONDeleteHandler() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if len(memWriter.Files) != 0 {
+					t.Errorf("JSONDeleteHandler() wrote files on error: %v", memWriter.Files)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("JSONDeleteHandler() unexpected error = %v", err)
+				return
+			}
+
+			if output.Deleted != tt.wantDeleted {
+				t.Errorf("JSONDeleteHandler() deleted = %v, want %v", output.Deleted, tt.wantDeleted)
+			}
+			if !reflect.DeepEqual(output.Indices, tt.wantIndices) {
+				t.Errorf("JSONDeleteHandler() indices = %v, want %v", output.Indices, tt.wantIndices)
+			}
+
+			got, written := memWriter.Files[output.Path]
+			if tt.wantContent == "" {
+				if written {
+					t.Errorf("JSONDeleteHandler() wrote %q, want no write", got)
+				}
+				return
+			}
+			if got != tt.wantContent {
+				t.Errorf("JSONDeleteHandler() written content = %s, want %s", got, tt.wantContent)
+			}
+		})
+	}
+}
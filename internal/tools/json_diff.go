@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonfmt"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpatch"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
+)
+
+// JSONDiffTool defines the json_diff tool metadata
+var JSONDiffTool = &mcp.Tool{
+	Name:        "json_diff",
+	Description: "Compare a JSON file with another file or inline JSON content and return the structural differences as an RFC 6902 patch and a readable summary",
+}
+
+// maxSummaryValueLen caps how much of a value is quoted in a summary line
+const maxSummaryValueLen = 60
+
+// JSONDiffArgs defines the input parameters for the json_diff tool
+type JSONDiffArgs struct {
+	Path      string `json:"path" jsonschema:"Absolute or relative path to the original JSON file"`
+	OtherPath string `json:"otherPath,omitempty" jsonschema:"Path to the JSON file to compare against (exactly one of otherPath or content)"`
+	Content   string `json:"content,omitempty" jsonschema:"Inline JSON content to compare against (exactly one of otherPath or content)"`
+	ArrayKey  string `json:"arrayKey,omitempty" jsonschema:"Match array elements by this object field (e.g., 'id') instead of by position"`
+}
+
+// JSONDiffOutput defines the output structure for the json_diff tool
+type JSONDiffOutput struct {
+	Path      string                `json:"path"`
+	Identical bool                  `json:"identical"`
+	Patch     []jsonpatch.Operation `json:"patch"`
+	Summary   []string              `json:"summary"`
+}
+
+// JSONDiffHandler handles the json_diff tool invocation
+func JSONDiffHandler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args JSONDiffArgs,
+) (*mcp.CallToolResult, JSONDiffOutput, error) {
+	// Resolve path (validates and converts to absolute)
+	absPath, err := pathutil.Resolve(args.Path)
+	if err != nil {
+		return nil, JSONDiffOutput{}, err
+	}
+	if err := authorize(JSONDiffTool, policy.Read, absPath); err != nil {
+		return nil, JSONDiffOutput{}, err
+	}
+
+	if (args.OtherPath == "") == (args.Content == "") {
+		return nil, JSONDiffOutput{}, fmt.Errorf("%w: exactly one of otherPath or content is required", domain.ErrInvalidDiff)
+	}
+
+	slog.Info("json_diff tool called",
+		slog.String("path", absPath),
+		slog.String("otherPath", args.OtherPath),
+		slog.String("arrayKey", args.ArrayKey),
+	)
+
+	from, err := readJSONDocument(ctx, absPath)
+	if err != nil {
+		return nil, JSONDiffOutput{}, err
+	}
+
+	var to any
+	if args.OtherPath != "" {
+		otherPath, err := pathutil.Resolve(args.OtherPath)
+		if err != nil {
+			return nil, JSONDiffOutput{}, err
+		}
+		if err := authorize(JSONDiffTool, policy.Read, otherPath); err != nil {
+			return nil, JSONDiffOutput{}, err
+		}
+		to, err = readJSONDocument(ctx, otherPath)
+		if err != nil {
+			return nil, JSONDiffOutput{}, err
+		}
+	} else {
+		to, err = parseFileDocument(absPath, args.Content)
+		if err != nil {
+			return nil, JSONDiffOutput{}, err
+		}
+	}
+
+	diffs := jsonpatch.Compare(from, to, jsonpatch.DiffOptions{ArrayKey: args.ArrayKey})
+	ops := make([]jsonpatch.Operation, len(diffs))
+	for i, d := range diffs {
+		ops[i] = d.Operation
+	}
+	output := JSONDiffOutput{
+		Path:      absPath,
+		Identical: len(ops) == 0,
+		Patch:     ops,
+		Summary:   summarizeDiff(diffs),
+	}
+
+	message := "Documents are identical"
+	if !output.Identical {
+		message = fmt.Sprintf("%d differences:\n%s", len(ops), strings.Join(output.Summary, "\n"))
+	}
+	return textResult(message), output, nil
+}
+
+// readJSONDocument reads and parses a JSON, JSONC or JSON5 file.
+func readJSONDocument(ctx context.Context, path string) (any, error) {
+	content, err := fileReader.Read(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return parseFileDocument(path, content)
+}
+
+// summarizeDiff describes each difference in a line, quoting the old and
+// new values.
+func summarizeDiff(diffs []jsonpatch.Difference) []string {
+	summary := make([]string, 0, len(diffs))
+	for _, d := range diffs {
+		path := d.Path
+		if path == "" {
+			path = "(root)"
+		}
+		switch d.Op {
+		case "add":
+			summary = append(summary, fmt.Sprintf("added %s: %s", path, summaryValue(d.Value)))
+		case "remove":
+			summary = append(summary, fmt.Sprintf("removed %s: %s", path, summaryValue(d.Old)))
+		case "replace":
+			summary = append(summary, fmt.Sprintf("changed %s: %s -> %s", path, summaryValue(d.Old), summaryValue(d.Value)))
+		case "move":
+			summary = append(summary, fmt.Sprintf("moved %s -> %s", d.From, d.Path))
+		}
+	}
+	return summary
+}
+
+// summaryValue renders a value compactly, truncated for long values.
+func summaryValue(v any) string {
+	s, err := jsonfmt.Encode(v, jsonfmt.Options{Style: jsonfmt.StyleCompact})
+	if err != nil {
+		return "?"
+	}
+	if runes := []rune(s); len(runes) > maxSummaryValueLen {
+		return string(runes[:maxSummaryValueLen]) + "..."
+	}
+	return s
+}
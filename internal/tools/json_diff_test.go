@@ -0,0 +1,118 @@
+package tools_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tools"
+)
+
+func TestJSONDiffHandler(t *testing.T) {
+	files := map[string]string{
+		"/tmp/before.json": `{"name": "app", "items": [{"id": "a", "n": 1}, {"id": "b", "n": 2}]}`,
+		"/tmp/after.json":  "{\n  \"name\": \"app\",\n  \"items\": [\n    {\"id\": \"b\", \"n\": 2},\n    {\"id\": \"a\", \"n\": 1}\n  ]\n}\n",
+	}
+
+	tests := []struct {
+		name        string
+		args        tools.JSONDiffArgs
+		wantErr     error
+		wantPatch   string
+		wantSummary []string
+	}{
+		{
+			name:        "reformatted file is identical",
+			args:        tools.JSONDiffArgs{Path: "/tmp/before.json", Content: `{"items":[{"n":1,"id":"a"},{"n":2,"id":"b"}],"name":"app"}`},
+			wantPatch:   `[]`,
+			wantSummary: []string{},
+		},
+		{
+			name:        "compare files by index",
+			args:        tools.JSONDiffArgs{Path: "/tmp/before.json", OtherPath: "/tmp/after.json"},
+			wantPatch:   `[{"op":"replace","path":"/items/0/id","value":"b"},{"op":"replace","path":"/items/0/n","value":2},{"op":"replace","path":"/items/1/id","value":"a"},{"op":"replace","path":"/items/1/n","value":1}]`,
+			wantSummary: []string{`changed /items/0/id: "a" -> "b"`, `changed /items/0/n: 1 -> 2`, `changed /items/1/id: "b" -> "a"`, `changed /items/1/n: 2 -> 1`},
+		},
+		{
+			name:        "compare files by identity key",
+			args:        tools.JSONDiffArgs{Path: "/tmp/before.json", OtherPath: "/tmp/after.json", ArrayKey: "id"},
+			wantPatch:   `[{"op":"move","from":"/items/1","path":"/items/0"}]`,
+			wantSummary: []string{"moved /items/1 -> /items/0"},
+		},
+		{
+			name:        "inline content",
+			args:        tools.JSONDiffArgs{Path: "/tmp/before.json", Content: `{"name": null, "items": []}`},
+			wantPatch:   `[{"op":"remove","path":"/items/1"},{"op":"remove","path":"/items/0"},{"op":"replace","path":"/name","value":null}]`,
+			wantSummary: []string{`removed /items/1: {"id":"b","n":2}`, `removed /items/0: {"id":"a","n":1}`, `changed /name: "app" -> null`},
+		},
+		{
+			name:        "root replacement",
+			args:        tools.JSONDiffArgs{Path: "/tmp/before.json", Content: `[]`},
+			wantPatch:   `[{"op":"replace","path":"","value":[]}]`,
+			wantSummary: []string{`changed (root): {"items":[{"id":"a","n":1},{"id":"b","n":2}],"name":"app"} -> []`},
+		},
+		{
+			name:    "needs a comparison target",
+			args:    tools.JSONDiffArgs{Path: "/tmp/before.json"},
+			wantErr: domain.ErrInvalidDiff,
+		},
+		{
+			name:    "both comparison targets",
+			args:    tools.JSONDiffArgs{Path: "/tmp/before.json", OtherPath: "/tmp/after.json", Content: `{}`},
+			wantErr: domain.ErrInvalidDiff,
+		},
+		{
+			name:    "invalid inline content",
+			args:    tools.JSONDiffArgs{Path: "/tmp/before.json", Content: `{`},
+			wantErr: domain.ErrInvalidJSON,
+		},
+		{
+			name:    "other file not found",
+			args:    tools.JSONDiffArgs{Path: "/tmp/before.json", OtherPath: "/tmp/missing.json"},
+			wantErr: domain.ErrFileNotFound,
+		},
+		{
+			name:    "other path traversal",
+			args:    tools.JSONDiffArgs{Path: "/tmp/before.json", OtherPath: "/tmp/../etc/passwd"},
+			wantErr: domain.ErrPathTraversal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memReader := reader.NewInMemoryFileReader()
+			memReader.Files = files
+			tools.SetFileReader(memReader)
+
+			_, output, err := tools.JSONDiffHandler(context.Background(), &mcp.CallToolRequest{}, tt.args)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("JSONDiffHandler() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("JSONDiffHandler() unexpected error = %v", err)
+			}
+
+			patch, err := json.Marshal(output.Patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(patch) != tt.wantPatch {
+				t.Errorf("JSONDiffHandler() patch = %s, want %s", patch, tt.wantPatch)
+			}
+			if !reflect.DeepEqual(output.Summary, tt.wantSummary) {
+				t.Errorf("JSONDiffHandler() summary = %q, want %q", output.Summary, tt.wantSummary)
+			}
+			if output.Identical != (len(tt.wantSummary) == 0) {
+				t.Errorf("JSONDiffHandler() identical = %v", output.Identical)
+			}
+		})
+	}
+}
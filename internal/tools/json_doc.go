@@ -0,0 +1,218 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonc"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonfmt"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpatch"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpointer"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tomldoc"
+	"github.com/robertbagge/markdown-writer-mcp/internal/yamldoc"
+)
+
+// Document formats, chosen by file extension.
+const (
+	formatJSON  = "json"
+	formatJSONC = "jsonc"
+	formatYAML  = "yaml"
+	formatTOML  = "toml"
+)
+
+// documentFormat returns the document format of a file from its extension.
+// Files with any other extension are JSON.
+func documentFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return formatYAML
+	case ".toml":
+		return formatTOML
+	case ".jsonc", ".json5":
+		return formatJSONC
+	}
+	return formatJSON
+}
+
+// parseJSONDocument decodes file content for the mutating JSON tools.
+// Numbers are kept as json.Number so that rewriting a file never loses
+// precision on large integers.
+func parseJSONDocument(content string) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(content)))
+	dec.UseNumber()
+
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidJSON, err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("%w: unexpected data after top-level value", domain.ErrInvalidJSON)
+	}
+	return depthChecked(doc, nil)
+}
+
+// parseFileDocument decodes the content of a file for reading. YAML and
+// TOML files, recognized by their extension, are mapped onto the same
+// document model. Other files that are not standard JSON are read as JSONC
+// or JSON5, so that configuration files with comments and trailing commas
+// are accepted.
+func parseFileDocument(path, content string) (any, error) {
+	switch documentFormat(path) {
+	case formatYAML:
+		return depthChecked(yamldoc.Decode([]byte(content)))
+	case formatTOML:
+		return depthChecked(tomldoc.Decode([]byte(content)))
+	}
+
+	doc, err := parseJSONDocument(content)
+	if err == nil || !errors.Is(err, domain.ErrInvalidJSON) {
+		return doc, err
+	}
+	return depthChecked(jsonc.Decode([]byte(content)))
+}
+
+// marshalJSONDocument encodes a document for writing back to disk. The
+// indentation, trailing newline and key order of original are kept, so that
+// a small edit produces a small diff. An empty original (a new file) gets
+// two-space indentation.
+func marshalJSONDocument(doc any, original string) (string, error) {
+	return jsonfmt.Encode(doc, jsonfmt.DetectOptions(original))
+}
+
+// encodeForWrite validates a mutated document against its JSON Schema, if
+// one applies, and encodes it in the style of original for writing back.
+// A JSONC, JSON5 or YAML original is edited in place instead, so that its
+// comments survive. TOML files are rewritten without their comments.
+func encodeForWrite(ctx context.Context, tool *mcp.Tool, path string, doc any, original string) (string, error) {
+	if _, err := validateAgainstSchema(ctx, tool, path, doc, ""); err != nil {
+		return "", err
+	}
+
+	switch documentFormat(path) {
+	case formatYAML:
+		return encodeYAML(doc, original)
+	case formatTOML:
+		out, err := tomldoc.Encode(doc)
+		return string(out), err
+	}
+
+	if strings.TrimSpace(original) == "" || json.Valid([]byte(original)) {
+		return marshalJSONDocument(doc, original)
+	}
+
+	from, err := jsonc.Decode([]byte(original))
+	if err != nil {
+		return "", err
+	}
+	edited, err := jsonc.Patch([]byte(original), jsonpatch.Diff(from, doc, jsonpatch.DiffOptions{}))
+	if err != nil {
+		return "", err
+	}
+	return string(edited), nil
+}
+
+// encodeYAML encodes a document as YAML, patching original when there is
+// one so that its comments and layout are kept.
+func encodeYAML(doc any, original string) (string, error) {
+	if strings.TrimSpace(original) == "" {
+		out, err := yamldoc.Encode(doc)
+		return string(out), err
+	}
+	from, err := yamldoc.Decode([]byte(original))
+	if err != nil {
+		return "", err
+	}
+	edited, err := yamldoc.Patch([]byte(original), jsonpatch.Diff(from, doc, jsonpatch.DiffOptions{}))
+	if err != nil {
+		return "", err
+	}
+	return string(edited), nil
+}
+
+// loadJSONArray reads a JSON file and returns the parsed document together
+// with the array found at arrayPath. The raw content is returned as well so
+// that the file can be written back in its original style.
+func loadJSONArray(ctx context.Context, path string, arrayPath []string) (string, any, []any, error) {
+	content, err := fileReader.Read(ctx, path)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	doc, err := parseFileDocument(path, content)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	target, err := navigateToPath(doc, arrayPath)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	arr, ok := target.([]any)
+	if !ok {
+		return "", nil, nil, domain.ErrNotAnArray
+	}
+	return content, doc, arr, nil
+}
+
+// replaceArray puts arr back into doc at arrayPath and returns the new root.
+func replaceArray(doc any, arrayPath []string, arr []any) (any, error) {
+	if len(arrayPath) == 0 {
+		return arr, nil
+	}
+	op := jsonpatch.Operation{Op: "replace", Path: jsonpointer.Pointer(arrayPath).String(), Value: arr}
+	return jsonpatch.Apply(doc, []jsonpatch.Operation{op})
+}
+
+// checkMaxAffected enforces the optional maxAffected safety cap.
+func checkMaxAffected(matched int, maxAffected *int) error {
+	if maxAffected != nil && matched > *maxAffected {
+		return fmt.Errorf("%w: %d elements matched, maxAffected is %d", domain.ErrTooManyMatches, matched, *maxAffected)
+	}
+	return nil
+}
+
+// pathLock is the mutex of one path, shared by the calls holding or
+// waiting for it.
+type pathLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+var (
+	// pathLocksMu guards pathLocks
+	pathLocksMu sync.Mutex
+
+	// pathLocks holds one lock per absolute path in use so that
+	// read-modify-write tools never interleave on the same file. A lock is
+	// dropped once no call holds or waits for it.
+	pathLocks = map[string]*pathLock{}
+)
+
+// lockPath locks the given absolute path and returns the unlock function.
+func lockPath(path string) func() {
+	pathLocksMu.Lock()
+	l, ok := pathLocks[path]
+	if !ok {
+		l = &pathLock{}
+		pathLocks[path] = l
+	}
+	l.refs++
+	pathLocksMu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		pathLocksMu.Lock()
+		defer pathLocksMu.Unlock()
+		if l.refs--; l.refs == 0 {
+			delete(pathLocks, path)
+		}
+	}
+}
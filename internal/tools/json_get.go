@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpointer"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
+)
+
+// JSONGetTool defines the json_get tool metadata
+var JSONGetTool = &mcp.Tool{
+	Name:        "json_get",
+	Description: "Read the subtree of a JSON file addressed by an RFC 6901 JSON Pointer, with its type and size. JSONC, JSON5, YAML (.yaml, .yml) and TOML (.toml) files are accepted",
+}
+
+// JSONGetArgs defines the input parameters for the json_get tool
+type JSONGetArgs struct {
+	Path    string `json:"path" jsonschema:"Absolute or relative path to the JSON file"`
+	Pointer string `json:"pointer" jsonschema:"JSON Pointer to the value to read (e.g., '/data/items/3/name'). Empty string selects the whole document"`
+}
+
+// JSONGetOutput defines the output structure for the json_get tool
+type JSONGetOutput struct {
+	Path    string   `json:"path"`
+	Pointer string   `json:"pointer"`
+	Type    string   `json:"type"`
+	Value   any      `json:"value"`
+	Length  *int     `json:"length,omitempty"`
+	Keys    []string `json:"keys,omitempty"`
+}
+
+// JSONGetHandler handles the json_get tool invocation
+func JSONGetHandler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args JSONGetArgs,
+) (*mcp.CallToolResult, JSONGetOutput, error) {
+	// Resolve path (validates and converts to absolute)
+	absPath, err := pathutil.Resolve(args.Path)
+	if err != nil {
+		return nil, JSONGetOutput{}, err
+	}
+	if err := authorize(JSONGetTool, policy.Read, absPath); err != nil {
+		return nil, JSONGetOutput{}, err
+	}
+
+	ptr, err := jsonpointer.Parse(args.Pointer)
+	if err != nil {
+		return nil, JSONGetOutput{}, err
+	}
+
+	slog.Info("json_get tool called",
+		slog.String("path", absPath),
+		slog.String("pointer", args.Pointer),
+	)
+
+	// Read and parse the document
+	content, err := fileReader.Read(ctx, absPath)
+	if err != nil {
+		return nil, JSONGetOutput{}, err
+	}
+	doc, err := parseFileDocument(absPath, content)
+	if err != nil {
+		return nil, JSONGetOutput{}, err
+	}
+
+	value, err := ptr.Get(doc)
+	if err != nil {
+		return nil, JSONGetOutput{}, err
+	}
+
+	output := JSONGetOutput{
+		Path:    absPath,
+		Pointer: ptr.String(),
+		Type:    jsonTypeName(value),
+		Value:   value,
+	}
+
+	// Describe the shape of containers
+	switch v := value.(type) {
+	case []any:
+		n := len(v)
+		output.Length = &n
+	case map[string]any:
+		n := len(v)
+		output.Length = &n
+		output.Keys = make([]string, 0, n)
+		for k := range v {
+			output.Keys = append(output.Keys, k)
+		}
+		sort.Strings(output.Keys)
+	}
+
+	// Serialize the subtree for the MCP response
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return nil, JSONGetOutput{}, fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(valueJSON)},
+		},
+	}
+
+	return result, output, nil
+}
+
+// jsonTypeName returns the JSON type name of a decoded value
+func jsonTypeName(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	default:
+		return "number"
+	}
+}
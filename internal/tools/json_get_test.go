@@ -0,0 +1,158 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tools"
+)
+
+func TestJSONGetHandler(t *testing.T) {
+	const docJSON = `{"data": {"items": [{"name": "first", "tags": []}, {"name": "second", "count": 3}]}, "a/b": true}`
+
+	tests := []struct {
+		name       string
+		pointer    string
+		wantErr    error
+		wantType   string
+		wantText   string
+		wantLength *int
+		wantKeys   []string
+	}{
+		{
+			name:       "whole document",
+			pointer:    "",
+			wantType:   "object",
+			wantText:   `{"a/b":true,"data":{"items":[{"name":"first","tags":[]},{"count":3,"name":"second"}]}}`,
+			wantLength: intPtr(2),
+			wantKeys:   []string{"a/b", "data"},
+		},
+		{
+			name:       "array",
+			pointer:    "/data/items",
+			wantType:   "array",
+			wantText:   `[{"name":"first","tags":[]},{"count":3,"name":"second"}]`,
+			wantLength: intPtr(2),
+		},
+		{
+			name:       "array element",
+			pointer:    "/data/items/1",
+			wantType:   "object",
+			wantText:   `{"count":3,"name":"second"}`,
+			wantLength: intPtr(2),
+			wantKeys:   []string{"count", "name"},
+		},
+		{
+			name:     "string inside array element",
+			pointer:  "/data/items/0/name",
+			wantType: "string",
+			wantText: `"first"`,
+		},
+		{
+			name:     "number",
+			pointer:  "/data/items/1/count",
+			wantType: "number",
+			wantText: `3`,
+		},
+		{
+			name:     "escaped key",
+			pointer:  "/a~1b",
+			wantType: "boolean",
+			wantText: `true`,
+		},
+		{
+			name:    "index out of range",
+			pointer: "/data/items/2",
+			wantErr: domain.ErrPointerNotFound,
+		},
+		{
+			name:    "missing member",
+			pointer: "/data/missing",
+			wantErr: domain.ErrPointerNotFound,
+		},
+		{
+			name:    "invalid pointer",
+			pointer: "data",
+			wantErr: domain.ErrInvalidPointer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memReader := reader.NewInMemoryFileReader()
+			memReader.Files = map[string]string{"/tmp/doc.json": docJSON}
+			tools.SetFileReader(memReader)
+
+			result, output, err := tools.JSONGetHandler(
+				context.Background(),
+				&mcp.CallToolRequest{},
+				tools.JSONGetArgs{Path: "/tmp/doc.json", Pointer: tt.pointer},
+			)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("JSONGetHandler() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("JSONGetHandler() unexpected error = %v", err)
+				return
+			}
+
+			if output.Type != tt.wantType {
+				t.Errorf("JSONGetHandler() type = %v, want %v", output.Type, tt.wantType)
+			}
+
+			if !reflect.DeepEqual(output.Length, tt.wantLength) {
+				t.Errorf("JSONGetHandler() length = %v, want %v", output.Length, tt.wantLength)
+			}
+
+			if !reflect.DeepEqual(output.Keys, tt.wantKeys) {
+				t.Errorf("JSONGetHandler() keys = %v, want %v", output.Keys, tt.wantKeys)
+			}
+
+			text, ok := result.Content[0].(*mcp.TextContent)
+			if !ok {
+				t.Fatal("JSONGetHandler() result content is not TextContent")
+			}
+			if text.Text != tt.wantText {
+				t.Errorf("JSONGetHandler() text = %v, want %v", text.Text, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestJSONGetHandler_TOML(t *testing.T) {
+	memReader := reader.NewInMemoryFileReader()
+	memReader.Files = map[string]string{"/tmp/pyproject.toml": `[project]
+name = "app"
+released = 2024-05-01
+
+[[project.authors]]
+name = "Ada"
+`}
+	tools.SetFileReader(memReader)
+
+	result, output, err := tools.JSONGetHandler(
+		context.Background(),
+		&mcp.CallToolRequest{},
+		tools.JSONGetArgs{Path: "/tmp/pyproject.toml", Pointer: "/project"},
+	)
+	if err != nil {
+		t.Fatalf("JSONGetHandler() unexpected error = %v", err)
+	}
+	if output.Type != "object" {
+		t.Errorf("JSONGetHandler() type = %v, want object", output.Type)
+	}
+	want := `{"authors":[{"name":"Ada"}],"name":"app","released":"2024-05-01"}`
+	if text := result.Content[0].(*mcp.TextContent).Text; text != want {
+		t.Errorf("JSONGetHandler() text = %v, want %v", text, want)
+	}
+}
@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpatch"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpointer"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
+)
+
+// JSONMergePatchTool defines the json_merge_patch tool metadata
+var JSONMergePatchTool = &mcp.Tool{
+	Name:        "json_merge_patch",
+	Description: "Apply an RFC 7396 merge patch to a JSON file (or a subtree of it) with atomic writes. Objects merge recursively, null deletes a key and other values replace",
+}
+
+// JSONMergePatchArgs defines the input parameters for the json_merge_patch tool
+type JSONMergePatchArgs struct {
+	Path    string `json:"path" jsonschema:"Absolute or relative path to the JSON file to patch"`
+	Patch   any    `json:"patch" jsonschema:"Merge patch document (e.g., {\"settings\": {\"theme\": \"dark\", \"legacy\": null}})"`
+	Pointer string `json:"pointer,omitempty" jsonschema:"Optional JSON Pointer to the subtree the patch applies to (e.g., '/settings'). Defaults to the whole document"`
+}
+
+// JSONMergePatchOutput defines the output structure for the json_merge_patch tool
+type JSONMergePatchOutput struct {
+	Path    string             `json:"path"`
+	Size    int64              `json:"size"`
+	Changes []jsonpatch.Change `json:"changes"`
+	Secrets []string           `json:"secrets,omitempty"`
+}
+
+// JSONMergePatchHandler handles the json_merge_patch tool invocation
+func JSONMergePatchHandler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args JSONMergePatchArgs,
+) (*mcp.CallToolResult, JSONMergePatchOutput, error) {
+	// Resolve path (validates and converts to absolute)
+	absPath, err := pathutil.Resolve(args.Path)
+	if err != nil {
+		return nil, JSONMergePatchOutput{}, err
+	}
+	if err := authorize(JSONMergePatchTool, policy.Write, absPath); err != nil {
+		return nil, JSONMergePatchOutput{}, err
+	}
+
+	ptr, err := jsonpointer.Parse(args.Pointer)
+	if err != nil {
+		return nil, JSONMergePatchOutput{}, err
+	}
+
+	slog.Info("json_merge_patch tool called",
+		slog.String("path", absPath),
+		slog.String("pointer", args.Pointer),
+	)
+
+	// Hold the file lock across the read-modify-write cycle
+	unlock := lockPath(absPath)
+	defer unlock()
+
+	// Read and parse the current document
+	content, err := fileReader.Read(ctx, absPath)
+	if err != nil {
+		return nil, JSONMergePatchOutput{}, err
+	}
+	doc, err := parseFileDocument(absPath, content)
+	if err != nil {
+		return nil, JSONMergePatchOutput{}, err
+	}
+
+	// Locate the subtree; a missing final member is created by the merge
+	target, err := ptr.Get(doc)
+	exists := err == nil
+	if err != nil && !errors.Is(err, domain.ErrPointerNotFound) {
+		return nil, JSONMergePatchOutput{}, err
+	}
+
+	merged, changes := jsonpatch.MergePatch(target, args.Patch, ptr)
+	switch {
+	case !exists:
+		changes = []jsonpatch.Change{{Path: ptr.String(), Kind: jsonpatch.ChangeAdded}}
+	case len(changes) == 0 && !jsonpatch.Equal(target, merged):
+		// A non-object patch replaced the whole subtree
+		changes = []jsonpatch.Change{{Path: ptr.String(), Kind: jsonpatch.ChangeUpdated}}
+	}
+
+	output := JSONMergePatchOutput{
+		Path:    absPath,
+		Size:    int64(len(content)),
+		Changes: changes,
+	}
+
+	// Nothing to write if the patch did not change anything
+	if len(changes) == 0 {
+		result := &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Merge patch made no changes to %s", absPath)},
+			},
+		}
+		return result, output, nil
+	}
+
+	// Put the merged subtree back in place
+	op := jsonpatch.Operation{Op: "replace", Path: ptr.String(), Value: merged}
+	if !exists {
+		op.Op = "add"
+	}
+	patched, err := jsonpatch.Apply(doc, []jsonpatch.Operation{op})
+	if err != nil {
+		return nil, JSONMergePatchOutput{}, err
+	}
+
+	updated, err := encodeForWrite(ctx, JSONMergePatchTool, absPath, patched, content)
+	if err != nil {
+		return nil, JSONMergePatchOutput{}, err
+	}
+
+	updated, notes, err := guardSecrets(JSONMergePatchTool, absPath, content, updated)
+	if err != nil {
+		return nil, JSONMergePatchOutput{}, err
+	}
+	size, err := fileWriter.Write(ctx, absPath, updated)
+	if err != nil {
+		return nil, JSONMergePatchOutput{}, err
+	}
+	output.Size = size
+	output.Secrets = notes
+
+	message := fmt.Sprintf("Successfully merged patch into %s: %s (%d bytes)", absPath, summarizeChanges(changes), size)
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: withSecretNotes(message, notes)},
+		},
+	}
+
+	return result, output, nil
+}
+
+// summarizeChanges renders a short count of added, updated and removed members.
+func summarizeChanges(changes []jsonpatch.Change) string {
+	counts := map[string]int{}
+	for _, c := range changes {
+		counts[c.Kind]++
+	}
+	return fmt.Sprintf("%d added, %d updated, %d removed",
+		counts[jsonpatch.ChangeAdded], counts[jsonpatch.ChangeUpdated], counts[jsonpatch.ChangeRemoved])
+}
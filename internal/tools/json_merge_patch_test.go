@@ -0,0 +1,163 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpatch"
+	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tools"
+	"github.com/robertbagge/markdown-writer-mcp/internal/writer"
+)
+
+func TestJSONMergePatchHandler(t *testing.T) {
+	const settingsJSON = `{"editor": {"theme": "light", "fontSize": 12}, "legacy": true}`
+
+	tests := []struct {
+		name        string
+		files       map[string]string
+		args        tools.JSONMergePatchArgs
+		wantErr     error
+		wantContent string // empty means nothing should be written
+		wantChanges []jsonpatch.Change
+	}{
+		{
+			name:  "merge whole document",
+			files: map[string]string{"/tmp/settings.json": settingsJSON},
+			args: tools.JSONMergePatchArgs{
+				Path: "/tmp/settings.json",
+				Patch: map[string]any{
+					"editor": map[string]any{"theme": "dark"},
+					"legacy": nil,
+				},
+			},
+			wantContent: `{"editor":{"theme":"dark","fontSize":12}}`,
+			wantChanges: []jsonpatch.Change{
+				{Path: "/editor/theme", Kind: jsonpatch.ChangeUpdated},
+				{Path: "/legacy", Kind: jsonpatch.ChangeRemoved},
+			},
+		},
+		{
+			name:  "merge subtree via pointer",
+			files: map[string]string{"/tmp/settings.json": settingsJSON},
+			args: tools.JSONMergePatchArgs{
+				Path:    "/tmp/settings.json",
+				Pointer: "/editor",
+				Patch:   map[string]any{"fontSize": 14, "wordWrap": "on"},
+			},
+			wantContent: `{"editor":{"theme":"light","fontSize":14,"wordWrap":"on"},"legacy":true}`,
+			wantChanges: []jsonpatch.Change{
+				{Path: "/editor/fontSize", Kind: jsonpatch.ChangeUpdated},
+				{Path: "/editor/wordWrap", Kind: jsonpatch.ChangeAdded},
+			},
+		},
+		{
+			name:  "pointer to missing member creates it",
+			files: map[string]string{"/tmp/settings.json": settingsJSON},
+			args: tools.JSONMergePatchArgs{
+				Path:    "/tmp/settings.json",
+				Pointer: "/terminal",
+				Patch:   map[string]any{"shell": "zsh"},
+			},
+			wantContent: `{"editor":{"theme":"light","fontSize":12},"legacy":true,"terminal":{"shell":"zsh"}}`,
+			wantChanges: []jsonpatch.Change{
+				{Path: "/terminal", Kind: jsonpatch.ChangeAdded},
+			},
+		},
+		{
+			name:  "no-op patch does not write",
+			files: map[string]string{"/tmp/settings.json": settingsJSON},
+			args: tools.JSONMergePatchArgs{
+				Path:  "/tmp/settings.json",
+				Patch: map[string]any{"legacy": true, "missing": nil},
+			},
+			wantChanges: []jsonpatch.Change{},
+		},
+		{
+			name:  "pointer with missing parent",
+			files: map[string]string{"/tmp/settings.json": settingsJSON},
+			args: tools.JSONMergePatchArgs{
+				Path:    "/tmp/settings.json",
+				Pointer: "/a/b",
+				Patch:   map[string]any{"x": 1},
+			},
+			wantErr: domain.ErrPointerNotFound,
+		},
+		{
+			name:  "invalid pointer",
+			files: map[string]string{"/tmp/settings.json": settingsJSON},
+			args: tools.JSONMergePatchArgs{
+				Path:    "/tmp/settings.json",
+				Pointer: "editor",
+				Patch:   map[string]any{"x": 1},
+			},
+			wantErr: domain.ErrInvalidPointer,
+		},
+		{
+			name:  "file not found",
+			files: map[string]string{},
+			args: tools.JSONMergePatchArgs{
+				Path:  "/tmp/missing.json",
+				Patch: map[string]any{"x": 1},
+			},
+			wantErr: domain.ErrFileNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memReader := reader.NewInMemoryFileReader()
+			memReader.Files = tt.files
+			tools.SetFileReader(memReader)
+			memWriter := writer.NewInMemoryFileWriter()
+			tools.SetFileWriter(memWriter)
+
+			result, output, err := tools.JSONMergePatchHandler(
+				context.Background(),
+				&mcp.CallToolRequest{},
+				tt.args,
+			)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("JSONMergePatchHandler() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("JSONMergePatchHandler() unexpected error = %v", err)
+				return
+			}
+
+			if result == nil {
+				t.Error("JSONMergePatchHandler() result is nil")
+				return
+			}
+
+			if !reflect.DeepEqual(output.Changes, tt.wantChanges) {
+				t.Errorf("JSONMergePatchHandler() changes = %v, want %v", output.Changes, tt.wantChanges)
+			}
+
+			got, written := memWriter.Files[output.Path]
+			if tt.wantContent == "" {
+				if written {
+					t.Errorf("JSONMergePatchHandler() wrote %q, want no write", got)
+				}
+				return
+			}
+
+			if got != tt.wantContent {
+				t.Errorf("JSONMergePatchHandler() written content = %q, want %q", got, tt.wantContent)
+			}
+
+			if output.Size != int64(len(tt.wantContent)) {
+				t.Errorf("JSONMergePatchHandler() size = %v, want %v", output.Size, len(tt.wantContent))
+			}
+		})
+	}
+}
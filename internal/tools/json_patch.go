@@ -0,0 +1,147 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpatch"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
+)
+
+// JSONPatchTool defines the json_patch tool metadata
+var JSONPatchTool = &mcp.Tool{
+	Name:        "json_patch",
+	Description: "Apply an RFC 6902 JSON Patch (add, remove, replace, move, copy, test) to a JSON, YAML or TOML file with atomic writes. If any operation fails the file is left untouched. Comments in JSONC and YAML files are kept",
+}
+
+// PatchOperation defines a single RFC 6902 patch operation
+type PatchOperation struct {
+	Op    string `json:"op" jsonschema:"Operation: add, remove, replace, move, copy, test"`
+	Path  string `json:"path" jsonschema:"JSON Pointer to the target location (e.g., '/data/items/0/name')"`
+	From  string `json:"from,omitempty" jsonschema:"JSON Pointer to the source location (required for move/copy)"`
+	Value any    `json:"value,omitempty" jsonschema:"Value to add, replace or test against (required for add/replace/test)"`
+
+	// hasValue records that the decoded operation named a value, even null
+	hasValue bool
+}
+
+// UnmarshalJSON decodes an operation and records whether it has a value,
+// so that a missing value is told apart from null.
+func (op *PatchOperation) UnmarshalJSON(data []byte) error {
+	type plain PatchOperation
+	if err := json.Unmarshal(data, (*plain)(op)); err != nil {
+		return err
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	_, op.hasValue = members["value"]
+	return nil
+}
+
+// JSONPatchArgs defines the input parameters for the json_patch tool
+type JSONPatchArgs struct {
+	Path  string           `json:"path" jsonschema:"Absolute or relative path to the JSON file to patch"`
+	Patch []PatchOperation `json:"patch" jsonschema:"Ordered list of RFC 6902 patch operations"`
+}
+
+// JSONPatchOutput defines the output structure for the json_patch tool
+type JSONPatchOutput struct {
+	Path    string   `json:"path"`
+	Size    int64    `json:"size"`
+	Applied int      `json:"applied"`
+	Secrets []string `json:"secrets,omitempty"`
+}
+
+// JSONPatchHandler handles the json_patch tool invocation
+func JSONPatchHandler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args JSONPatchArgs,
+) (*mcp.CallToolResult, JSONPatchOutput, error) {
+	// Resolve path (validates and converts to absolute)
+	absPath, err := pathutil.Resolve(args.Path)
+	if err != nil {
+		return nil, JSONPatchOutput{}, err
+	}
+	if err := authorize(JSONPatchTool, policy.Write, absPath); err != nil {
+		return nil, JSONPatchOutput{}, err
+	}
+
+	if len(args.Patch) == 0 {
+		return nil, JSONPatchOutput{}, fmt.Errorf("%w: patch must contain at least one operation", domain.ErrInvalidPatch)
+	}
+	for i, op := range args.Patch {
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil && !op.hasValue {
+				return nil, JSONPatchOutput{}, fmt.Errorf("%w: operation %d (%s %s) has no value", domain.ErrInvalidPatch, i, op.Op, op.Path)
+			}
+		}
+	}
+
+	slog.Info("json_patch tool called",
+		slog.String("path", absPath),
+		slog.Int("operationCount", len(args.Patch)),
+	)
+
+	// Hold the file lock across the read-modify-write cycle
+	unlock := lockPath(absPath)
+	defer unlock()
+
+	// Read and parse the current document
+	content, err := fileReader.Read(ctx, absPath)
+	if err != nil {
+		return nil, JSONPatchOutput{}, err
+	}
+	doc, err := parseFileDocument(absPath, content)
+	if err != nil {
+		return nil, JSONPatchOutput{}, err
+	}
+
+	// Apply all operations; nothing is written unless every operation succeeds
+	ops := make([]jsonpatch.Operation, len(args.Patch))
+	for i, op := range args.Patch {
+		ops[i] = jsonpatch.Operation{Op: op.Op, Path: op.Path, From: op.From, Value: op.Value}
+	}
+	patched, err := jsonpatch.Apply(doc, ops)
+	if err != nil {
+		return nil, JSONPatchOutput{}, err
+	}
+
+	updated, err := encodeForWrite(ctx, JSONPatchTool, absPath, patched, content)
+	if err != nil {
+		return nil, JSONPatchOutput{}, err
+	}
+
+	updated, notes, err := guardSecrets(JSONPatchTool, absPath, content, updated)
+	if err != nil {
+		return nil, JSONPatchOutput{}, err
+	}
+	size, err := fileWriter.Write(ctx, absPath, updated)
+	if err != nil {
+		return nil, JSONPatchOutput{}, err
+	}
+
+	output := JSONPatchOutput{
+		Path:    absPath,
+		Size:    size,
+		Applied: len(ops),
+		Secrets: notes,
+	}
+
+	message := fmt.Sprintf("Successfully applied %d patch operations to %s (%d bytes)", len(ops), absPath, size)
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: withSecretNotes(message, notes)},
+		},
+	}
+
+	return result, output, nil
+}
@@ -0,0 +1,235 @@
+package tools_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tools"
+	"github.com/robertbagge/markdown-writer-mcp/internal/writer"
+)
+
+func TestJSONPatchHandler(t *testing.T) {
+	const configJSON = `{"name": "app", "version": 9007199254740993, "tags": ["a", "b"]}`
+
+	tests := []struct {
+		name        string
+		files       map[string]string
+		args        tools.JSONPatchArgs
+		wantErr     error
+		wantContent string
+	}{
+		{
+			name:  "replace and add",
+			files: map[string]string{"/tmp/config.json": configJSON},
+			args: tools.JSONPatchArgs{
+				Path: "/tmp/config.json",
+				Patch: []tools.PatchOperation{
+					{Op: "test", Path: "/name", Value: "app"},
+					{Op: "replace", Path: "/name", Value: "service"},
+					{Op: "add", Path: "/tags/-", Value: "c"},
+				},
+			},
+			wantContent: `{"name":"service","version":9007199254740993,"tags":["a","b","c"]}`,
+		},
+		{
+			name:  "JSONC file keeps comments",
+			files: map[string]string{"/tmp/tsconfig.json": "{\n  // Build settings\n  \"compilerOptions\": {\n    \"target\": \"es2020\", // language level\n    \"strict\": true,\n  },\n}\n"},
+			args: tools.JSONPatchArgs{
+				Path: "/tmp/tsconfig.json",
+				Patch: []tools.PatchOperation{
+					{Op: "replace", Path: "/compilerOptions/target", Value: "es2022"},
+					{Op: "add", Path: "/compilerOptions/outDir", Value: "dist"},
+				},
+			},
+			wantContent: "{\n  // Build settings\n  \"compilerOptions\": {\n    \"target\": \"es2022\", // language level\n    \"strict\": true,\n    \"outDir\": \"dist\",\n  },\n}\n",
+		},
+		{
+			name:  "YAML file keeps comments",
+			files: map[string]string{"/tmp/compose.yaml": "# Local stack\nservices:\n  web:\n    image: nginx:1.25 # pinned\n    ports:\n      - \"8080:80\"\n"},
+			args: tools.JSONPatchArgs{
+				Path: "/tmp/compose.yaml",
+				Patch: []tools.PatchOperation{
+					{Op: "replace", Path: "/services/web/image", Value: "nginx:1.27"},
+					{Op: "add", Path: "/services/web/ports/-", Value: "2222:22"},
+				},
+			},
+			wantContent: "# Local stack\nservices:\n  web:\n    image: nginx:1.27 # pinned\n    ports:\n      - \"8080:80\"\n      - \"2222:22\"\n",
+		},
+		{
+			name:  "TOML file",
+			files: map[string]string{"/tmp/app.toml": "# settings\n[server]\nport = 8080\n"},
+			args: tools.JSONPatchArgs{
+				Path: "/tmp/app.toml",
+				Patch: []tools.PatchOperation{
+					{Op: "add", Path: "/server/host", Value: "localhost"},
+				},
+			},
+			wantContent: "[server]\nhost = \"localhost\"\nport = 8080\n",
+		},
+		{
+			name:  "TOML cannot hold null",
+			files: map[string]string{"/tmp/app.toml": "[server]\nport = 8080\n"},
+			args: tools.JSONPatchArgs{
+				Path:  "/tmp/app.toml",
+				Patch: decodePatch(`[{"op": "replace", "path": "/server/port", "value": null}]`),
+			},
+			wantErr: domain.ErrInvalidTOML,
+		},
+		{
+			name:  "null value",
+			files: map[string]string{"/tmp/config.json": configJSON},
+			args: tools.JSONPatchArgs{
+				Path:  "/tmp/config.json",
+				Patch: decodePatch(`[{"op": "add", "path": "/owner", "value": null}]`),
+			},
+			wantContent: `{"name":"app","version":9007199254740993,"tags":["a","b"],"owner":null}`,
+		},
+		{
+			name:  "add without value",
+			files: map[string]string{"/tmp/config.json": configJSON},
+			args: tools.JSONPatchArgs{
+				Path:  "/tmp/config.json",
+				Patch: decodePatch(`[{"op": "add", "path": "/owner"}]`),
+			},
+			wantErr: domain.ErrInvalidPatch,
+		},
+		{
+			name:  "replace without value",
+			files: map[string]string{"/tmp/config.json": configJSON},
+			args: tools.JSONPatchArgs{
+				Path:  "/tmp/config.json",
+				Patch: decodePatch(`[{"op": "replace", "path": "/name"}]`),
+			},
+			wantErr: domain.ErrInvalidPatch,
+		},
+		{
+			name:  "test without value",
+			files: map[string]string{"/tmp/config.json": configJSON},
+			args: tools.JSONPatchArgs{
+				Path:  "/tmp/config.json",
+				Patch: decodePatch(`[{"op": "test", "path": "/name"}]`),
+			},
+			wantErr: domain.ErrInvalidPatch,
+		},
+		{
+			name:  "failed test leaves file untouched",
+			files: map[string]string{"/tmp/config.json": configJSON},
+			args: tools.JSONPatchArgs{
+				Path: "/tmp/config.json",
+				Patch: []tools.PatchOperation{
+					{Op: "replace", Path: "/name", Value: "service"},
+					{Op: "test", Path: "/name", Value: "app"},
+				},
+			},
+			wantErr: domain.ErrPatchTestFailed,
+		},
+		{
+			name:  "invalid pointer leaves file untouched",
+			files: map[string]string{"/tmp/config.json": configJSON},
+			args: tools.JSONPatchArgs{
+				Path: "/tmp/config.json",
+				Patch: []tools.PatchOperation{
+					{Op: "remove", Path: "/tags/5"},
+				},
+			},
+			wantErr: domain.ErrPointerNotFound,
+		},
+		{
+			name:  "empty patch",
+			files: map[string]string{"/tmp/config.json": configJSON},
+			args: tools.JSONPatchArgs{
+				Path: "/tmp/config.json",
+			},
+			wantErr: domain.ErrInvalidPatch,
+		},
+		{
+			name:  "invalid JSON in file",
+			files: map[string]string{"/tmp/broken.json": `{"name": `},
+			args: tools.JSONPatchArgs{
+				Path:  "/tmp/broken.json",
+				Patch: []tools.PatchOperation{{Op: "remove", Path: "/name"}},
+			},
+			wantErr: domain.ErrInvalidJSON,
+		},
+		{
+			name:  "file not found",
+			files: map[string]string{},
+			args: tools.JSONPatchArgs{
+				Path:  "/tmp/missing.json",
+				Patch: []tools.PatchOperation{{Op: "remove", Path: "/name"}},
+			},
+			wantErr: domain.ErrFileNotFound,
+		},
+		{
+			name:  "path traversal attempt",
+			files: map[string]string{},
+			args: tools.JSONPatchArgs{
+				Path:  "/tmp/../etc/passwd",
+				Patch: []tools.PatchOperation{{Op: "remove", Path: "/name"}},
+			},
+			wantErr: domain.ErrPathTraversal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memReader := reader.NewInMemoryFileReader()
+			memReader.Files = tt.files
+			tools.SetFileReader(memReader)
+			memWriter := writer.NewInMemoryFileWriter()
+			tools.SetFileWriter(memWriter)
+
+			result, output, err := tools.JSONPatchHandler(
+				context.Background(),
+				&mcp.CallToolRequest{},
+				tt.args,
+			)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("JSONPatchHandler() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if len(memWriter.Files) != 0 {
+					t.Errorf("JSONPatchHandler() wrote files on error: %v", memWriter.Files)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("JSONPatchHandler() unexpected error = %v", err)
+				return
+			}
+
+			if result == nil {
+				t.Error("JSONPatchHandler() result is nil")
+				return
+			}
+
+			if output.Applied != len(tt.args.Patch) {
+				t.Errorf("JSONPatchHandler() applied = %v, want %v", output.Applied, len(tt.args.Patch))
+			}
+
+			if got := memWriter.Files[output.Path]; got != tt.wantContent {
+				t.Errorf("JSONPatchHandler() written content = %q, want %q", got, tt.wantContent)
+			}
+
+			if output.Size != int64(len(tt.wantContent)) {
+				t.Errorf("JSONPatchHandler() size = %v, want %v", output.Size, len(tt.wantContent))
+			}
+		})
+	}
+}
+
+// decodePatch decodes patch operations as a client sends them.
+func decodePatch(patch string) []tools.PatchOperation {
+	var ops []tools.PatchOperation
+	if err := json.Unmarshal([]byte(patch), &ops); err != nil {
+		panic(err)
+	}
+	return ops
+}
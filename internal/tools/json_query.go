@@ -5,37 +5,97 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"reflect"
 
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonc"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpatch"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpointer"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonstream"
 	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
+	"github.com/robertbagge/markdown-writer-mcp/internal/queryexpr"
 )
 
 // JSONQueryTool defines the json_query tool metadata
 var JSONQueryTool = &mcp.Tool{
 	Name:        "json_query",
-	Description: "Query JSON arrays with filtering, supports nested paths and multiple filter operations",
+	Description: "Query JSON arrays with filtering, supports nested paths and multiple filter operations. Arrays of strings, numbers or arrays are filtered with the field $, and each result's array index is reported. Alternatively, expr takes a JMESPath expression for filtering, projection, sorting and slicing. JSONC and JSON5 files (comments, trailing commas) are accepted, as are YAML (.yaml, .yml) and TOML (.toml) files",
+	InputSchema: inputSchemaFor[JSONQueryArgs](),
+}
+
+// ArrayPath locates an array inside a JSON document. It accepts either a
+// list of keys (e.g., ["data", "items"]) or an RFC 6901 JSON Pointer string
+// (e.g., "/data/items"). Numeric segments step into array elements.
+type ArrayPath []string
+
+// UnmarshalJSON accepts both the list and the JSON Pointer forms.
+func (p *ArrayPath) UnmarshalJSON(data []byte) error {
+	var pointer string
+	if err := json.Unmarshal(data, &pointer); err == nil {
+		tokens, err := jsonpointer.Parse(pointer)
+		if err != nil {
+			return err
+		}
+		*p = ArrayPath(tokens)
+		return nil
+	}
+
+	var keys []string
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return fmt.Errorf("arrayPath must be a list of keys or a JSON Pointer string: %w", err)
+	}
+	*p = keys
+	return nil
+}
+
+// arrayPathSchema describes ArrayPath's two accepted forms
+var arrayPathSchema = &jsonschema.Schema{
+	OneOf: []*jsonschema.Schema{
+		{Type: "array", Items: &jsonschema.Schema{Type: "string"}},
+		{Type: "string"},
+	},
+}
+
+// inputSchemaFor infers a tool input schema, substituting the schemas of
+// argument types whose JSON form differs from their Go type
+func inputSchemaFor[T any]() *jsonschema.Schema {
+	schema, err := jsonschema.For[T](&jsonschema.ForOptions{
+		TypeSchemas: map[reflect.Type]*jsonschema.Schema{
+			reflect.TypeFor[ArrayPath](): arrayPathSchema,
+		},
+	})
+	if err != nil {
+		panic(fmt.Sprintf("infer input schema for %T: %v", *new(T), err))
+	}
+	return schema
 }
 
 // Filter defines a single filter condition
 type Filter struct {
-	Field string `json:"field" jsonschema:"Dot-notation path to field (e.g., 'regions', 'type')"`
+	Field string `json:"field" jsonschema:"Dot-notation path to field (e.g., 'regions', 'type'), or $ for the element itself in arrays of strings, numbers or arrays"`
 	Op    string `json:"op" jsonschema:"Operation: eq, neq, contains, is_null, is_not_null"`
 	Value any    `json:"value,omitempty" jsonschema:"Value to compare (required for eq/neq/contains)"`
 }
 
 // JSONQueryArgs defines the input parameters for the json_query tool
 type JSONQueryArgs struct {
-	Path      string   `json:"path" jsonschema:"Absolute or relative path to the JSON file"`
-	ArrayPath []string `json:"arrayPath,omitempty" jsonschema:"Path to array in JSON structure (e.g., [\"data\", \"items\"])"`
-	Filters   []Filter `json:"filters,omitempty" jsonschema:"Array of filter conditions (AND logic)"`
-	Limit     *int     `json:"limit,omitempty" jsonschema:"Maximum number of results to return"`
+	Path      string    `json:"path" jsonschema:"Absolute or relative path to the JSON file"`
+	ArrayPath ArrayPath `json:"arrayPath,omitempty" jsonschema:"Path to array in JSON structure, as a list of keys (e.g., [\"data\", \"items\"]) or a JSON Pointer (e.g., \"/data/items\")"`
+	Filters   []Filter  `json:"filters,omitempty" jsonschema:"Array of filter conditions (AND logic)"`
+	Expr      string    `json:"expr,omitempty" jsonschema:"JMESPath expression evaluated against the array (or the value at arrayPath), as an alternative to filters, e.g. [?type == 'vc'] | sort_by(@, &name)[:10].{id: id, name: name}"`
+	Limit     *int      `json:"limit,omitempty" jsonschema:"Maximum number of results to return"`
 }
 
-// JSONQueryOutput defines the output structure for the json_query tool
+// JSONQueryOutput defines the output structure for the json_query tool.
+// Indices holds the position of each result in the queried array; results
+// of an expression have none.
 type JSONQueryOutput struct {
-	Result []any `json:"result"`
-	Count  int   `json:"count"`
+	Result  []any `json:"result"`
+	Indices []int `json:"indices,omitempty"`
+	Count   int   `json:"count"`
 }
 
 // JSONQueryHandler handles the json_query tool invocation
@@ -49,97 +109,277 @@ func JSONQueryHandler(
 	if err != nil {
 		return nil, JSONQueryOutput{}, err
 	}
+	if err := authorize(JSONQueryTool, policy.Read, absPath); err != nil {
+		return nil, JSONQueryOutput{}, err
+	}
+
+	// Compile the expression before touching the file
+	var expr *queryexpr.Expr
+	if args.Expr != "" {
+		if len(args.Filters) > 0 {
+			return nil, JSONQueryOutput{}, fmt.Errorf("%w: use either filters or expr, not both", domain.ErrInvalidFilter)
+		}
+		if expr, err = queryexpr.Compile(args.Expr); err != nil {
+			return nil, JSONQueryOutput{}, err
+		}
+	}
 
 	slog.Info("json_query tool called",
 		slog.String("path", absPath),
 		slog.Any("arrayPath", args.ArrayPath),
 		slog.Int("filterCount", len(args.Filters)),
+		slog.String("expr", args.Expr),
 	)
 
-	// Read file using injected reader
-	content, err := fileReader.Read(ctx, absPath)
+	var results []any
+	var indices []int
+	if expr != nil {
+		results, err = queryExpr(ctx, absPath, args.ArrayPath, expr, args.Limit)
+	} else {
+		results, indices, err = queryArray(ctx, absPath, args.ArrayPath, args.Filters, args.Limit, maxQueryResultBytes)
+	}
 	if err != nil {
 		return nil, JSONQueryOutput{}, err
 	}
 
-	// Parse JSON
-	var data any
-	if err := json.Unmarshal([]byte(content), &data); err != nil {
-		return nil, JSONQueryOutput{}, fmt.Errorf("%w: %v", domain.ErrInvalidJSON, err)
+	output := JSONQueryOutput{
+		Result:  results,
+		Indices: indices,
+		Count:   len(results),
 	}
 
-	// Navigate to arrayPath if provided
-	target := data
-	if len(args.ArrayPath) > 0 {
-		target, err = navigateToPath(data, args.ArrayPath)
-		if err != nil {
-			return nil, JSONQueryOutput{}, err
-		}
+	// Serialize output to JSON for MCP response
+	outputJSON, err := json.Marshal(output)
+	if err != nil {
+		return nil, JSONQueryOutput{}, fmt.Errorf("failed to marshal output: %w", err)
 	}
 
-	// Verify target is an array
-	arr, ok := target.([]any)
-	if !ok {
-		return nil, JSONQueryOutput{}, domain.ErrNotAnArray
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(outputJSON)},
+		},
 	}
 
-	// Apply filters
-	var results []any
-	for _, item := range arr {
-		itemMap, ok := item.(map[string]any)
-		if !ok {
-			continue // Skip non-object items
+	return result, output, nil
+}
+
+// queryArray returns the elements of the array at arrayPath that match all
+// filters, up to limit, with their indices in the array. maxBytes caps
+// their combined size; zero means no cap.
+func queryArray(ctx context.Context, path string, arrayPath []string, filters []Filter, limit *int, maxBytes int64) ([]any, []int, error) {
+	results := []any{}
+	indices := []int{}
+	var resultBytes int64
+	collect := func(index int, item any, size int64) (bool, error) {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		if !matchesElement(item, filters) {
+			return true, nil // Skip non-matching items
 		}
 
-		if matchesAllFilters(itemMap, args.Filters) {
-			results = append(results, item)
+		resultBytes += size
+		if err := checkResultBytes(resultBytes, maxBytes); err != nil {
+			return false, err
 		}
+		results = append(results, item)
+		indices = append(indices, index)
+
+		// Stop as soon as the limit is reached, without reading further
+		return limit == nil || *limit <= 0 || len(results) < *limit, nil
 	}
 
-	// Apply limit if specified
-	if args.Limit != nil && *args.Limit > 0 && len(results) > *args.Limit {
-		results = results[:*args.Limit]
+	var err error
+	switch documentFormat(path) {
+	case formatYAML, formatTOML:
+		err = forEachDocumentElement(ctx, path, arrayPath, collect)
+	default:
+		err = forEachStreamElement(ctx, path, arrayPath, collect)
+	}
+	if err != nil {
+		return nil, nil, err
 	}
+	return results, indices, nil
+}
 
-	output := JSONQueryOutput{
-		Result: results,
-		Count:  len(results),
+// queryExpr evaluates expr against the value at arrayPath. An array result
+// yields its elements, null yields nothing and any other value a single
+// result. The whole file is decoded, since expressions may sort or
+// aggregate.
+func queryExpr(ctx context.Context, path string, arrayPath []string, expr *queryexpr.Expr, limit *int) ([]any, error) {
+	doc, err := readStreamDocument(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	target, err := navigateToPath(doc, arrayPath)
+	if err != nil {
+		return nil, err
+	}
+	value, err := expr.Search(target)
+	if err != nil {
+		return nil, err
 	}
 
-	// Serialize output to JSON for MCP response
-	outputJSON, err := json.Marshal(output)
+	results := []any{}
+	switch val := value.(type) {
+	case nil:
+	case []any:
+		results = val
+	default:
+		results = append(results, val)
+	}
+	if limit != nil && *limit > 0 && len(results) > *limit {
+		results = results[:*limit]
+	}
+
+	raw, err := json.Marshal(results)
 	if err != nil {
-		return nil, JSONQueryOutput{}, fmt.Errorf("failed to marshal output: %w", err)
+		return nil, err
 	}
+	if err := checkResultBytes(int64(len(raw)), maxQueryResultBytes); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
 
-	result := &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: string(outputJSON)},
-		},
+// forEachStreamElement streams the elements of the array at arrayPath in a
+// JSON file, so only matching elements are held in memory. Comments and
+// trailing commas are dropped as the file is read.
+func forEachStreamElement(ctx context.Context, path string, arrayPath []string, fn jsonstream.ElementFunc) error {
+	rc, err := streamReader.Open(ctx, path)
+	if err != nil {
+		return err
 	}
+	defer rc.Close()
+	return jsonstream.ForEach(jsonc.NewReader(rc), arrayPath, func(index int, elem any, size int64) (bool, error) {
+		if err := checkDepth(elem, len(arrayPath)+1); err != nil {
+			return false, err
+		}
+		return fn(index, elem, size)
+	})
+}
 
-	return result, output, nil
+// readStreamDocument reads and decodes a whole file through the stream
+// reader, within the read size limit.
+func readStreamDocument(ctx context.Context, path string) (any, error) {
+	rc, err := streamReader.Open(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	content, err := readAllLimited(rc, path)
+	if err != nil {
+		return nil, err
+	}
+	return parseFileDocument(path, string(content))
 }
 
-// navigateToPath traverses the JSON structure following the given path
+// forEachDocumentElement calls fn for each element of the array at
+// arrayPath in a YAML or TOML file, which is decoded as a whole. The size
+// of an element is the length of its compact JSON encoding.
+func forEachDocumentElement(ctx context.Context, path string, arrayPath []string, fn jsonstream.ElementFunc) error {
+	doc, err := readStreamDocument(ctx, path)
+	if err != nil {
+		return err
+	}
+	target, err := navigateToPath(doc, arrayPath)
+	if err != nil {
+		return err
+	}
+	arr, ok := target.([]any)
+	if !ok {
+		return domain.ErrNotAnArray
+	}
+
+	for i, elem := range arr {
+		raw, err := json.Marshal(elem)
+		if err != nil {
+			return err
+		}
+		more, err := fn(i, elem, int64(len(raw)))
+		if err != nil || !more {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxQueryResultBytes caps the total size of the elements a query may
+// return; zero means no limit. Set via SetMaxQueryResultBytes.
+var maxQueryResultBytes int64
+
+// SetMaxQueryResultBytes sets the ceiling on the combined size, in bytes of
+// source JSON, of the elements returned by json_query and jsonl_query.
+func SetMaxQueryResultBytes(n int64) {
+	maxQueryResultBytes = n
+}
+
+// checkResultBytes enforces a result size ceiling in bytes; zero means
+// no ceiling.
+func checkResultBytes(total, ceiling int64) error {
+	if ceiling > 0 && total > ceiling {
+		return fmt.Errorf("%w: matching elements exceed %d bytes; narrow the filters or set a limit", domain.ErrResultTooLarge, ceiling)
+	}
+	return nil
+}
+
+// navigateToPath traverses the JSON structure following the given path.
+// Keys select object members and numeric segments select array elements.
 func navigateToPath(data any, path []string) (any, error) {
 	current := data
 	for _, key := range path {
-		obj, ok := current.(map[string]any)
-		if !ok {
-			return nil, domain.ErrArrayPathNotFound
-		}
-		val, exists := obj[key]
-		if !exists {
+		switch node := current.(type) {
+		case map[string]any:
+			val, exists := node[key]
+			if !exists {
+				return nil, domain.ErrArrayPathNotFound
+			}
+			current = val
+		case []any:
+			idx, err := jsonpointer.ArrayIndex(key, len(node))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", domain.ErrArrayPathNotFound, err)
+			}
+			current = node[idx]
+		default:
 			return nil, domain.ErrArrayPathNotFound
 		}
-		current = val
 	}
 	return current, nil
 }
 
+// matchingIndices returns the indices of the elements of arr that match
+// all filters, as matchesElement decides
+func matchingIndices(arr []any, filters []Filter) []int {
+	indices := []int{}
+	for i, item := range arr {
+		if matchesElement(item, filters) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// elementField is the filter field naming the array element itself
+const elementField = "$"
+
+// matchesElement checks if an array element matches all filters. Elements
+// that are not objects have no fields, so they match only filters on the
+// element itself.
+func matchesElement(item any, filters []Filter) bool {
+	if _, ok := item.(map[string]any); !ok {
+		for _, filter := range filters {
+			if filter.Field != elementField {
+				return false
+			}
+		}
+	}
+	return matchesAllFilters(item, filters)
+}
+
 // matchesAllFilters checks if an item matches all filters (AND logic)
-func matchesAllFilters(item map[string]any, filters []Filter) bool {
+func matchesAllFilters(item any, filters []Filter) bool {
 	for _, filter := range filters {
 		if !applyFilter(item, filter) {
 			return false
@@ -149,7 +389,7 @@ func matchesAllFilters(item map[string]any, filters []Filter) bool {
 }
 
 // applyFilter checks if a single filter matches the item
-func applyFilter(item map[string]any, filter Filter) bool {
+func applyFilter(item any, filter Filter) bool {
 	value, exists := getFieldValue(item, filter.Field)
 
 	switch filter.Op {
@@ -187,15 +427,34 @@ func applyFilter(item map[string]any, filter Filter) bool {
 }
 
 // getFieldValue retrieves a field value from the item, supporting dot notation
-func getFieldValue(item map[string]any, field string) (any, bool) {
+func getFieldValue(item any, field string) (any, bool) {
+	if field == elementField {
+		return item, true
+	}
+	obj, ok := item.(map[string]any)
+	if !ok {
+		return nil, false
+	}
 	// For now, support simple field access (not nested dot notation in field)
 	// The field name itself might contain dots in the future
-	val, exists := item[field]
+	val, exists := obj[field]
 	return val, exists
 }
 
 // valuesEqual compares two values for equality
 func valuesEqual(a, b any) bool {
+	// Documents parsed for mutation keep numbers as json.Number
+	if n, ok := a.(json.Number); ok {
+		if f, err := n.Float64(); err == nil {
+			a = f
+		}
+	}
+	if n, ok := b.(json.Number); ok {
+		if f, err := n.Float64(); err == nil {
+			b = f
+		}
+	}
+
 	// Handle numeric comparisons (JSON numbers are float64)
 	switch av := a.(type) {
 	case float64:
@@ -223,7 +482,8 @@ func valuesEqual(a, b any) bool {
 			return av == bv
 		}
 	}
-	return a == b
+	// Arrays and objects compare structurally
+	return jsonpatch.Equal(a, b)
 }
 
 // arrayContains checks if an array contains a value
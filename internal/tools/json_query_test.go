@@ -2,7 +2,9 @@ package tools_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -233,6 +235,174 @@ func TestJSONQueryHandler(t *testing.T) {
 			wantCount: 0,
 			wantIDs:   []string{},
 		},
+		// Test Case 13: arrayPath stepping into an array element
+		{
+			name:  "arrayPath through array index",
+			files: map[string]string{"/tmp/groups.json": `{"groups": [{"members": [{"id": "a"}]}, {"members": [{"id": "b"}, {"id": "c"}]}]}`},
+			args: tools.JSONQueryArgs{
+				Path:      "/tmp/groups.json",
+				ArrayPath: []string{"groups", "1", "members"},
+			},
+			wantErr:   nil,
+			wantCount: 2,
+			wantIDs:   []string{"b", "c"},
+		},
+		// Test Case 14: arrayPath index out of range
+		{
+			name:  "arrayPath index out of range",
+			files: map[string]string{"/tmp/groups.json": `{"groups": []}`},
+			args: tools.JSONQueryArgs{
+				Path:      "/tmp/groups.json",
+				ArrayPath: []string{"groups", "0", "members"},
+			},
+			wantErr:   domain.ErrArrayPathNotFound,
+			wantCount: 0,
+			wantIDs:   []string{},
+		},
+		// Test Case 15: limit stops reading before the rest of the file
+		{
+			name:  "limit stops streaming early",
+			files: map[string]string{"/tmp/truncated.json": `{"items": [{"id": "a"}, {"id": "b"}, {"id": "c", "broken`},
+			args: tools.JSONQueryArgs{
+				Path:      "/tmp/truncated.json",
+				ArrayPath: []string{"items"},
+				Limit:     intPtr(1),
+			},
+			wantErr:   nil,
+			wantCount: 1,
+			wantIDs:   []string{"a"},
+		},
+		// Test Case 15b: the limit-th match ends the read before a malformed tail
+		{
+			name:  "limit reached before malformed tail",
+			files: map[string]string{"/tmp/tail.json": `[{"id": "a", "n": 1}, {"id": "b", "n": 1}, {"id": "c", "n": 2}, {"id": "d", "n": 2}, BROKEN`},
+			args: tools.JSONQueryArgs{
+				Path:    "/tmp/tail.json",
+				Filters: []tools.Filter{{Field: "n", Op: "eq", Value: 1}},
+				Limit:   intPtr(2),
+			},
+			wantErr:   nil,
+			wantCount: 2,
+			wantIDs:   []string{"a", "b"},
+		},
+		// Test Case 16: malformed JSON after the array
+		{
+			name:  "malformed JSON",
+			files: map[string]string{"/tmp/bad.json": `{"items": [{"id": "a"}], "other": }`},
+			args: tools.JSONQueryArgs{
+				Path:      "/tmp/bad.json",
+				ArrayPath: []string{"items"},
+			},
+			wantErr:   domain.ErrInvalidJSON,
+			wantCount: 0,
+			wantIDs:   []string{},
+		},
+		// Test Case 17: JSONC file with comments and trailing commas
+		{
+			name: "JSONC file",
+			files: map[string]string{"/tmp/config.jsonc": `{
+  // Investors we track
+  "investors": [
+    { "id": "a", "type": "vc", },
+    /* { "id": "b", "type": "vc" }, */
+    { id: 'c', type: 'vc' },
+  ],
+}`},
+			args: tools.JSONQueryArgs{
+				Path:      "/tmp/config.jsonc",
+				ArrayPath: []string{"investors"},
+				Filters: []tools.Filter{
+					{Field: "type", Op: "eq", Value: "vc"},
+				},
+			},
+			wantErr:   nil,
+			wantCount: 2,
+			wantIDs:   []string{"a", "c"},
+		},
+		// Test Case 18: YAML file with anchors
+		{
+			name: "YAML file",
+			files: map[string]string{"/tmp/investors.yaml": `# Investors we track
+defaults: &vc
+  type: vc
+investors:
+  - id: a
+    <<: *vc
+  - id: b
+    type: angel
+  - {id: c, type: vc}
+`},
+			args: tools.JSONQueryArgs{
+				Path:      "/tmp/investors.yaml",
+				ArrayPath: []string{"investors"},
+				Filters: []tools.Filter{
+					{Field: "type", Op: "eq", Value: "vc"},
+				},
+			},
+			wantErr:   nil,
+			wantCount: 2,
+			wantIDs:   []string{"a", "c"},
+		},
+		// Test Case 19: multi-document YAML stream queried as an array
+		{
+			name:  "multi-document YAML",
+			files: map[string]string{"/tmp/manifests.yml": "id: a\ntype: vc\n---\nid: b\ntype: angel\n---\nid: c\ntype: vc\n"},
+			args: tools.JSONQueryArgs{
+				Path: "/tmp/manifests.yml",
+				Filters: []tools.Filter{
+					{Field: "type", Op: "neq", Value: "vc"},
+				},
+			},
+			wantErr:   nil,
+			wantCount: 1,
+			wantIDs:   []string{"b"},
+		},
+		// Test Case 20: TOML array of tables
+		{
+			name: "TOML file",
+			files: map[string]string{"/tmp/investors.toml": `[[investors]]
+id = "a"
+stage = 1
+
+[[investors]]
+id = "b"
+stage = 2
+`},
+			args: tools.JSONQueryArgs{
+				Path:      "/tmp/investors.toml",
+				ArrayPath: []string{"investors"},
+				Filters: []tools.Filter{
+					{Field: "stage", Op: "eq", Value: 2},
+				},
+			},
+			wantErr:   nil,
+			wantCount: 1,
+			wantIDs:   []string{"b"},
+		},
+		// Test Case 21: YAML arrayPath to a mapping
+		{
+			name:  "YAML arrayPath not an array",
+			files: map[string]string{"/tmp/config.yaml": "data:\n  id: a\n"},
+			args: tools.JSONQueryArgs{
+				Path:      "/tmp/config.yaml",
+				ArrayPath: []string{"data"},
+			},
+			wantErr:   domain.ErrNotAnArray,
+			wantCount: 0,
+			wantIDs:   []string{},
+		},
+		// Test Case 22: arrayPath to an object
+		{
+			name:  "arrayPath not an array",
+			files: map[string]string{"/tmp/nested.json": nestedDataJSON},
+			args: tools.JSONQueryArgs{
+				Path:      "/tmp/nested.json",
+				ArrayPath: []string{"data"},
+			},
+			wantErr:   domain.ErrNotAnArray,
+			wantCount: 0,
+			wantIDs:   []string{},
+		},
 	}
 
 	for _, tt := range tests {
@@ -240,7 +410,7 @@ func TestJSONQueryHandler(t *testing.T) {
 			// Setup in-memory reader with test files
 			memReader := reader.NewInMemoryFileReader()
 			memReader.Files = tt.files
-			tools.SetFileReader(memReader)
+			tools.SetStreamReader(memReader)
 
 			result, output, err := tools.JSONQueryHandler(
 				context.Background(),
@@ -310,3 +480,234 @@ func TestJSONQueryHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONQueryHandler_Elements(t *testing.T) {
+	const listsJSON = `{
+  "tags": ["go", "rust", "go", "zig"],
+  "scores": [3, 10, 7.5, null],
+  "pairs": [["a", 1], ["b", 2], []],
+  "mixed": [{"id": "x"}, "x", 4]
+}`
+
+	tests := []struct {
+		name        string
+		args        tools.JSONQueryArgs
+		wantResult  string
+		wantIndices []int
+	}{
+		{
+			name:        "strings equal to a value",
+			args:        tools.JSONQueryArgs{ArrayPath: tools.ArrayPath{"tags"}, Filters: []tools.Filter{{Field: "$", Op: "eq", Value: "go"}}},
+			wantResult:  `["go","go"]`,
+			wantIndices: []int{0, 2},
+		},
+		{
+			name:        "numbers not equal to a value",
+			args:        tools.JSONQueryArgs{ArrayPath: tools.ArrayPath{"scores"}, Filters: []tools.Filter{{Field: "$", Op: "neq", Value: 10}, {Field: "$", Op: "is_not_null"}}},
+			wantResult:  `[3,7.5]`,
+			wantIndices: []int{0, 2},
+		},
+		{
+			name:        "arrays containing a value",
+			args:        tools.JSONQueryArgs{ArrayPath: tools.ArrayPath{"pairs"}, Filters: []tools.Filter{{Field: "$", Op: "contains", Value: "b"}}},
+			wantResult:  `[["b",2]]`,
+			wantIndices: []int{1},
+		},
+		{
+			name:        "arrays equal to an array",
+			args:        tools.JSONQueryArgs{ArrayPath: tools.ArrayPath{"pairs"}, Filters: []tools.Filter{{Field: "$", Op: "eq", Value: []any{"a", 1}}}},
+			wantResult:  `[["a",1]]`,
+			wantIndices: []int{0},
+		},
+		{
+			name:        "no filters return every element",
+			args:        tools.JSONQueryArgs{ArrayPath: tools.ArrayPath{"mixed"}, Limit: intPtr(2)},
+			wantResult:  `[{"id":"x"},"x"]`,
+			wantIndices: []int{0, 1},
+		},
+		{
+			name:        "field filters skip elements that are not objects",
+			args:        tools.JSONQueryArgs{ArrayPath: tools.ArrayPath{"mixed"}, Filters: []tools.Filter{{Field: "id", Op: "is_not_null"}}},
+			wantResult:  `[{"id":"x"}]`,
+			wantIndices: []int{0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memReader := reader.NewInMemoryFileReader()
+			memReader.Files = map[string]string{"/tmp/lists.json": listsJSON}
+			tools.SetStreamReader(memReader)
+
+			tt.args.Path = "/tmp/lists.json"
+			_, output, err := tools.JSONQueryHandler(context.Background(), &mcp.CallToolRequest{}, tt.args)
+			if err != nil {
+				t.Fatalf("JSONQueryHandler() unexpected error = %v", err)
+			}
+
+			got, err := json.Marshal(output.Result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.wantResult {
+				t.Errorf("JSONQueryHandler() result = %s, want %s", got, tt.wantResult)
+			}
+			if !reflect.DeepEqual(output.Indices, tt.wantIndices) {
+				t.Errorf("JSONQueryHandler() indices = %v, want %v", output.Indices, tt.wantIndices)
+			}
+			if output.Count != len(tt.wantIndices) {
+				t.Errorf("JSONQueryHandler() count = %v, want %v", output.Count, len(tt.wantIndices))
+			}
+		})
+	}
+}
+
+func TestJSONQueryHandler_Expr(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       tools.JSONQueryArgs
+		wantErr    error
+		wantResult string
+	}{
+		{
+			name:       "filter and project",
+			args:       tools.JSONQueryArgs{Expr: "[?type == 'vc'].{id: id, regions: length(regions)}"},
+			wantResult: `[{"id":"sequoia-capital","regions":1},{"id":"a16z","regions":2}]`,
+		},
+		{
+			name:       "sort and slice with limit",
+			args:       tools.JSONQueryArgs{Expr: "sort_by(@, &name)[*].name", Limit: intPtr(2)},
+			wantResult: `["Andreessen Horowitz","AngelList Access Fund"]`,
+		},
+		{
+			name:       "scalar result",
+			args:       tools.JSONQueryArgs{Expr: "length([?profileUpdatedAt == null])"},
+			wantResult: `[2]`,
+		},
+		{
+			name:       "null result",
+			args:       tools.JSONQueryArgs{Expr: "[?type == 'pe'] | [0]"},
+			wantResult: `[]`,
+		},
+		{
+			name:    "syntax error before the file is read",
+			args:    tools.JSONQueryArgs{Path: "/tmp/missing.json", Expr: "[?type == 'vc'"},
+			wantErr: domain.ErrInvalidExpression,
+		},
+		{
+			name:    "filters and expr together",
+			args:    tools.JSONQueryArgs{Expr: "[*]", Filters: []tools.Filter{{Field: "type", Op: "eq", Value: "vc"}}},
+			wantErr: domain.ErrInvalidFilter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memReader := reader.NewInMemoryFileReader()
+			memReader.Files = map[string]string{"/tmp/investors.json": testDataJSON}
+			tools.SetStreamReader(memReader)
+
+			if tt.args.Path == "" {
+				tt.args.Path = "/tmp/investors.json"
+			}
+			_, output, err := tools.JSONQueryHandler(context.Background(), &mcp.CallToolRequest{}, tt.args)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("JSONQueryHandler() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("JSONQueryHandler() unexpected error = %v", err)
+			}
+
+			got, err := json.Marshal(output.Result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.wantResult {
+				t.Errorf("JSONQueryHandler() result = %s, want %s", got, tt.wantResult)
+			}
+			if output.Indices != nil {
+				t.Errorf("JSONQueryHandler() indices = %v, want none", output.Indices)
+			}
+		})
+	}
+}
+
+func TestJSONQueryHandlerResultCeiling(t *testing.T) {
+	memReader := reader.NewInMemoryFileReader()
+	memReader.Files = map[string]string{"/tmp/items.json": `[{"id": "a", "pad": "xxxxxxxxxx"}, {"id": "b", "pad": "xxxxxxxxxx"}]`}
+	tools.SetStreamReader(memReader)
+	tools.SetMaxQueryResultBytes(40)
+	t.Cleanup(func() { tools.SetMaxQueryResultBytes(0) })
+
+	args := tools.JSONQueryArgs{Path: "/tmp/items.json"}
+	_, _, err := tools.JSONQueryHandler(context.Background(), &mcp.CallToolRequest{}, args)
+	if !errors.Is(err, domain.ErrResultTooLarge) {
+		t.Errorf("JSONQueryHandler() error = %v, wantErr %v", err, domain.ErrResultTooLarge)
+	}
+
+	// A limit keeps the result under the ceiling
+	args.Limit = intPtr(1)
+	_, output, err := tools.JSONQueryHandler(context.Background(), &mcp.CallToolRequest{}, args)
+	if err != nil {
+		t.Fatalf("JSONQueryHandler() unexpected error = %v", err)
+	}
+	if output.Count != 1 {
+		t.Errorf("JSONQueryHandler() count = %v, want 1", output.Count)
+	}
+}
+
+func TestArrayPath_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    tools.ArrayPath
+		wantErr error
+	}{
+		{
+			name:  "list of keys",
+			input: `{"path": "x.json", "arrayPath": ["data", "items"]}`,
+			want:  tools.ArrayPath{"data", "items"},
+		},
+		{
+			name:  "JSON Pointer",
+			input: `{"path": "x.json", "arrayPath": "/data/items/0/tags"}`,
+			want:  tools.ArrayPath{"data", "items", "0", "tags"},
+		},
+		{
+			name:  "escaped JSON Pointer",
+			input: `{"path": "x.json", "arrayPath": "/a~1b"}`,
+			want:  tools.ArrayPath{"a/b"},
+		},
+		{
+			name:    "invalid JSON Pointer",
+			input:   `{"path": "x.json", "arrayPath": "data/items"}`,
+			wantErr: domain.ErrInvalidPointer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args tools.JSONQueryArgs
+			err := json.Unmarshal([]byte(tt.input), &args)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unmarshal() unexpected error = %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(args.ArrayPath, tt.want) {
+				t.Errorf("Unmarshal() arrayPath = %#v, want %#v", args.ArrayPath, tt.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 
 	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
 	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -47,6 +48,9 @@ func JSONReadHandler(
 	if err != nil {
 		return nil, JSONReadOutput{}, err
 	}
+	if err := authorize(JSONReadTool, policy.Read, absPath); err != nil {
+		return nil, JSONReadOutput{}, err
+	}
 
 	slog.Info("json_read tool called",
 		slog.String("path", absPath),
@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/csvdoc"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
+)
+
+// JSONToCSVTool defines the json_to_csv tool metadata
+var JSONToCSVTool = &mcp.Tool{
+	Name:        "json_to_csv",
+	Description: "Export the objects of a JSON array, optionally filtered like json_query, to a CSV file with atomic writes. Nested fields become dot-separated columns and arrays of scalars are joined in one cell",
+	InputSchema: inputSchemaFor[JSONToCSVArgs](),
+}
+
+// JSONToCSVArgs defines the input parameters for the json_to_csv tool
+type JSONToCSVArgs struct {
+	Path           string    `json:"path" jsonschema:"Absolute or relative path to the JSON file to export"`
+	OutputPath     string    `json:"outputPath" jsonschema:"Absolute or relative path of the CSV file to write"`
+	ArrayPath      ArrayPath `json:"arrayPath,omitempty" jsonschema:"Path to array in JSON structure, as a list of keys (e.g., [\"data\", \"items\"]) or a JSON Pointer (e.g., \"/data/items\")"`
+	Filters        []Filter  `json:"filters,omitempty" jsonschema:"Array of filter conditions (AND logic), as in json_query"`
+	Limit          *int      `json:"limit,omitempty" jsonschema:"Maximum number of rows to export"`
+	Columns        []string  `json:"columns,omitempty" jsonschema:"Columns to export, in order, as dot-separated field paths (e.g., 'address.city'); defaults to every field"`
+	Delimiter      string    `json:"delimiter,omitempty" jsonschema:"Field delimiter: a single character such as ; or tab (default ,)"`
+	ArraySeparator string    `json:"arraySeparator,omitempty" jsonschema:"Separator joining the items of array fields in one cell (default ;)"`
+}
+
+// JSONToCSVOutput defines the output structure for the json_to_csv tool
+type JSONToCSVOutput struct {
+	Path    string   `json:"path"`
+	Rows    int      `json:"rows"`
+	Columns []string `json:"columns"`
+	Size    int64    `json:"size"`
+	Secrets []string `json:"secrets,omitempty"`
+}
+
+// JSONToCSVHandler handles the json_to_csv tool invocation
+func JSONToCSVHandler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args JSONToCSVArgs,
+) (*mcp.CallToolResult, JSONToCSVOutput, error) {
+	// Resolve paths (validates and converts to absolute)
+	absPath, err := pathutil.Resolve(args.Path)
+	if err != nil {
+		return nil, JSONToCSVOutput{}, err
+	}
+	if err := authorize(JSONToCSVTool, policy.Read, absPath); err != nil {
+		return nil, JSONToCSVOutput{}, err
+	}
+	outPath, err := pathutil.Resolve(args.OutputPath)
+	if err != nil {
+		return nil, JSONToCSVOutput{}, err
+	}
+	if err := authorize(JSONToCSVTool, policy.Write, outPath); err != nil {
+		return nil, JSONToCSVOutput{}, err
+	}
+	delimiter, err := parseDelimiter(args.Delimiter)
+	if err != nil {
+		return nil, JSONToCSVOutput{}, err
+	}
+
+	slog.Info("json_to_csv tool called",
+		slog.String("path", absPath),
+		slog.String("outputPath", outPath),
+		slog.Any("arrayPath", args.ArrayPath),
+		slog.Int("filterCount", len(args.Filters)),
+	)
+
+	// The rows go to a file, so the query result ceiling does not apply
+	records, _, err := queryArray(ctx, absPath, args.ArrayPath, args.Filters, args.Limit, 0)
+	if err != nil {
+		return nil, JSONToCSVOutput{}, err
+	}
+
+	content, columns, err := csvdoc.Encode(records, csvdoc.EncodeOptions{
+		Columns:        args.Columns,
+		Delimiter:      delimiter,
+		ArraySeparator: args.ArraySeparator,
+	})
+	if err != nil {
+		return nil, JSONToCSVOutput{}, err
+	}
+
+	unlock := lockPath(outPath)
+	defer unlock()
+
+	updated, notes, err := guardSecrets(JSONToCSVTool, outPath, replacedContent(ctx, outPath), string(content))
+	if err != nil {
+		return nil, JSONToCSVOutput{}, err
+	}
+	size, err := fileWriter.Write(ctx, outPath, updated)
+	if err != nil {
+		return nil, JSONToCSVOutput{}, err
+	}
+
+	output := JSONToCSVOutput{
+		Path:    outPath,
+		Rows:    len(records),
+		Columns: columns,
+		Size:    size,
+		Secrets: notes,
+	}
+
+	message := fmt.Sprintf("Successfully exported %d rows with %d columns to %s", output.Rows, len(columns), outPath)
+	return textResult(withSecretNotes(message, notes)), output, nil
+}
+
+// parseDelimiter converts the delimiter argument into a CSV field
+// delimiter. The empty string means a comma.
+func parseDelimiter(delimiter string) (rune, error) {
+	switch delimiter {
+	case "":
+		return ',', nil
+	case "tab", `\t`:
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(delimiter)
+	if size != len(delimiter) || r == utf8.RuneError || r == '"' || r == '\r' || r == '\n' {
+		return 0, fmt.Errorf("%w: delimiter must be a single character other than a quote or line break, got %q", domain.ErrInvalidFormat, delimiter)
+	}
+	return r, nil
+}
@@ -0,0 +1,98 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tools"
+	"github.com/robertbagge/markdown-writer-mcp/internal/writer"
+)
+
+func TestJSONToCSVHandler(t *testing.T) {
+	const investorsJSON = `{"investors": [
+		{"id": "a", "type": "vc", "hq": {"city": "Oslo"}, "regions": ["EU", "US"]},
+		{"id": "b", "type": "angel", "hq": {"city": "Lund"}},
+		{"id": "c", "type": "vc", "funds": 3}
+	]}`
+
+	tests := []struct {
+		name        string
+		args        tools.JSONToCSVArgs
+		wantErr     error
+		wantRows    int
+		wantColumns []string
+		wantContent string
+	}{
+		{
+			name:        "every field",
+			args:        tools.JSONToCSVArgs{Path: "/tmp/investors.json", OutputPath: "/tmp/out.csv", ArrayPath: tools.ArrayPath{"investors"}},
+			wantRows:    3,
+			wantColumns: []string{"hq.city", "id", "regions", "type", "funds"},
+			wantContent: "hq.city,id,regions,type,funds\nOslo,a,EU;US,vc,\nLund,b,,angel,\n,c,,vc,3\n",
+		},
+		{
+			name: "filtered with chosen columns",
+			args: tools.JSONToCSVArgs{
+				Path:           "/tmp/investors.json",
+				OutputPath:     "/tmp/out.csv",
+				ArrayPath:      tools.ArrayPath{"investors"},
+				Filters:        []tools.Filter{{Field: "type", Op: "eq", Value: "vc"}},
+				Columns:        []string{"id", "regions", "hq.city"},
+				Delimiter:      "tab",
+				ArraySeparator: "|",
+			},
+			wantRows:    2,
+			wantColumns: []string{"id", "regions", "hq.city"},
+			wantContent: "id\tregions\thq.city\na\tEU|US\tOslo\nc\t\t\n",
+		},
+		{
+			name:    "invalid delimiter",
+			args:    tools.JSONToCSVArgs{Path: "/tmp/investors.json", OutputPath: "/tmp/out.csv", ArrayPath: tools.ArrayPath{"investors"}, Delimiter: "::"},
+			wantErr: domain.ErrInvalidFormat,
+		},
+		{
+			name:    "not an array",
+			args:    tools.JSONToCSVArgs{Path: "/tmp/investors.json", OutputPath: "/tmp/out.csv"},
+			wantErr: domain.ErrNotAnArray,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memReader := reader.NewInMemoryFileReader()
+			memReader.Files = map[string]string{"/tmp/investors.json": investorsJSON}
+			tools.SetStreamReader(memReader)
+			memWriter := writer.NewInMemoryFileWriter()
+			tools.SetFileWriter(memWriter)
+
+			_, output, err := tools.JSONToCSVHandler(context.Background(), &mcp.CallToolRequest{}, tt.args)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("JSONToCSVHandler() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if len(memWriter.Files) != 0 {
+					t.Errorf("JSONToCSVHandler() wrote files on error: %v", memWriter.Files)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("JSONToCSVHandler() unexpected error = %v", err)
+			}
+
+			if output.Rows != tt.wantRows {
+				t.Errorf("JSONToCSVHandler() rows = %v, want %v", output.Rows, tt.wantRows)
+			}
+			if !reflect.DeepEqual(output.Columns, tt.wantColumns) {
+				t.Errorf("JSONToCSVHandler() columns = %v, want %v", output.Columns, tt.wantColumns)
+			}
+			if got := memWriter.Files["/tmp/out.csv"]; got != tt.wantContent {
+				t.Errorf("JSONToCSVHandler() content = %q, want %q", got, tt.wantContent)
+			}
+		})
+	}
+}
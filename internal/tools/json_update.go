@@ -0,0 +1,212 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpatch"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
+)
+
+// JSONUpdateTool defines the json_update tool metadata
+var JSONUpdateTool = &mcp.Tool{
+	Name:        "json_update",
+	Description: "Set or unset fields on JSON array elements matching filters, or upsert an element by a key field, with atomic writes. Supports dryRun and a maxAffected safety cap",
+	InputSchema: inputSchemaFor[JSONUpdateArgs](),
+}
+
+// Upsert identifies an element by a key field and inserts or updates it
+type Upsert struct {
+	Key  string         `json:"key" jsonschema:"Field that identifies the element (e.g., 'id')"`
+	Item map[string]any `json:"item" jsonschema:"Element to insert; if an element with the same key value exists, these fields are set on it instead"`
+}
+
+// JSONUpdateArgs defines the input parameters for the json_update tool
+type JSONUpdateArgs struct {
+	Path        string         `json:"path" jsonschema:"Absolute or relative path to the JSON file"`
+	ArrayPath   ArrayPath      `json:"arrayPath,omitempty" jsonschema:"Path to array in JSON structure, as a list of keys (e.g., [\"data\", \"items\"]) or a JSON Pointer (e.g., \"/data/items\")"`
+	Filters     []Filter       `json:"filters,omitempty" jsonschema:"Array of filter conditions (AND logic) selecting the elements to update"`
+	All         bool           `json:"all,omitempty" jsonschema:"Update every element; required when no filters are given"`
+	Set         map[string]any `json:"set,omitempty" jsonschema:"Fields to set on every matched element"`
+	Unset       []string       `json:"unset,omitempty" jsonschema:"Fields to remove from every matched element"`
+	Upsert      *Upsert        `json:"upsert,omitempty" jsonschema:"Insert or update a single element by key field (cannot be combined with filters, set or unset)"`
+	DryRun      bool           `json:"dryRun,omitempty" jsonschema:"Report matching elements without writing the file"`
+	MaxAffected *int           `json:"maxAffected,omitempty" jsonschema:"Fail without writing if more than this many elements match"`
+}
+
+// JSONUpdateOutput defines the output structure for the json_update tool
+type JSONUpdateOutput struct {
+	Path     string   `json:"path"`
+	Matched  int      `json:"matched"`
+	Updated  int      `json:"updated"`
+	Inserted int      `json:"inserted"`
+	Indices  []int    `json:"indices"`
+	Matches  []any    `json:"matches,omitempty"`
+	DryRun   bool     `json:"dryRun"`
+	Size     int64    `json:"size"`
+	Secrets  []string `json:"secrets,omitempty"`
+}
+
+// JSONUpdateHandler handles the json_update tool invocation
+func JSONUpdateHandler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args JSONUpdateArgs,
+) (*mcp.CallToolResult, JSONUpdateOutput, error) {
+	// Resolve path (validates and converts to absolute)
+	absPath, err := pathutil.Resolve(args.Path)
+	if err != nil {
+		return nil, JSONUpdateOutput{}, err
+	}
+	if err := authorize(JSONUpdateTool, policy.Write, absPath); err != nil {
+		return nil, JSONUpdateOutput{}, err
+	}
+
+	if err := validateUpdate(args); err != nil {
+		return nil, JSONUpdateOutput{}, err
+	}
+
+	slog.Info("json_update tool called",
+		slog.String("path", absPath),
+		slog.Any("arrayPath", args.ArrayPath),
+		slog.Int("filterCount", len(args.Filters)),
+		slog.Bool("upsert", args.Upsert != nil),
+		slog.Bool("dryRun", args.DryRun),
+	)
+
+	// Hold the file lock across the read-modify-write cycle
+	unlock := lockPath(absPath)
+	defer unlock()
+
+	content, doc, arr, err := loadJSONArray(ctx, absPath, args.ArrayPath)
+	if err != nil {
+		return nil, JSONUpdateOutput{}, err
+	}
+
+	// Select the elements to update
+	var indices []int
+	if args.Upsert != nil {
+		key := args.Upsert.Item[args.Upsert.Key]
+		indices = matchingIndices(arr, []Filter{{Field: args.Upsert.Key, Op: "eq", Value: key}})
+	} else {
+		indices = matchingIndices(arr, args.Filters)
+	}
+
+	if err := checkMaxAffected(len(indices), args.MaxAffected); err != nil {
+		return nil, JSONUpdateOutput{}, err
+	}
+
+	output := JSONUpdateOutput{
+		Path:    absPath,
+		Matched: len(indices),
+		Indices: indices,
+		DryRun:  args.DryRun,
+	}
+
+	if args.DryRun {
+		output.Matches = make([]any, len(indices))
+		for i, idx := range indices {
+			output.Matches[i] = arr[idx]
+		}
+		if args.Upsert != nil && len(indices) == 0 {
+			output.Inserted = 1
+		}
+		message := fmt.Sprintf("Dry run: %d elements match in %s, no changes written", len(indices), absPath)
+		return textResult(message), output, nil
+	}
+
+	// Apply the update to each matched element
+	set, unset := args.Set, args.Unset
+	if args.Upsert != nil {
+		set = args.Upsert.Item
+	}
+	for _, idx := range indices {
+		item, ok := arr[idx].(map[string]any)
+		if !ok {
+			return nil, JSONUpdateOutput{}, fmt.Errorf("%w: element %d is not an object, so it has no fields to set or unset", domain.ErrInvalidUpdate, idx)
+		}
+		if updateFields(item, set, unset) {
+			output.Updated++
+		}
+	}
+	if args.Upsert != nil && len(indices) == 0 {
+		arr = append(arr, jsonpatch.DeepCopy(args.Upsert.Item))
+		output.Inserted = 1
+	}
+
+	if output.Updated == 0 && output.Inserted == 0 {
+		message := fmt.Sprintf("%d elements matched in %s, nothing changed", len(indices), absPath)
+		return textResult(message), output, nil
+	}
+
+	doc, err = replaceArray(doc, args.ArrayPath, arr)
+	if err != nil {
+		return nil, JSONUpdateOutput{}, err
+	}
+	updated, err := encodeForWrite(ctx, JSONUpdateTool, absPath, doc, content)
+	if err != nil {
+		return nil, JSONUpdateOutput{}, err
+	}
+	updated, notes, err := guardSecrets(JSONUpdateTool, absPath, content, updated)
+	if err != nil {
+		return nil, JSONUpdateOutput{}, err
+	}
+	size, err := fileWriter.Write(ctx, absPath, updated)
+	if err != nil {
+		return nil, JSONUpdateOutput{}, err
+	}
+	output.Secrets = notes
+	output.Size = size
+
+	message := fmt.Sprintf("Successfully updated %d and inserted %d elements in %s (%d bytes)",
+		output.Updated, output.Inserted, absPath, size)
+	return textResult(withSecretNotes(message, notes)), output, nil
+}
+
+// validateUpdate checks that the arguments describe exactly one kind of update
+func validateUpdate(args JSONUpdateArgs) error {
+	if args.Upsert != nil {
+		if len(args.Filters) > 0 || args.All || len(args.Set) > 0 || len(args.Unset) > 0 {
+			return fmt.Errorf("%w: upsert cannot be combined with filters, all, set or unset", domain.ErrInvalidUpdate)
+		}
+		if args.Upsert.Key == "" {
+			return fmt.Errorf("%w: upsert requires a key field", domain.ErrInvalidUpdate)
+		}
+		if _, ok := args.Upsert.Item[args.Upsert.Key]; !ok {
+			return fmt.Errorf("%w: upsert item has no %q field", domain.ErrInvalidUpdate, args.Upsert.Key)
+		}
+		return nil
+	}
+	if len(args.Set) == 0 && len(args.Unset) == 0 {
+		return fmt.Errorf("%w: provide set, unset or upsert", domain.ErrInvalidUpdate)
+	}
+	// Refuse to silently rewrite the whole array
+	if len(args.Filters) == 0 && !args.All {
+		return fmt.Errorf("%w: json_update requires at least one filter, or all: true to update every element", domain.ErrInvalidUpdate)
+	}
+	return nil
+}
+
+// updateFields sets and unsets fields on an element and reports whether
+// anything actually changed
+func updateFields(item map[string]any, set map[string]any, unset []string) bool {
+	changed := false
+	for field, value := range set {
+		old, exists := item[field]
+		if !exists || !jsonpatch.Equal(old, value) {
+			item[field] = jsonpatch.DeepCopy(value)
+			changed = true
+		}
+	}
+	for _, field := range unset {
+		if _, exists := item[field]; exists {
+			delete(item, field)
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,301 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tools"
+	"github.com/robertbagge/markdown-writer-mcp/internal/writer"
+)
+
+const registryJSON = `{"investors": [
+  {"id": "a", "type": "vc", "stage": "seed"},
+  {"id": "b", "type": "angel", "stage": "seed"},
+  {"id": "c", "type": "vc", "rank": 3}
+]}`
+
+func TestJSONUpdateHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         tools.JSONUpdateArgs
+		wantErr      error
+		wantMatched  int
+		wantUpdated  int
+		wantInserted int
+		wantIndices  []int
+		wantContent  string // empty means nothing should be written
+	}{
+		{
+			name: "set and unset fields on matches",
+			args: tools.JSONUpdateArgs{
+				Path:      "/tmp/registry.json",
+				ArrayPath: []string{"investors"},
+				Filters:   []tools.Filter{{Field: "type", Op: "eq", Value: "vc"}},
+				Set:       map[string]any{"reviewed": true},
+				Unset:     []string{"stage"},
+			},
+			wantMatched: 2,
+			wantUpdated: 2,
+			wantIndices: []int{0, 2},
+			wantContent: `{
+  "investors": [
+    {
+      "id": "a",
+      "type": "vc",
+      "reviewed": true
+    },
+    {
+      "id": "b",
+      "type": "angel",
+      "stage": "seed"
+    },
+    {
+      "id": "c",
+      "type": "vc",
+      "rank": 3,
+      "reviewed": true
+    }
+  ]
+}`,
+		},
+		{
+			name: "numeric filter against file numbers",
+			args: tools.JSONUpdateArgs{
+				Path:      "/tmp/registry.json",
+				ArrayPath: []string{"investors"},
+				Filters:   []tools.Filter{{Field: "rank", Op: "eq", Value: 3}},
+				Set:       map[string]any{"rank": 3},
+			},
+			wantMatched: 1,
+			wantUpdated: 0,
+			wantIndices: []int{2},
+		},
+		{
+			name: "upsert updates existing element",
+			args: tools.JSONUpdateArgs{
+				Path:      "/tmp/registry.json",
+				ArrayPath: []string{"investors"},
+				Upsert:    &tools.Upsert{Key: "id", Item: map[string]any{"id": "b", "stage": "series-a"}},
+			},
+			wantMatched: 1,
+			wantUpdated: 1,
+			wantIndices: []int{1},
+			wantContent: `{
+  "investors": [
+    {
+      "id": "a",
+      "type": "vc",
+      "stage": "seed"
+    },
+    {
+      "id": "b",
+      "type": "angel",
+      "stage": "series-a"
+    },
+    {
+      "id": "c",
+      "type": "vc",
+      "rank": 3
+    }
+  ]
+}`,
+		},
+		{
+			name: "upsert inserts missing element",
+			args: tools.JSONUpdateArgs{
+				Path:      "/tmp/registry.json",
+				ArrayPath: []string{"investors"},
+				Upsert:    &tools.Upsert{Key: "id", Item: map[string]any{"id": "d", "type": "vc"}},
+			},
+			wantMatched:  0,
+			wantInserted: 1,
+			wantIndices:  []int{},
+			wantContent: `{
+  "investors": [
+    {
+      "id": "a",
+      "type": "vc",
+      "stage": "seed"
+    },
+    {
+      "id": "b",
+      "type": "angel",
+      "stage": "seed"
+    },
+    {
+      "id": "c",
+      "type": "vc",
+      "rank": 3
+    },
+    {
+      "id": "d",
+      "type": "vc"
+    }
+  ]
+}`,
+		},
+		{
+			name: "dry run does not write",
+			args: tools.JSONUpdateArgs{
+				Path:      "/tmp/registry.json",
+				ArrayPath: []string{"investors"},
+				Filters:   []tools.Filter{{Field: "stage", Op: "eq", Value: "seed"}},
+				Set:       map[string]any{"stage": "pre-seed"},
+				DryRun:    true,
+			},
+			wantMatched: 2,
+			wantIndices: []int{0, 1},
+		},
+		{
+			name: "all updates every element",
+			args: tools.JSONUpdateArgs{
+				Path:      "/tmp/registry.json",
+				ArrayPath: []string{"investors"},
+				All:       true,
+				Set:       map[string]any{"reviewed": true},
+				DryRun:    true,
+			},
+			wantMatched: 3,
+			wantIndices: []int{0, 1, 2},
+		},
+		{
+			name: "maxAffected exceeded",
+			args: tools.JSONUpdateArgs{
+				Path:        "/tmp/registry.json",
+				ArrayPath:   []string{"investors"},
+				All:         true,
+				Set:         map[string]any{"reviewed": true},
+				MaxAffected: intPtr(2),
+			},
+			wantErr: domain.ErrTooManyMatches,
+		},
+		{
+			name: "upsert combined with filters",
+			args: tools.JSONUpdateArgs{
+				Path:    "/tmp/registry.json",
+				Filters: []tools.Filter{{Field: "type", Op: "eq", Value: "vc"}},
+				Upsert:  &tools.Upsert{Key: "id", Item: map[string]any{"id": "a"}},
+			},
+			wantErr: domain.ErrInvalidUpdate,
+		},
+		{
+			name: "upsert item missing key",
+			args: tools.JSONUpdateArgs{
+				Path:   "/tmp/registry.json",
+				Upsert: &tools.Upsert{Key: "id", Item: map[string]any{"type": "vc"}},
+			},
+			wantErr: domain.ErrInvalidUpdate,
+		},
+		{
+			name: "nothing to update",
+			args: tools.JSONUpdateArgs{
+				Path:    "/tmp/registry.json",
+				Filters: []tools.Filter{{Field: "type", Op: "eq", Value: "vc"}},
+			},
+			wantErr: domain.ErrInvalidUpdate,
+		},
+		{
+			name: "no filters",
+			args: tools.JSONUpdateArgs{
+				Path:      "/tmp/registry.json",
+				ArrayPath: []string{"investors"},
+				Set:       map[string]any{"reviewed": true},
+			},
+			wantErr: domain.ErrInvalidUpdate,
+		},
+		{
+			name: "primitive array matches",
+			args: tools.JSONUpdateArgs{
+				Path:      "/tmp/tags.json",
+				ArrayPath: []string{"tags"},
+				Filters:   []tools.Filter{{Field: "$", Op: "eq", Value: "a"}},
+				Set:       map[string]any{"reviewed": true},
+				DryRun:    true,
+			},
+			wantMatched: 2,
+			wantIndices: []int{0, 2},
+		},
+		{
+			name: "set on a primitive element",
+			args: tools.JSONUpdateArgs{
+				Path:      "/tmp/tags.json",
+				ArrayPath: []string{"tags"},
+				Filters:   []tools.Filter{{Field: "$", Op: "eq", Value: "a"}},
+				Set:       map[string]any{"reviewed": true},
+			},
+			wantErr: domain.ErrInvalidUpdate,
+		},
+		{
+			name: "target is not an array",
+			args: tools.JSONUpdateArgs{
+				Path: "/tmp/registry.json",
+				All:  true,
+				Set:  map[string]any{"reviewed": true},
+			},
+			wantErr: domain.ErrNotAnArray,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memReader := reader.NewInMemoryFileReader()
+			memReader.Files = map[string]string{"/tmp/registry.json": registryJSON, "/tmp/tags.json": tagsJSON}
+			tools.SetFileReader(memReader)
+			memWriter := writer.NewInMemoryFileWriter()
+			tools.SetFileWriter(memWriter)
+
+			_, output, err := tools.JSONUpdateHandler(
+				context.Background(),
+				&mcp.CallToolRequest{},
+				tt.args,
+			)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("JSONUpdateHandler() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if len(memWriter.Files) != 0 {
+					t.Errorf("JSONUpdateHandler() wrote files on error: %v", memWriter.Files)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("JSONUpdateHandler() unexpected error = %v", err)
+				return
+			}
+
+			if output.Matched != tt.wantMatched {
+				t.Errorf("JSONUpdateHandler() matched = %v, want %v", output.Matched, tt.wantMatched)
+			}
+			if output.Updated != tt.wantUpdated {
+				t.Errorf("JSONUpdateHandler() updated = %v, want %v", output.Updated, tt.wantUpdated)
+			}
+			if output.Inserted != tt.wantInserted {
+				t.Errorf("JSONUpdateHandler() inserted = %v, want %v", output.Inserted, tt.wantInserted)
+			}
+			if !reflect.DeepEqual(output.Indices, tt.wantIndices) {
+				t.Errorf("JSONUpdateHandler() indices = %v, want %v", output.Indices, tt.wantIndices)
+			}
+			if tt.args.DryRun && len(output.Matches) != tt.wantMatched {
+				t.Errorf("JSONUpdateHandler() dry run matches = %v, want %d", output.Matches, tt.wantMatched)
+			}
+
+			got, written := memWriter.Files[output.Path]
+			if tt.wantContent == "" {
+				if written {
+					t.Errorf("JSONUpdateHandler() wrote %q, want no write", got)
+				}
+				return
+			}
+			if got != tt.wantContent {
+				t.Errorf("JSONUpdateHandler() written content = %s, want %s", got, tt.wantContent)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
+	"github.com/robertbagge/markdown-writer-mcp/internal/schema"
+)
+
+// JSONValidateTool defines the json_validate tool metadata
+var JSONValidateTool = &mcp.Tool{
+	Name:        "json_validate",
+	Description: "Validate a JSON file against a JSON Schema (draft 2020-12) and report every violation with its instance pointer. The schema comes from schemaPath, the document's local $schema, or the server's schema mappings",
+}
+
+// JSONValidateArgs defines the input parameters for the json_validate tool
+type JSONValidateArgs struct {
+	Path       string `json:"path" jsonschema:"Absolute or relative path to the JSON file to validate"`
+	SchemaPath string `json:"schemaPath,omitempty" jsonschema:"Optional path to the JSON Schema file; overrides $schema and configured mappings"`
+}
+
+// JSONValidateOutput defines the output structure for the json_validate tool
+type JSONValidateOutput struct {
+	Path       string             `json:"path"`
+	Schema     string             `json:"schema"`
+	Valid      bool               `json:"valid"`
+	Violations []schema.Violation `json:"violations"`
+}
+
+// schemaValidator is injected via SetSchemaValidator (DIP - dependency injection)
+var schemaValidator schema.Validator
+
+// SetSchemaValidator injects the JSON Schema validator implementation.
+// This follows the Dependency Inversion Principle.
+func SetSchemaValidator(v schema.Validator) {
+	schemaValidator = v
+}
+
+// JSONValidateHandler handles the json_validate tool invocation
+func JSONValidateHandler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args JSONValidateArgs,
+) (*mcp.CallToolResult, JSONValidateOutput, error) {
+	// Resolve path (validates and converts to absolute)
+	absPath, err := pathutil.Resolve(args.Path)
+	if err != nil {
+		return nil, JSONValidateOutput{}, err
+	}
+	if err := authorize(JSONValidateTool, policy.Read, absPath); err != nil {
+		return nil, JSONValidateOutput{}, err
+	}
+
+	schemaPath, err := resolveSchemaPath(JSONValidateTool, args.SchemaPath)
+	if err != nil {
+		return nil, JSONValidateOutput{}, err
+	}
+
+	slog.Info("json_validate tool called",
+		slog.String("path", absPath),
+		slog.String("schemaPath", schemaPath),
+	)
+
+	content, err := fileReader.Read(ctx, absPath)
+	if err != nil {
+		return nil, JSONValidateOutput{}, err
+	}
+	doc, err := parseFileDocument(absPath, content)
+	if err != nil {
+		return nil, JSONValidateOutput{}, err
+	}
+
+	if schemaValidator == nil {
+		return nil, JSONValidateOutput{}, domain.ErrNoSchema
+	}
+	res, err := schemaValidator.Validate(ctx, absPath, doc, schemaPath, schemaGuard(JSONValidateTool))
+	if err != nil {
+		return nil, JSONValidateOutput{}, err
+	}
+	if res.SchemaPath == "" {
+		return nil, JSONValidateOutput{}, fmt.Errorf("%w: %s", domain.ErrNoSchema, absPath)
+	}
+
+	output := JSONValidateOutput{
+		Path:       absPath,
+		Schema:     res.SchemaPath,
+		Valid:      len(res.Violations) == 0,
+		Violations: res.Violations,
+	}
+
+	message := fmt.Sprintf("%s is valid against %s", absPath, res.SchemaPath)
+	if !output.Valid {
+		message = fmt.Sprintf("%s has %d violations against %s:\n%s",
+			absPath, len(res.Violations), res.SchemaPath, formatViolations(res.Violations, "\n"))
+	}
+
+	return textResult(message), output, nil
+}
+
+// resolveSchemaPath resolves an optional schemaPath argument, which tool
+// must be allowed to read
+func resolveSchemaPath(tool *mcp.Tool, schemaPath string) (string, error) {
+	if schemaPath == "" {
+		return "", nil
+	}
+	absPath, err := pathutil.Resolve(schemaPath)
+	if err != nil {
+		return "", err
+	}
+	if err := authorize(tool, policy.Read, absPath); err != nil {
+		return "", err
+	}
+	return absPath, nil
+}
+
+// schemaGuard resolves the schema files the validator reads for tool, which
+// must be allowed to read them, as for any other path.
+func schemaGuard(tool *mcp.Tool) schema.PathGuard {
+	return func(path string) (string, error) {
+		return resolveSchemaPath(tool, path)
+	}
+}
+
+// validateAgainstSchema validates a document that tool is about to write and
+// returns the schema that applied, or "" when none did. Violations are
+// reported as an ErrSchemaViolation error.
+func validateAgainstSchema(ctx context.Context, tool *mcp.Tool, absPath string, doc any, schemaPath string) (string, error) {
+	if schemaValidator == nil {
+		return "", nil
+	}
+	res, err := schemaValidator.Validate(ctx, absPath, doc, schemaPath, schemaGuard(tool))
+	if err != nil {
+		return "", err
+	}
+	if len(res.Violations) > 0 {
+		return "", fmt.Errorf("%w: %s: %s", domain.ErrSchemaViolation, res.SchemaPath, formatViolations(res.Violations, "; "))
+	}
+	return res.SchemaPath, nil
+}
+
+// formatViolations renders violations as "pointer: message" lines
+func formatViolations(violations []schema.Violation, sep string) string {
+	parts := make([]string, len(violations))
+	for i, v := range violations {
+		pointer := v.Pointer
+		if pointer == "" {
+			pointer = "(root)"
+		}
+		parts[i] = fmt.Sprintf("%s: %s", pointer, v.Message)
+	}
+	return strings.Join(parts, sep)
+}
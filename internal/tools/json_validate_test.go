@@ -0,0 +1,279 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/config"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
+	"github.com/robertbagge/markdown-writer-mcp/internal/schema"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tools"
+	"github.com/robertbagge/markdown-writer-mcp/internal/writer"
+)
+
+const serviceSchema = `{
+  "type": "object",
+  "required": ["name", "port"],
+  "properties": {
+    "name": {"type": "string"},
+    "port": {"type": "integer", "maximum": 65535}
+  }
+}`
+
+// setupSchemaValidator installs a validator whose schemas and documents are
+// served from memReader, with services/*.json mapped to the service schema.
+func setupSchemaValidator(t *testing.T, memReader *reader.InMemoryFileReader) {
+	t.Helper()
+	memReader.Files["/schemas/service.json"] = serviceSchema
+	tools.SetFileReader(memReader)
+	tools.SetSchemaValidator(schema.NewFileValidator(memReader, []config.SchemaMapping{
+		{Pattern: "/tmp/services/*.json", Schema: "/schemas/service.json"},
+	}))
+	t.Cleanup(func() { tools.SetSchemaValidator(nil) })
+}
+
+func TestJSONValidateHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          map[string]string
+		args           tools.JSONValidateArgs
+		wantErr        error
+		wantSchema     string
+		wantValid      bool
+		wantViolations []schema.Violation
+	}{
+		{
+			name:           "valid via config mapping",
+			files:          map[string]string{"/tmp/services/api.json": `{"name": "api", "port": 8080}`},
+			args:           tools.JSONValidateArgs{Path: "/tmp/services/api.json"},
+			wantSchema:     "/schemas/service.json",
+			wantValid:      true,
+			wantViolations: []schema.Violation{},
+		},
+		{
+			name:       "violations via explicit schema",
+			files:      map[string]string{"/tmp/other.json": `{"name": 1, "port": 70000}`},
+			args:       tools.JSONValidateArgs{Path: "/tmp/other.json", SchemaPath: "/schemas/service.json"},
+			wantSchema: "/schemas/service.json",
+			wantValid:  false,
+			wantViolations: []schema.Violation{
+				{Pointer: "/name", Message: "got number, want string"},
+				{Pointer: "/port", Message: "maximum: got 70,000, want 65,535"},
+			},
+		},
+		{
+			name:       "violations via document $schema",
+			files:      map[string]string{"/tmp/local.json": `{"$schema": "../schemas/service.json", "name": "db"}`},
+			args:       tools.JSONValidateArgs{Path: "/tmp/local.json"},
+			wantSchema: "/schemas/service.json",
+			wantValid:  false,
+			wantViolations: []schema.Violation{
+				{Pointer: "", Message: "missing property 'port'"},
+			},
+		},
+		{
+			name:    "no schema applies",
+			files:   map[string]string{"/tmp/free.json": `{}`},
+			args:    tools.JSONValidateArgs{Path: "/tmp/free.json"},
+			wantErr: domain.ErrNoSchema,
+		},
+		{
+			name:    "invalid JSON",
+			files:   map[string]string{"/tmp/services/broken.json": `{"name": `},
+			args:    tools.JSONValidateArgs{Path: "/tmp/services/broken.json"},
+			wantErr: domain.ErrInvalidJSON,
+		},
+		{
+			name:    "file not found",
+			files:   map[string]string{},
+			args:    tools.JSONValidateArgs{Path: "/tmp/services/missing.json"},
+			wantErr: domain.ErrFileNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memReader := reader.NewInMemoryFileReader()
+			memReader.Files = tt.files
+			setupSchemaValidator(t, memReader)
+
+			result, output, err := tools.JSONValidateHandler(
+				context.Background(),
+				&mcp.CallToolRequest{},
+				tt.args,
+			)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("JSONValidateHandler() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("JSONValidateHandler() unexpected error = %v", err)
+				return
+			}
+
+			if result == nil {
+				t.Error("JSONValidateHandler() result is nil")
+				return
+			}
+
+			if output.Schema != tt.wantSchema {
+				t.Errorf("JSONValidateHandler() schema = %v, want %v", output.Schema, tt.wantSchema)
+			}
+			if output.Valid != tt.wantValid {
+				t.Errorf("JSONValidateHandler() valid = %v, want %v", output.Valid, tt.wantValid)
+			}
+			if !reflect.DeepEqual(output.Violations, tt.wantViolations) {
+				t.Errorf("JSONValidateHandler() violations = %#v, want %#v", output.Violations, tt.wantViolations)
+			}
+		})
+	}
+}
+
+func TestJSONWriteHandler_SchemaValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       tools.JSONWriteArgs
+		wantErr    error
+		wantSchema string
+	}{
+		{
+			name:       "mapped schema accepts valid content",
+			args:       tools.JSONWriteArgs{Path: "/tmp/services/api.json", Content: `{"name": "api", "port": 443}`},
+			wantSchema: "/schemas/service.json",
+		},
+		{
+			name:    "mapped schema rejects invalid content",
+			args:    tools.JSONWriteArgs{Path: "/tmp/services/api.json", Content: `{"name": "api", "port": "443"}`},
+			wantErr: domain.ErrSchemaViolation,
+		},
+		{
+			name:    "explicit schemaPath",
+			args:    tools.JSONWriteArgs{Path: "/tmp/unmapped.json", Content: `{"name": "api"}`, SchemaPath: "/schemas/service.json"},
+			wantErr: domain.ErrSchemaViolation,
+		},
+		{
+			name:    "missing explicit schema",
+			args:    tools.JSONWriteArgs{Path: "/tmp/unmapped.json", Content: `{}`, SchemaPath: "/schemas/missing.json"},
+			wantErr: domain.ErrInvalidSchema,
+		},
+		{
+			name: "no schema applies",
+			args: tools.JSONWriteArgs{Path: "/tmp/unmapped.json", Content: `{"anything": true}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupSchemaValidator(t, reader.NewInMemoryFileReader())
+			memWriter := writer.NewInMemoryFileWriter()
+			tools.SetFileWriter(memWriter)
+
+			_, output, err := tools.JSONWriteHandler(
+				context.Background(),
+				&mcp.CallToolRequest{},
+				tt.args,
+			)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("JSONWriteHandler() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if len(memWriter.Files) != 0 {
+					t.Errorf("JSONWriteHandler() wrote files on error: %v", memWriter.Files)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("JSONWriteHandler() unexpected error = %v", err)
+				return
+			}
+
+			if output.Schema != tt.wantSchema {
+				t.Errorf("JSONWriteHandler() schema = %v, want %v", output.Schema, tt.wantSchema)
+			}
+		})
+	}
+}
+
+func TestJSONPatchHandler_SchemaValidation(t *testing.T) {
+	memReader := reader.NewInMemoryFileReader()
+	memReader.Files["/tmp/services/api.json"] = `{"name": "api", "port": 8080}`
+	setupSchemaValidator(t, memReader)
+	memWriter := writer.NewInMemoryFileWriter()
+	tools.SetFileWriter(memWriter)
+
+	_, _, err := tools.JSONPatchHandler(
+		context.Background(),
+		&mcp.CallToolRequest{},
+		tools.JSONPatchArgs{
+			Path:  "/tmp/services/api.json",
+			Patch: []tools.PatchOperation{{Op: "remove", Path: "/port"}},
+		},
+	)
+
+	if !errors.Is(err, domain.ErrSchemaViolation) {
+		t.Errorf("JSONPatchHandler() error = %v, wantErr %v", err, domain.ErrSchemaViolation)
+	}
+	if len(memWriter.Files) != 0 {
+		t.Errorf("JSONPatchHandler() wrote files on error: %v", memWriter.Files)
+	}
+}
+
+func TestJSONValidateHandler_SchemaOutsideWorkspace(t *testing.T) {
+	workspace := t.TempDir()
+	if err := pathutil.SetRoots([]string{workspace}); err != nil {
+		t.Fatalf("SetRoots() unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = pathutil.SetRoots(nil) })
+
+	docPath := filepath.Join(workspace, "doc.json")
+	inside := filepath.Join(workspace, "schema.json")
+	tests := []struct {
+		name string
+		doc  string
+		args tools.JSONValidateArgs
+	}{
+		{
+			name: "explicit schema path",
+			doc:  `{}`,
+			args: tools.JSONValidateArgs{Path: docPath, SchemaPath: "/schemas/service.json"},
+		},
+		{
+			name: "document $schema",
+			doc:  `{"$schema": "/schemas/service.json"}`,
+			args: tools.JSONValidateArgs{Path: docPath},
+		},
+		{
+			name: "$ref from a workspace schema",
+			doc:  `{}`,
+			args: tools.JSONValidateArgs{Path: docPath, SchemaPath: inside},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memReader := reader.NewInMemoryFileReader()
+			memReader.Files = map[string]string{
+				docPath: tt.doc,
+				inside:  `{"$ref": "file:///schemas/service.json"}`,
+			}
+			setupSchemaValidator(t, memReader)
+
+			_, _, err := tools.JSONValidateHandler(context.Background(), &mcp.CallToolRequest{}, tt.args)
+			if !errors.Is(err, domain.ErrOutsideWorkspace) {
+				t.Errorf("JSONValidateHandler() error = %v, wantErr %v", err, domain.ErrOutsideWorkspace)
+			}
+		})
+	}
+}
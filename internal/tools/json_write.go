@@ -3,30 +3,48 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonc"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonfmt"
 	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
-	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tomldoc"
+	"github.com/robertbagge/markdown-writer-mcp/internal/yamldoc"
 )
 
 // JSONWriteTool defines the json_write tool metadata
 var JSONWriteTool = &mcp.Tool{
 	Name:        "json_write",
-	Description: "Write JSON content to a file path with validation and atomic writes",
+	Description: "Write JSON content to a file path with validation and atomic writes. YAML and TOML content is accepted for .yaml, .yml and .toml files",
 }
 
 // JSONWriteArgs defines the input parameters for the json_write tool
 type JSONWriteArgs struct {
-	Path    string `json:"path" jsonschema:"Absolute or relative path to the JSON file to write"`
-	Content string `json:"content" jsonschema:"JSON content to write to the file (must be valid JSON)"`
+	Path       string `json:"path" jsonschema:"Absolute or relative path to the JSON file to write"`
+	Content    string `json:"content" jsonschema:"JSON content to write to the file (must be valid JSON)"`
+	SchemaPath string `json:"schemaPath,omitempty" jsonschema:"Optional path to a JSON Schema the content must satisfy; overrides $schema and configured mappings"`
+	Style      string `json:"style,omitempty" jsonschema:"Output style: raw (default, write content as given), pretty, compact, canonical (RFC 8785) or preserve (keep the existing file's indentation and key order)"`
+	Indent     string `json:"indent,omitempty" jsonschema:"Indentation for the pretty style: a number of spaces such as 2 or 4, or tab (default 2)"`
+	SortKeys   bool   `json:"sortKeys,omitempty" jsonschema:"Sort object keys in the pretty, compact and preserve styles"`
+	Format     string `json:"format,omitempty" jsonschema:"Content format: json, jsonc (comments, trailing commas and JSON5 syntax), yaml or toml. Defaults to yaml for .yaml/.yml files, toml for .toml files and json otherwise. Non-JSON content is written as given"`
+	Mode       string `json:"mode,omitempty" jsonschema:"Write mode: create (fail if the file exists), overwrite (fail if it does not exist) or upsert (create or replace). Defaults to the server's default mode, upsert unless configured otherwise"`
+	Overwrite  bool   `json:"overwrite,omitempty" jsonschema:"Allow replacing an existing file when the server's default mode is create"`
 }
 
 // JSONWriteOutput defines the output structure for the json_write tool
 type JSONWriteOutput struct {
-	Path string `json:"path"`
-	Size int64  `json:"size"`
+	Path     string   `json:"path"`
+	Size     int64    `json:"size"`
+	Schema   string   `json:"schema,omitempty"`
+	Replaced bool     `json:"replaced"`
+	Secrets  []string `json:"secrets,omitempty"`
 }
 
 // JSONWriteHandler handles the json_write tool invocation
@@ -40,29 +58,68 @@ func JSONWriteHandler(
 	if err != nil {
 		return nil, JSONWriteOutput{}, err
 	}
+	if err := authorize(JSONWriteTool, policy.Write, absPath); err != nil {
+		return nil, JSONWriteOutput{}, err
+	}
+	if err := checkTextContent(args.Content); err != nil {
+		return nil, JSONWriteOutput{}, err
+	}
+	mode, err := writeMode(args.Mode, args.Overwrite)
+	if err != nil {
+		return nil, JSONWriteOutput{}, err
+	}
 
-	// Validate that content is valid JSON
-	if !json.Valid([]byte(args.Content)) {
-		return nil, JSONWriteOutput{}, domain.ErrInvalidJSON
+	// Validate that content is valid in its format
+	doc, err := parseWriteContent(absPath, args)
+	if err != nil {
+		return nil, JSONWriteOutput{}, err
+	}
+
+	// Validate against the applicable JSON Schema, if any
+	schemaPath, err := resolveSchemaPath(JSONWriteTool, args.SchemaPath)
+	if err != nil {
+		return nil, JSONWriteOutput{}, err
+	}
+	appliedSchema, err := validateAgainstSchema(ctx, JSONWriteTool, absPath, doc, schemaPath)
+	if err != nil {
+		return nil, JSONWriteOutput{}, err
 	}
 
 	slog.Info("json_write tool called",
 		slog.String("path", absPath),
 		slog.Int("content_length", len(args.Content)),
+		slog.String("style", args.Style),
+		slog.String("mode", string(mode)),
 	)
 
+	unlock := lockPath(absPath)
+	defer unlock()
+
+	content, err := formatContent(ctx, absPath, doc, args)
+	if err != nil {
+		return nil, JSONWriteOutput{}, err
+	}
+
+	content, notes, err := guardSecrets(JSONWriteTool, absPath, replacedContent(ctx, absPath), content)
+	if err != nil {
+		return nil, JSONWriteOutput{}, err
+	}
+
 	// Write file using the existing fileWriter (reused from write.go)
-	size, err := fileWriter.Write(ctx, absPath, args.Content)
+	size, replaced, err := fileWriter.WriteMode(ctx, absPath, content, mode)
 	if err != nil {
 		return nil, JSONWriteOutput{}, err
 	}
 
 	output := JSONWriteOutput{
-		Path: absPath,
-		Size: size,
+		Path:     absPath,
+		Size:     size,
+		Schema:   appliedSchema,
+		Replaced: replaced,
+		Secrets:  notes,
 	}
 
-	message := fmt.Sprintf("Successfully wrote %d bytes of JSON to %s", size, absPath)
+	message := withSecretNotes(writtenMessage(size, " of JSON", absPath, replaced), notes)
 	result := &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: message},
@@ -71,3 +128,92 @@ func JSONWriteHandler(
 
 	return result, output, nil
 }
+
+// parseWriteContent decodes the content to write according to its format,
+// which defaults to the one of the file extension. Content in a format other
+// than JSON is written as given, so it cannot be restyled.
+func parseWriteContent(path string, args JSONWriteArgs) (any, error) {
+	format := args.Format
+	if format == "" {
+		if format = documentFormat(path); format == formatJSONC {
+			format = formatJSON
+		}
+	}
+	if format != formatJSON && args.Style != "" && args.Style != "raw" {
+		return nil, fmt.Errorf("%w: style %q cannot be used with the %s format, which is written as given", domain.ErrInvalidFormat, args.Style, format)
+	}
+
+	switch format {
+	case formatJSON:
+		if !json.Valid([]byte(args.Content)) {
+			return nil, domain.ErrInvalidJSON
+		}
+		return parseJSONDocument(args.Content)
+
+	case formatJSONC:
+		return depthChecked(jsonc.Decode([]byte(args.Content)))
+	case formatYAML:
+		return depthChecked(yamldoc.Decode([]byte(args.Content)))
+	case formatTOML:
+		return depthChecked(tomldoc.Decode([]byte(args.Content)))
+	}
+	return nil, fmt.Errorf("%w: unknown format %q", domain.ErrInvalidFormat, args.Format)
+}
+
+// formatContent lays out the document in the requested style. The raw style
+// returns the content exactly as given.
+func formatContent(ctx context.Context, path string, doc any, args JSONWriteArgs) (string, error) {
+	var contentOrder []*jsonfmt.KeyOrder
+	if order, err := jsonfmt.ParseKeyOrder(args.Content); err == nil {
+		contentOrder = []*jsonfmt.KeyOrder{order}
+	}
+
+	var opts jsonfmt.Options
+	switch args.Style {
+	case "", "raw":
+		return args.Content, nil
+
+	case jsonfmt.StylePretty:
+		indent, err := parseIndent(args.Indent)
+		if err != nil {
+			return "", err
+		}
+		opts = jsonfmt.Options{Style: jsonfmt.StylePretty, Indent: indent, TrailingNewline: true, Order: contentOrder}
+
+	case jsonfmt.StyleCompact:
+		opts = jsonfmt.Options{Style: jsonfmt.StyleCompact, Order: contentOrder}
+
+	case jsonfmt.StyleCanonical:
+		opts = jsonfmt.Options{Style: jsonfmt.StyleCanonical}
+
+	case "preserve":
+		// Follow the existing file; a new file falls back to the pretty style
+		existing, err := fileReader.Read(ctx, path)
+		if err != nil && !errors.Is(err, domain.ErrFileNotFound) {
+			return "", err
+		}
+		opts = jsonfmt.DetectOptions(existing)
+		opts.Order = append(opts.Order, contentOrder...)
+
+	default:
+		return "", fmt.Errorf("%w: unknown style %q", domain.ErrInvalidFormat, args.Style)
+	}
+
+	opts.SortKeys = args.SortKeys
+	return jsonfmt.Encode(doc, opts)
+}
+
+// parseIndent converts the indent argument into an indentation unit.
+func parseIndent(indent string) (string, error) {
+	switch indent {
+	case "":
+		return "  ", nil
+	case "tab":
+		return "\t", nil
+	}
+	n, err := strconv.Atoi(indent)
+	if err != nil || n < 0 || n > 8 {
+		return "", fmt.Errorf("%w: indent must be 0-8 spaces or \"tab\", got %q", domain.ErrInvalidFormat, indent)
+	}
+	return strings.Repeat(" ", n), nil
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
 	"github.com/robertbagge/markdown-writer-mcp/internal/tools"
 	"github.com/robertbagge/markdown-writer-mcp/internal/writer"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -136,3 +137,231 @@ func TestJSONWriteHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONWriteHandlerStyles(t *testing.T) {
+	existing := "{\n\t\"name\": \"old\",\n\t\"deps\": {\n\t\t\"b\": 1,\n\t\t\"a\": 2\n\t}\n}\n"
+
+	tests := []struct {
+		name        string
+		args        tools.JSONWriteArgs
+		wantErr     error
+		wantContent string
+	}{
+		{
+			name:        "pretty keeps content key order",
+			args:        tools.JSONWriteArgs{Path: "/tmp/new.json", Content: `{"b": 1, "a": [1, 2]}`, Style: "pretty"},
+			wantContent: "{\n  \"b\": 1,\n  \"a\": [\n    1,\n    2\n  ]\n}\n",
+		},
+		{
+			name:        "pretty with indent and sorted keys",
+			args:        tools.JSONWriteArgs{Path: "/tmp/new.json", Content: `{"b": 1, "a": {}}`, Style: "pretty", Indent: "4", SortKeys: true},
+			wantContent: "{\n    \"a\": {},\n    \"b\": 1\n}\n",
+		},
+		{
+			name:        "compact",
+			args:        tools.JSONWriteArgs{Path: "/tmp/new.json", Content: "{\n  \"b\": 1,\n  \"a\": [1, 2]\n}", Style: "compact"},
+			wantContent: `{"b":1,"a":[1,2]}`,
+		},
+		{
+			name:        "canonical",
+			args:        tools.JSONWriteArgs{Path: "/tmp/new.json", Content: `{"b": 1.50, "a": "é"}`, Style: "canonical"},
+			wantContent: `{"a":"é","b":1.5}`,
+		},
+		{
+			name: "preserve follows the existing file",
+			args: tools.JSONWriteArgs{
+				Path:    "/tmp/existing.json",
+				Content: `{"deps": {"a": 3, "c": 4, "b": 1}, "version": 2, "name": "new"}`,
+				Style:   "preserve",
+			},
+			wantContent: "{\n\t\"name\": \"new\",\n\t\"deps\": {\n\t\t\"b\": 1,\n\t\t\"a\": 3,\n\t\t\"c\": 4\n\t},\n\t\"version\": 2\n}\n",
+		},
+		{
+			name:        "preserve on a new file is pretty",
+			args:        tools.JSONWriteArgs{Path: "/tmp/new.json", Content: `{"b": 1, "a": 2}`, Style: "preserve"},
+			wantContent: "{\n  \"b\": 1,\n  \"a\": 2\n}\n",
+		},
+		{
+			name:        "jsonc written as given",
+			args:        tools.JSONWriteArgs{Path: "/tmp/new.jsonc", Content: "{\n  // comment\n  \"a\": 1,\n}\n", Format: "jsonc"},
+			wantContent: "{\n  // comment\n  \"a\": 1,\n}\n",
+		},
+		{
+			name:    "comments need the jsonc format",
+			args:    tools.JSONWriteArgs{Path: "/tmp/new.json", Content: "{\n  // comment\n  \"a\": 1\n}"},
+			wantErr: domain.ErrInvalidJSON,
+		},
+		{
+			name:    "invalid jsonc",
+			args:    tools.JSONWriteArgs{Path: "/tmp/new.jsonc", Content: "{\"a\": 1 /* open", Format: "jsonc"},
+			wantErr: domain.ErrInvalidJSON,
+		},
+		{
+			name:    "jsonc cannot be restyled",
+			args:    tools.JSONWriteArgs{Path: "/tmp/new.jsonc", Content: "{}", Format: "jsonc", Style: "pretty"},
+			wantErr: domain.ErrInvalidFormat,
+		},
+		{
+			name:        "yaml detected from the extension",
+			args:        tools.JSONWriteArgs{Path: "/tmp/new.yaml", Content: "# app\nname: app\nports: [80]\n"},
+			wantContent: "# app\nname: app\nports: [80]\n",
+		},
+		{
+			name:    "invalid yaml",
+			args:    tools.JSONWriteArgs{Path: "/tmp/new.yml", Content: "name: [app\n"},
+			wantErr: domain.ErrInvalidYAML,
+		},
+		{
+			name:        "toml detected from the extension",
+			args:        tools.JSONWriteArgs{Path: "/tmp/new.toml", Content: "[server]\nport = 8080\n"},
+			wantContent: "[server]\nport = 8080\n",
+		},
+		{
+			name:    "invalid toml",
+			args:    tools.JSONWriteArgs{Path: "/tmp/new.toml", Content: "[server\n"},
+			wantErr: domain.ErrInvalidTOML,
+		},
+		{
+			name:    "yaml cannot be restyled",
+			args:    tools.JSONWriteArgs{Path: "/tmp/new.yaml", Content: "a: 1\n", Style: "compact"},
+			wantErr: domain.ErrInvalidFormat,
+		},
+		{
+			name:    "unknown format",
+			args:    tools.JSONWriteArgs{Path: "/tmp/new.json", Content: "{}", Format: "xml"},
+			wantErr: domain.ErrInvalidFormat,
+		},
+		{
+			name:    "unknown style",
+			args:    tools.JSONWriteArgs{Path: "/tmp/new.json", Content: `{}`, Style: "fancy"},
+			wantErr: domain.ErrInvalidFormat,
+		},
+		{
+			name:    "invalid indent",
+			args:    tools.JSONWriteArgs{Path: "/tmp/new.json", Content: `{}`, Style: "pretty", Indent: "wide"},
+			wantErr: domain.ErrInvalidFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memReader := reader.NewInMemoryFileReader()
+			memReader.Files = map[string]string{"/tmp/existing.json": existing}
+			tools.SetFileReader(memReader)
+			memWriter := writer.NewInMemoryFileWriter()
+			tools.SetFileWriter(memWriter)
+
+			_, output, err := tools.JSONWriteHandler(context.Background(), &mcp.CallToolRequest{}, tt.args)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("JSONWriteHandler() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if len(memWriter.Files) != 0 {
+					t.Errorf("JSONWriteHandler() wrote files on error: %v", memWriter.Files)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("JSONWriteHandler() unexpected error = %v", err)
+			}
+
+			if got := memWriter.Files[output.Path]; got != tt.wantContent {
+				t.Errorf("JSONWriteHandler() written content = %q, want %q", got, tt.wantContent)
+			}
+			if output.Size != int64(len(tt.wantContent)) {
+				t.Errorf("JSONWriteHandler() size = %v, want %v", output.Size, len(tt.wantContent))
+			}
+		})
+	}
+}
+
+func TestJSONWriteHandlerModes(t *testing.T) {
+	memWriter := writer.NewInMemoryFileWriter()
+	tools.SetFileWriter(memWriter)
+	t.Cleanup(func() { tools.SetDefaultWriteMode(writer.ModeUpsert) })
+
+	tests := []struct {
+		name         string
+		defaultMode  writer.Mode
+		args         tools.JSONWriteArgs
+		wantErr      error
+		wantReplaced bool
+		wantContent  string
+	}{
+		{
+			name:         "upsert by default replaces",
+			args:         tools.JSONWriteArgs{Path: "/tmp/existing.json", Content: `{"new": true}`},
+			wantReplaced: true,
+			wantContent:  `{"new": true}`,
+		},
+		{
+			name:        "create a new file",
+			args:        tools.JSONWriteArgs{Path: "/tmp/new.json", Content: `{"new": true}`, Mode: "create"},
+			wantContent: `{"new": true}`,
+		},
+		{
+			name:        "create refuses an existing file",
+			args:        tools.JSONWriteArgs{Path: "/tmp/existing.json", Content: `{"new": true}`, Mode: "create"},
+			wantErr:     domain.ErrFileExists,
+			wantContent: `{"old": true}`,
+		},
+		{
+			name:    "overwrite needs an existing file",
+			args:    tools.JSONWriteArgs{Path: "/tmp/new.json", Content: `{"new": true}`, Mode: "overwrite"},
+			wantErr: domain.ErrFileNotFound,
+		},
+		{
+			name:    "unknown mode",
+			args:    tools.JSONWriteArgs{Path: "/tmp/new.json", Content: `{}`, Mode: "replace"},
+			wantErr: domain.ErrInvalidWriteMode,
+		},
+		{
+			name:        "create default refuses an existing file",
+			defaultMode: writer.ModeCreate,
+			args:        tools.JSONWriteArgs{Path: "/tmp/existing.json", Content: `{"new": true}`},
+			wantErr:     domain.ErrFileExists,
+			wantContent: `{"old": true}`,
+		},
+		{
+			name:         "create default replaces with overwrite",
+			defaultMode:  writer.ModeCreate,
+			args:         tools.JSONWriteArgs{Path: "/tmp/existing.json", Content: `{"new": true}`, Overwrite: true},
+			wantReplaced: true,
+			wantContent:  `{"new": true}`,
+		},
+		{
+			name:        "create default needs overwrite for another mode",
+			defaultMode: writer.ModeCreate,
+			args:        tools.JSONWriteArgs{Path: "/tmp/existing.json", Content: `{"new": true}`, Mode: "upsert"},
+			wantErr:     domain.ErrInvalidWriteMode,
+			wantContent: `{"old": true}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memWriter.Files = map[string]string{"/tmp/existing.json": `{"old": true}`}
+			if tt.defaultMode != "" {
+				tools.SetDefaultWriteMode(tt.defaultMode)
+			} else {
+				tools.SetDefaultWriteMode(writer.ModeUpsert)
+			}
+
+			_, output, err := tools.JSONWriteHandler(context.Background(), &mcp.CallToolRequest{}, tt.args)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("JSONWriteHandler() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("JSONWriteHandler() unexpected error = %v", err)
+			}
+
+			if output.Replaced != tt.wantReplaced {
+				t.Errorf("JSONWriteHandler() replaced = %v, want %v", output.Replaced, tt.wantReplaced)
+			}
+			if got := memWriter.Files[tt.args.Path]; got != tt.wantContent {
+				t.Errorf("JSONWriteHandler() content = %q, want %q", got, tt.wantContent)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonfmt"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
+	"github.com/robertbagge/markdown-writer-mcp/internal/writer"
+)
+
+// JSONLAppendTool defines the jsonl_append tool metadata
+var JSONLAppendTool = &mcp.Tool{
+	Name:        "jsonl_append",
+	Description: "Append records to a JSON Lines (NDJSON) file, one compact JSON value per line, without rewriting the file",
+}
+
+// JSONLAppendArgs defines the input parameters for the jsonl_append tool
+type JSONLAppendArgs struct {
+	Path    string `json:"path" jsonschema:"Absolute or relative path to the JSON Lines file"`
+	Records []any  `json:"records" jsonschema:"Records to append, one per line, in order"`
+	Create  bool   `json:"create,omitempty" jsonschema:"Create the file if it does not exist"`
+}
+
+// JSONLAppendOutput defines the output structure for the jsonl_append tool
+type JSONLAppendOutput struct {
+	Path     string   `json:"path"`
+	Appended int      `json:"appended"`
+	Bytes    int64    `json:"bytes"`
+	Secrets  []string `json:"secrets,omitempty"`
+}
+
+// lineAppender is injected via SetLineAppender (DIP - dependency injection)
+var lineAppender writer.LineAppender
+
+// SetLineAppender injects the line appender implementation.
+// This follows the Dependency Inversion Principle.
+func SetLineAppender(a writer.LineAppender) {
+	lineAppender = a
+}
+
+// JSONLAppendHandler handles the jsonl_append tool invocation
+func JSONLAppendHandler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args JSONLAppendArgs,
+) (*mcp.CallToolResult, JSONLAppendOutput, error) {
+	// Resolve path (validates and converts to absolute)
+	absPath, err := pathutil.Resolve(args.Path)
+	if err != nil {
+		return nil, JSONLAppendOutput{}, err
+	}
+	if err := authorize(JSONLAppendTool, policy.Write, absPath); err != nil {
+		return nil, JSONLAppendOutput{}, err
+	}
+
+	if len(args.Records) == 0 {
+		return nil, JSONLAppendOutput{}, fmt.Errorf("%w: records must contain at least one element", domain.ErrInvalidJSON)
+	}
+
+	// Encode every record before touching the file
+	lines := make([]string, len(args.Records))
+	for i, record := range args.Records {
+		line, err := jsonfmt.Encode(record, jsonfmt.Options{Style: jsonfmt.StyleCompact})
+		if err != nil {
+			return nil, JSONLAppendOutput{}, fmt.Errorf("record %d: %w", i, err)
+		}
+		lines[i] = line
+	}
+
+	slog.Info("jsonl_append tool called",
+		slog.String("path", absPath),
+		slog.Int("recordCount", len(args.Records)),
+	)
+
+	lines, notes, err := guardSecretLines(JSONLAppendTool, absPath, lines)
+	if err != nil {
+		return nil, JSONLAppendOutput{}, err
+	}
+
+	unlock := lockPath(absPath)
+	defer unlock()
+
+	n, err := lineAppender.AppendLines(ctx, absPath, lines, args.Create)
+	if err != nil {
+		return nil, JSONLAppendOutput{}, err
+	}
+
+	output := JSONLAppendOutput{
+		Path:     absPath,
+		Appended: len(lines),
+		Bytes:    n,
+		Secrets:  notes,
+	}
+
+	message := fmt.Sprintf("Successfully appended %d records (%d bytes) to %s", output.Appended, n, absPath)
+	return textResult(withSecretNotes(message, notes)), output, nil
+}
@@ -0,0 +1,81 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tools"
+	"github.com/robertbagge/markdown-writer-mcp/internal/writer"
+)
+
+func TestJSONLAppendHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		args        tools.JSONLAppendArgs
+		wantErr     error
+		wantContent string
+	}{
+		{
+			name:  "append records as compact lines",
+			files: map[string]string{"/tmp/events.jsonl": "{\"id\":1}\n"},
+			args: tools.JSONLAppendArgs{
+				Path:    "/tmp/events.jsonl",
+				Records: []any{map[string]any{"id": 2, "msg": "a<b"}, []any{1, "two"}},
+			},
+			wantContent: "{\"id\":1}\n{\"id\":2,\"msg\":\"a<b\"}\n[1,\"two\"]\n",
+		},
+		{
+			name:        "terminates a partial last line",
+			files:       map[string]string{"/tmp/events.jsonl": `{"id":1}`},
+			args:        tools.JSONLAppendArgs{Path: "/tmp/events.jsonl", Records: []any{"x"}},
+			wantContent: "{\"id\":1}\n\"x\"\n",
+		},
+		{
+			name:        "create missing file",
+			files:       map[string]string{},
+			args:        tools.JSONLAppendArgs{Path: "/tmp/new.jsonl", Records: []any{true}, Create: true},
+			wantContent: "true\n",
+		},
+		{
+			name:    "missing file without create",
+			files:   map[string]string{},
+			args:    tools.JSONLAppendArgs{Path: "/tmp/new.jsonl", Records: []any{true}},
+			wantErr: domain.ErrFileNotFound,
+		},
+		{
+			name:    "no records",
+			files:   map[string]string{},
+			args:    tools.JSONLAppendArgs{Path: "/tmp/events.jsonl"},
+			wantErr: domain.ErrInvalidJSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memWriter := writer.NewInMemoryFileWriter()
+			memWriter.Files = tt.files
+			tools.SetLineAppender(memWriter)
+
+			_, output, err := tools.JSONLAppendHandler(context.Background(), &mcp.CallToolRequest{}, tt.args)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("JSONLAppendHandler() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("JSONLAppendHandler() unexpected error = %v", err)
+			}
+			if output.Appended != len(tt.args.Records) {
+				t.Errorf("JSONLAppendHandler() appended = %v, want %v", output.Appended, len(tt.args.Records))
+			}
+			if got := memWriter.Files[output.Path]; got != tt.wantContent {
+				t.Errorf("JSONLAppendHandler() content = %q, want %q", got, tt.wantContent)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
+	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
+)
+
+// JSONLQueryTool defines the jsonl_query tool metadata
+var JSONLQueryTool = &mcp.Tool{
+	Name:        "jsonl_query",
+	Description: "Query a JSON Lines (NDJSON) file line by line with the json_query filters, reporting line numbers for matches and malformed lines",
+}
+
+// maxLineErrors caps how many malformed lines are reported individually
+const maxLineErrors = 100
+
+// JSONLQueryArgs defines the input parameters for the jsonl_query tool
+type JSONLQueryArgs struct {
+	Path    string   `json:"path" jsonschema:"Absolute or relative path to the JSON Lines file"`
+	Filters []Filter `json:"filters,omitempty" jsonschema:"Array of filter conditions (AND logic); records that are not objects match only filters on the field $, the record itself"`
+	Offset  int      `json:"offset,omitempty" jsonschema:"Number of matching records to skip"`
+	Limit   *int     `json:"limit,omitempty" jsonschema:"Maximum number of records to return; scanning stops once it is reached"`
+}
+
+// JSONLMatch is a matching record and its 1-based line number
+type JSONLMatch struct {
+	Line   int `json:"line"`
+	Record any `json:"record"`
+}
+
+// JSONLLineError reports a line that is not valid JSON
+type JSONLLineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// JSONLQueryOutput defines the output structure for the jsonl_query tool
+type JSONLQueryOutput struct {
+	Result       []JSONLMatch     `json:"result"`
+	Count        int              `json:"count"`
+	LinesScanned int              `json:"linesScanned"`
+	Truncated    bool             `json:"truncated"`
+	Errors       []JSONLLineError `json:"errors,omitempty"`
+	ErrorCount   int              `json:"errorCount"`
+}
+
+// streamReader is injected via SetStreamReader (DIP - dependency injection)
+var streamReader reader.StreamReader
+
+// SetStreamReader injects the streaming file reader implementation.
+// This follows the Dependency Inversion Principle.
+func SetStreamReader(r reader.StreamReader) {
+	streamReader = r
+}
+
+// JSONLQueryHandler handles the jsonl_query tool invocation
+func JSONLQueryHandler(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	args JSONLQueryArgs,
+) (*mcp.CallToolResult, JSONLQueryOutput, error) {
+	// Resolve path (validates and converts to absolute)
+	absPath, err := pathutil.Resolve(args.Path)
+	if err != nil {
+		return nil, JSONLQueryOutput{}, err
+	}
+	if err := authorize(JSONLQueryTool, policy.Read, absPath); err != nil {
+		return nil, JSONLQueryOutput{}, err
+	}
+
+	slog.Info("jsonl_query tool called",
+		slog.String("path", absPath),
+		slog.Int("filterCount", len(args.Filters)),
+	)
+
+	rc, err := streamReader.Open(ctx, absPath)
+	if err != nil {
+		return nil, JSONLQueryOutput{}, err
+	}
+	defer rc.Close()
+
+	output := JSONLQueryOutput{Result: []JSONLMatch{}}
+	skip := args.Offset
+	var resultBytes int64
+	err = scanJSONLines(ctx, rc, func(line int, record any, size int, lineErr error) (bool, error) {
+		output.LinesScanned = line
+		if lineErr != nil {
+			output.ErrorCount++
+			if len(output.Errors) < maxLineErrors {
+				output.Errors = append(output.Errors, JSONLLineError{Line: line, Error: lineErr.Error()})
+			}
+			return true, nil
+		}
+		if !recordMatches(record, args.Filters) {
+			return true, nil
+		}
+		if skip > 0 {
+			skip--
+			return true, nil
+		}
+		if args.Limit != nil && *args.Limit > 0 && len(output.Result) >= *args.Limit {
+			output.Truncated = true
+			return false, nil
+		}
+		resultBytes += int64(size)
+		if err := checkResultBytes(resultBytes, maxQueryResultBytes); err != nil {
+			return false, err
+		}
+		output.Result = append(output.Result, JSONLMatch{Line: line, Record: record})
+		return true, nil
+	})
+	if err != nil {
+		return nil, JSONLQueryOutput{}, err
+	}
+	output.Count = len(output.Result)
+
+	// Serialize output to JSON for MCP response
+	outputJSON, err := json.Marshal(output)
+	if err != nil {
+		return nil, JSONLQueryOutput{}, fmt.Errorf("failed to marshal output: %w", err)
+	}
+	return textResult(string(outputJSON)), output, nil
+}
+
+// scanJSONLines decodes r one line at a time and calls fn with each record's
+// 1-based line number and size, or with the decode error of a malformed line.
+// Blank lines are skipped. Scanning stops early when fn returns false or an
+// error, which is passed through.
+func scanJSONLines(ctx context.Context, r io.Reader, fn func(line int, record any, size int, err error) (bool, error)) error {
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		raw, readErr := br.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return fmt.Errorf("%w: line %d: %v", domain.ErrReadFailed, line, readErr)
+		}
+
+		if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 {
+			record, err := parseJSONDocument(string(trimmed))
+			more, fnErr := fn(line, record, len(trimmed), err)
+			if fnErr != nil || !more {
+				return fnErr
+			}
+		}
+
+		if readErr != nil {
+			return nil
+		}
+	}
+}
+
+// recordMatches reports whether a JSON Lines record matches all filters.
+// Records that are not objects only match filters on the record itself.
+func recordMatches(record any, filters []Filter) bool {
+	return matchesElement(record, filters)
+}
@@ -0,0 +1,167 @@
+package tools_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tools"
+)
+
+const eventsJSONL = `{"id": 1, "level": "info"}
+{"id": 2, "level": "error"}
+
+{"id": 3, "level": "error"
+"plain string"
+{"id": 4, "level": "error"}
+{"id": 5, "level": "info"}`
+
+func TestJSONLQueryHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          tools.JSONLQueryArgs
+		wantErr       error
+		wantLines     []int
+		wantScanned   int
+		wantTruncated bool
+		wantErrLines  []int
+	}{
+		{
+			name:         "no filters returns every record",
+			args:         tools.JSONLQueryArgs{Path: "/tmp/events.jsonl"},
+			wantLines:    []int{1, 2, 5, 6, 7},
+			wantScanned:  7,
+			wantErrLines: []int{4},
+		},
+		{
+			name: "filters match objects only",
+			args: tools.JSONLQueryArgs{
+				Path:    "/tmp/events.jsonl",
+				Filters: []tools.Filter{{Field: "level", Op: "eq", Value: "error"}},
+			},
+			wantLines:    []int{2, 6},
+			wantScanned:  7,
+			wantErrLines: []int{4},
+		},
+		{
+			name: "filters on the record itself",
+			args: tools.JSONLQueryArgs{
+				Path:    "/tmp/events.jsonl",
+				Filters: []tools.Filter{{Field: "$", Op: "eq", Value: "plain string"}},
+			},
+			wantLines:    []int{5},
+			wantScanned:  7,
+			wantErrLines: []int{4},
+		},
+		{
+			name: "limit stops scanning early",
+			args: tools.JSONLQueryArgs{
+				Path:    "/tmp/events.jsonl",
+				Filters: []tools.Filter{{Field: "level", Op: "eq", Value: "info"}},
+				Limit:   intPtr(1),
+			},
+			wantLines:     []int{1},
+			wantScanned:   7,
+			wantTruncated: true,
+			wantErrLines:  []int{4},
+		},
+		{
+			name: "offset skips matches",
+			args: tools.JSONLQueryArgs{
+				Path:   "/tmp/events.jsonl",
+				Offset: 3,
+				Limit:  intPtr(1),
+			},
+			wantLines:     []int{6},
+			wantScanned:   7,
+			wantTruncated: true,
+			wantErrLines:  []int{4},
+		},
+		{
+			name:    "file not found",
+			args:    tools.JSONLQueryArgs{Path: "/tmp/missing.jsonl"},
+			wantErr: domain.ErrFileNotFound,
+		},
+		{
+			name:    "path traversal",
+			args:    tools.JSONLQueryArgs{Path: "/tmp/../etc/passwd"},
+			wantErr: domain.ErrPathTraversal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memReader := reader.NewInMemoryFileReader()
+			memReader.Files = map[string]string{"/tmp/events.jsonl": eventsJSONL}
+			tools.SetStreamReader(memReader)
+
+			result, output, err := tools.JSONLQueryHandler(context.Background(), &mcp.CallToolRequest{}, tt.args)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("JSONLQueryHandler() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("JSONLQueryHandler() unexpected error = %v", err)
+			}
+			if result == nil {
+				t.Fatal("JSONLQueryHandler() result is nil")
+			}
+
+			var lines []int
+			for _, m := range output.Result {
+				lines = append(lines, m.Line)
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("JSONLQueryHandler() lines = %v, want %v", lines, tt.wantLines)
+			}
+			if output.Count != len(tt.wantLines) {
+				t.Errorf("JSONLQueryHandler() count = %v, want %v", output.Count, len(tt.wantLines))
+			}
+			if output.Truncated != tt.wantTruncated {
+				t.Errorf("JSONLQueryHandler() truncated = %v, want %v", output.Truncated, tt.wantTruncated)
+			}
+			if !tt.wantTruncated && output.LinesScanned != tt.wantScanned {
+				t.Errorf("JSONLQueryHandler() linesScanned = %v, want %v", output.LinesScanned, tt.wantScanned)
+			}
+
+			var errLines []int
+			for _, e := range output.Errors {
+				errLines = append(errLines, e.Line)
+			}
+			if !reflect.DeepEqual(errLines, tt.wantErrLines) {
+				t.Errorf("JSONLQueryHandler() error lines = %v, want %v", errLines, tt.wantErrLines)
+			}
+			if output.ErrorCount != len(tt.wantErrLines) {
+				t.Errorf("JSONLQueryHandler() errorCount = %v, want %v", output.ErrorCount, len(tt.wantErrLines))
+			}
+		})
+	}
+}
+
+func TestJSONLQueryHandlerKeepsNumberPrecision(t *testing.T) {
+	memReader := reader.NewInMemoryFileReader()
+	memReader.Files = map[string]string{"/tmp/ids.jsonl": "{\"id\": 9007199254740993}\n"}
+	tools.SetStreamReader(memReader)
+
+	_, output, err := tools.JSONLQueryHandler(context.Background(), &mcp.CallToolRequest{}, tools.JSONLQueryArgs{
+		Path:    "/tmp/ids.jsonl",
+		Filters: []tools.Filter{{Field: "id", Op: "is_not_null"}},
+	})
+	if err != nil {
+		t.Fatalf("JSONLQueryHandler() unexpected error = %v", err)
+	}
+	got, err := json.Marshal(output.Result[0].Record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"id":9007199254740993}`; string(got) != want {
+		t.Errorf("JSONLQueryHandler() record = %s, want %s", got, want)
+	}
+}
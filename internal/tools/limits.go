@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
+)
+
+var (
+	// maxReadBytes caps whole-document reads through the stream reader;
+	// zero means no limit. Set via SetMaxReadBytes.
+	maxReadBytes int64
+
+	// maxDepth caps the nesting depth of parsed documents; zero means no
+	// limit. Set via SetMaxDepth.
+	maxDepth int
+)
+
+// SetMaxReadBytes sets the largest file the query tools load as a whole
+// document, as for JMESPath expressions and YAML or TOML files. Streamed
+// JSON and JSON Lines are not limited.
+func SetMaxReadBytes(n int64) {
+	maxReadBytes = n
+}
+
+// SetMaxDepth sets the deepest nesting of arrays and objects a document
+// read or written by the tools may have.
+func SetMaxDepth(n int) {
+	maxDepth = n
+}
+
+// readAllLimited reads r to the end, failing with ErrReadTooLarge past the
+// read size limit.
+func readAllLimited(r io.Reader, path string) ([]byte, error) {
+	if maxReadBytes <= 0 {
+		return io.ReadAll(r)
+	}
+	content, err := io.ReadAll(io.LimitReader(r, maxReadBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > maxReadBytes {
+		return nil, reader.TooLargeError(path, maxReadBytes)
+	}
+	return content, nil
+}
+
+// depthChecked passes through the result of a decoder, failing with
+// ErrDepthExceeded when the document nests too deeply.
+func depthChecked(doc any, err error) (any, error) {
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDepth(doc, 0); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// checkDepth returns ErrDepthExceeded when v, found at depth base, nests
+// arrays and objects deeper than the depth limit.
+func checkDepth(v any, base int) error {
+	if maxDepth <= 0 {
+		return nil
+	}
+	if depth := base + nestingDepth(v, maxDepth-base+1); depth > maxDepth {
+		return fmt.Errorf("%w: nesting is deeper than %d levels", domain.ErrDepthExceeded, maxDepth)
+	}
+	return nil
+}
+
+// nestingDepth returns the nesting depth of v, a scalar being 0, counting
+// no further than limit.
+func nestingDepth(v any, limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+	deepest := 0
+	switch val := v.(type) {
+	case map[string]any:
+		for _, child := range val {
+			deepest = max(deepest, nestingDepth(child, limit-1))
+		}
+	case []any:
+		for _, child := range val {
+			deepest = max(deepest, nestingDepth(child, limit-1))
+		}
+	default:
+		return 0
+	}
+	return deepest + 1
+}
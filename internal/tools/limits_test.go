@@ -0,0 +1,113 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tools"
+	"github.com/robertbagge/markdown-writer-mcp/internal/writer"
+)
+
+func TestDepthLimit(t *testing.T) {
+	memReader := reader.NewInMemoryFileReader()
+	memReader.Files = map[string]string{
+		"/tmp/shallow.json": `{"a": {"b": [1]}}`,
+		"/tmp/deep.json":    `{"a": {"b": [[1]]}}`,
+		"/tmp/deep.yaml":    "a:\n  b:\n    - [1]\n",
+		"/tmp/items.json":   `{"items": [{"a": 1}, {"a": [[1]]}]}`,
+	}
+	tools.SetFileReader(memReader)
+	tools.SetStreamReader(memReader)
+	tools.SetFileWriter(writer.NewInMemoryFileWriter())
+	tools.SetMaxDepth(3)
+	t.Cleanup(func() { tools.SetMaxDepth(0) })
+
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr error
+	}{
+		{
+			name: "read within limit",
+			call: func() error {
+				_, _, err := tools.JSONGetHandler(ctx, nil, tools.JSONGetArgs{Path: "/tmp/shallow.json"})
+				return err
+			},
+		},
+		{
+			name: "read too deep",
+			call: func() error {
+				_, _, err := tools.JSONGetHandler(ctx, nil, tools.JSONGetArgs{Path: "/tmp/deep.json"})
+				return err
+			},
+			wantErr: domain.ErrDepthExceeded,
+		},
+		{
+			name: "yaml too deep",
+			call: func() error {
+				_, _, err := tools.JSONGetHandler(ctx, nil, tools.JSONGetArgs{Path: "/tmp/deep.yaml"})
+				return err
+			},
+			wantErr: domain.ErrDepthExceeded,
+		},
+		{
+			name: "write too deep",
+			call: func() error {
+				_, _, err := tools.JSONWriteHandler(ctx, nil, tools.JSONWriteArgs{Path: "/tmp/out.json", Content: `[[[[1]]]]`})
+				return err
+			},
+			wantErr: domain.ErrDepthExceeded,
+		},
+		{
+			name: "streamed element too deep",
+			call: func() error {
+				_, _, err := tools.JSONQueryHandler(ctx, nil, tools.JSONQueryArgs{Path: "/tmp/items.json", ArrayPath: tools.ArrayPath{"items"}})
+				return err
+			},
+			wantErr: domain.ErrDepthExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("handler unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("handler error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadLimit_WholeDocumentQuery(t *testing.T) {
+	memReader := reader.NewInMemoryFileReader()
+	memReader.Files = map[string]string{
+		"/tmp/big.json": `[` + strings.Repeat(`{"a": 1},`, 20) + `{"a": 2}]`,
+	}
+	tools.SetStreamReader(memReader)
+	tools.SetMaxReadBytes(64)
+	t.Cleanup(func() { tools.SetMaxReadBytes(0) })
+
+	ctx := context.Background()
+
+	// Streaming queries are not limited
+	if _, _, err := tools.JSONQueryHandler(ctx, nil, tools.JSONQueryArgs{Path: "/tmp/big.json"}); err != nil {
+		t.Errorf("streaming query unexpected error: %v", err)
+	}
+
+	// Expressions load the whole document
+	_, _, err := tools.JSONQueryHandler(ctx, nil, tools.JSONQueryArgs{Path: "/tmp/big.json", Expr: "[?a == `2`]"})
+	if !errors.Is(err, domain.ErrReadTooLarge) {
+		t.Errorf("expression query error = %v, wantErr %v", err, domain.ErrReadTooLarge)
+	}
+}
@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
+)
+
+// defaultExtensions are the file extensions tools may write unless the
+// config replaces them. Tools not listed may write any extension.
+var defaultExtensions = map[string][]string{
+	"write":            {".md", ".markdown", ".mdx"},
+	"json_write":       documentExtensions,
+	"json_append":      documentExtensions,
+	"json_update":      documentExtensions,
+	"json_delete":      documentExtensions,
+	"json_patch":       documentExtensions,
+	"json_merge_patch": documentExtensions,
+	"jsonl_append":     {".jsonl", ".ndjson"},
+	"json_to_csv":      {".csv"},
+	"csv_to_json":      documentExtensions,
+}
+
+// documentExtensions are the extensions of the JSON, YAML and TOML files
+// the document tools edit.
+var documentExtensions = []string{".json", ".jsonc", ".json5", ".yaml", ".yml", ".toml"}
+
+var (
+	// accessPolicy is injected via SetAccessPolicy (DIP - dependency injection)
+	accessPolicy policy.Checker
+
+	// allowedExtensions is set via SetAllowedExtensions
+	allowedExtensions = defaultExtensions
+
+	// rejectBinary is set via SetRejectBinary
+	rejectBinary = true
+)
+
+// SetAccessPolicy injects the policy deciding which paths each tool may
+// read and write. Without one, every resolved path is allowed.
+// This follows the Dependency Inversion Principle.
+func SetAccessPolicy(p policy.Checker) {
+	accessPolicy = p
+}
+
+// SetAllowedExtensions replaces the file extensions the given tools may
+// write, keeping the defaults of the others. An empty list allows any
+// extension. Unknown tool names are an error.
+func SetAllowedExtensions(overrides map[string][]string) error {
+	for tool := range overrides {
+		if !slices.ContainsFunc(registrations(), func(r registration) bool { return r.tool.Name == tool }) {
+			return fmt.Errorf("%w: unknown tool %q in writes.extensions", domain.ErrInvalidConfig, tool)
+		}
+	}
+	merged := maps.Clone(defaultExtensions)
+	maps.Copy(merged, overrides)
+	allowedExtensions = merged
+	return nil
+}
+
+// SetRejectBinary sets whether content holding NUL bytes or invalid UTF-8
+// is refused by the tools writing text.
+func SetRejectBinary(reject bool) {
+	rejectBinary = reject
+}
+
+// authorize checks that tool may perform op on the resolved path, and that
+// it may write files with the path's extension. Handlers call it for every
+// path before touching disk.
+func authorize(tool *mcp.Tool, op policy.Operation, absPath string) error {
+	if op == policy.Write {
+		if err := checkExtension(tool, absPath); err != nil {
+			return err
+		}
+	}
+	if accessPolicy == nil {
+		return nil
+	}
+	return accessPolicy.Check(tool.Name, op, absPath)
+}
+
+// checkExtension returns ErrExtensionNotAllowed unless tool may write files
+// with the extension of absPath.
+func checkExtension(tool *mcp.Tool, absPath string) error {
+	allowed := allowedExtensions[tool.Name]
+	if len(allowed) == 0 {
+		return nil
+	}
+	ext := strings.ToLower(filepath.Ext(absPath))
+	if slices.Contains(allowed, ext) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s writes only %s files, not %s", domain.ErrExtensionNotAllowed, tool.Name, strings.Join(allowed, ", "), absPath)
+}
+
+// checkTextContent returns ErrBinaryContent when content holds NUL bytes
+// or invalid UTF-8, unless binary content is allowed.
+func checkTextContent(content string) error {
+	if !rejectBinary {
+		return nil
+	}
+	if i := strings.IndexByte(content, 0); i >= 0 {
+		return fmt.Errorf("%w: NUL byte at offset %d", domain.ErrBinaryContent, i)
+	}
+	if !utf8.ValidString(content) {
+		return fmt.Errorf("%w: content is not valid UTF-8", domain.ErrBinaryContent)
+	}
+	return nil
+}
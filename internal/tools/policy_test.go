@@ -0,0 +1,185 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/config"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
+	"github.com/robertbagge/markdown-writer-mcp/internal/reader"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tools"
+	"github.com/robertbagge/markdown-writer-mcp/internal/writer"
+)
+
+func TestAccessPolicy(t *testing.T) {
+	memReader := reader.NewInMemoryFileReader()
+	memReader.Files = map[string]string{
+		"/tmp/repo/.env":          `{"token": "x"}`,
+		"/tmp/repo/data/a.json":   `[{"a": 1}]`,
+		"/tmp/repo/schema.json":   `{"type": "object"}`,
+		"/tmp/repo/.git/cfg.json": `{}`,
+	}
+	memWriter := writer.NewInMemoryFileWriter()
+	tools.SetFileReader(memReader)
+	tools.SetStreamReader(memReader)
+	tools.SetFileWriter(memWriter)
+	tools.SetAccessPolicy(policy.New(config.Policy{
+		Rules: []config.PolicyRule{
+			{Effect: "deny", Operations: []string{"write"}, Patterns: []string{"/tmp/repo/.git/**"}},
+			{Effect: "deny", Patterns: []string{"/tmp/repo/**/.env"}},
+			{Effect: "deny", Tools: []string{"json_write"}, Patterns: []string{"/tmp/repo/schema.json"}},
+		},
+	}))
+	t.Cleanup(func() { tools.SetAccessPolicy(nil) })
+
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr error
+	}{
+		{
+			name: "read allowed",
+			call: func() error {
+				_, _, err := tools.JSONReadHandler(ctx, nil, tools.JSONReadArgs{Path: "/tmp/repo/data/a.json"})
+				return err
+			},
+		},
+		{
+			name: "read denied",
+			call: func() error {
+				_, _, err := tools.JSONReadHandler(ctx, nil, tools.JSONReadArgs{Path: "/tmp/repo/.env"})
+				return err
+			},
+			wantErr: domain.ErrAccessDenied,
+		},
+		{
+			name: "write denied",
+			call: func() error {
+				_, _, err := tools.JSONWriteHandler(ctx, nil, tools.JSONWriteArgs{Path: "/tmp/repo/.git/cfg.json", Content: `{}`})
+				return err
+			},
+			wantErr: domain.ErrAccessDenied,
+		},
+		{
+			name: "schema read checked against the tool",
+			call: func() error {
+				_, _, err := tools.JSONWriteHandler(ctx, nil, tools.JSONWriteArgs{Path: "/tmp/repo/data/b.json", Content: `{}`, SchemaPath: "/tmp/repo/schema.json"})
+				return err
+			},
+			wantErr: domain.ErrAccessDenied,
+		},
+		{
+			name: "conversion output denied",
+			call: func() error {
+				_, _, err := tools.JSONToCSVHandler(ctx, nil, tools.JSONToCSVArgs{Path: "/tmp/repo/data/a.json", OutputPath: "/tmp/repo/.git/a.csv"})
+				return err
+			},
+			wantErr: domain.ErrAccessDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if tt.wantErr == nil {
+				if errors.Is(err, domain.ErrAccessDenied) {
+					t.Errorf("handler unexpectedly denied: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("handler error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, ok := memWriter.Files["/tmp/repo/.git/a.csv"]; ok {
+		t.Errorf("denied conversion wrote its output")
+	}
+}
+
+func TestWriteExtensionsAndContent(t *testing.T) {
+	memWriter := writer.NewInMemoryFileWriter()
+	tools.SetFileWriter(memWriter)
+	tools.SetLineAppender(memWriter)
+	t.Cleanup(func() {
+		_ = tools.SetAllowedExtensions(nil)
+		tools.SetRejectBinary(true)
+	})
+
+	ctx := context.Background()
+	write := func(path, content string) error {
+		_, _, err := tools.WriteHandler(ctx, nil, tools.WriteArgs{Path: path, Content: content})
+		return err
+	}
+	jsonWrite := func(path, content string) error {
+		_, _, err := tools.JSONWriteHandler(ctx, nil, tools.JSONWriteArgs{Path: path, Content: content})
+		return err
+	}
+	jsonlAppend := func(path string) error {
+		_, _, err := tools.JSONLAppendHandler(ctx, nil, tools.JSONLAppendArgs{Path: path, Records: []any{map[string]any{"a": 1}}, Create: true})
+		return err
+	}
+	jsonPatch := func(path string) error {
+		_, _, err := tools.JSONPatchHandler(ctx, nil, tools.JSONPatchArgs{Path: path, Patch: []tools.PatchOperation{{Op: "remove", Path: "/a"}}})
+		return err
+	}
+
+	tests := []struct {
+		name        string
+		extensions  map[string][]string
+		allowBinary bool
+		call        func() error
+		wantErr     error
+	}{
+		{name: "markdown", call: func() error { return write("/tmp/notes.md", "# Notes") }},
+		{name: "extension case ignored", call: func() error { return write("/tmp/README.MD", "# Readme") }},
+		{name: "source file refused", call: func() error { return write("/tmp/main.go", "package main") }, wantErr: domain.ErrExtensionNotAllowed},
+		{name: "json into markdown refused", call: func() error { return jsonWrite("/tmp/foo.md", `{}`) }, wantErr: domain.ErrExtensionNotAllowed},
+		{name: "yaml document", call: func() error { return jsonWrite("/tmp/foo.yaml", "a: 1\n") }},
+		{name: "records into markdown refused", call: func() error { return jsonlAppend("/tmp/notes.md") }, wantErr: domain.ErrExtensionNotAllowed},
+		{name: "records into ndjson", call: func() error { return jsonlAppend("/tmp/events.ndjson") }},
+		{name: "patch of a source file refused", call: func() error { return jsonPatch("/tmp/main.go") }, wantErr: domain.ErrExtensionNotAllowed},
+		{
+			name:       "configured extensions replace the defaults",
+			extensions: map[string][]string{"write": {".txt"}},
+			call:       func() error { return write("/tmp/notes.md", "# Notes") },
+			wantErr:    domain.ErrExtensionNotAllowed,
+		},
+		{
+			name:       "empty list allows any extension",
+			extensions: map[string][]string{"write": {}},
+			call:       func() error { return write("/tmp/script.sh", "echo hi") },
+		},
+		{name: "NUL byte refused", call: func() error { return write("/tmp/notes.md", "# Notes\x00") }, wantErr: domain.ErrBinaryContent},
+		{name: "invalid UTF-8 refused", call: func() error { return write("/tmp/notes.md", "\xff\xfe") }, wantErr: domain.ErrBinaryContent},
+		{name: "binary allowed", allowBinary: true, call: func() error { return write("/tmp/notes.md", "\xff\xfe") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tools.SetAllowedExtensions(tt.extensions); err != nil {
+				t.Fatal(err)
+			}
+			tools.SetRejectBinary(!tt.allowBinary)
+
+			err := tt.call()
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("handler unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("handler error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := tools.SetAllowedExtensions(map[string][]string{"md_write": {".md"}}); !errors.Is(err, domain.ErrInvalidConfig) {
+		t.Errorf("SetAllowedExtensions() error = %v, wantErr %v", err, domain.ErrInvalidConfig)
+	}
+}
@@ -1,25 +1,86 @@
 package tools
 
 import (
+	"fmt"
+	"slices"
+
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/config"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
 )
 
-// RegisterAll registers all available tools with the MCP server
-func RegisterAll(server *mcp.Server) error {
-	// Register write tool (markdown)
-	mcp.AddTool(server, WriteTool, WriteHandler)
+// registration adds one tool to a server.
+type registration struct {
+	tool *mcp.Tool
+	// mutating tools create or change files
+	mutating bool
+	add      func(server *mcp.Server)
+}
 
-	// Register verify tool (markdown)
-	mcp.AddTool(server, VerifyTool, VerifyHandler)
+// registrations lists every tool in registration order.
+func registrations() []registration {
+	return []registration{
+		// write tool (markdown)
+		{WriteTool, true, func(s *mcp.Server) { mcp.AddTool(s, WriteTool, WriteHandler) }},
+		// verify tool (markdown)
+		{VerifyTool, false, func(s *mcp.Server) { mcp.AddTool(s, VerifyTool, VerifyHandler) }},
+		{JSONReadTool, false, func(s *mcp.Server) { mcp.AddTool(s, JSONReadTool, JSONReadHandler) }},
+		{JSONGetTool, false, func(s *mcp.Server) { mcp.AddTool(s, JSONGetTool, JSONGetHandler) }},
+		{JSONWriteTool, true, func(s *mcp.Server) { mcp.AddTool(s, JSONWriteTool, JSONWriteHandler) }},
+		{JSONQueryTool, false, func(s *mcp.Server) { mcp.AddTool(s, JSONQueryTool, JSONQueryHandler) }},
+		{JSONLQueryTool, false, func(s *mcp.Server) { mcp.AddTool(s, JSONLQueryTool, JSONLQueryHandler) }},
+		{JSONDiffTool, false, func(s *mcp.Server) { mcp.AddTool(s, JSONDiffTool, JSONDiffHandler) }},
+		{JSONValidateTool, false, func(s *mcp.Server) { mcp.AddTool(s, JSONValidateTool, JSONValidateHandler) }},
+		{JSONAppendTool, true, func(s *mcp.Server) { mcp.AddTool(s, JSONAppendTool, JSONAppendHandler) }},
+		{JSONLAppendTool, true, func(s *mcp.Server) { mcp.AddTool(s, JSONLAppendTool, JSONLAppendHandler) }},
+		{JSONUpdateTool, true, func(s *mcp.Server) { mcp.AddTool(s, JSONUpdateTool, JSONUpdateHandler) }},
+		{JSONDeleteTool, true, func(s *mcp.Server) { mcp.AddTool(s, JSONDeleteTool, JSONDeleteHandler) }},
+		{JSONPatchTool, true, func(s *mcp.Server) { mcp.AddTool(s, JSONPatchTool, JSONPatchHandler) }},
+		{JSONMergePatchTool, true, func(s *mcp.Server) { mcp.AddTool(s, JSONMergePatchTool, JSONMergePatchHandler) }},
+		// json_to_csv and csv_to_json write their output file
+		{JSONToCSVTool, true, func(s *mcp.Server) { mcp.AddTool(s, JSONToCSVTool, JSONToCSVHandler) }},
+		{CSVToJSONTool, true, func(s *mcp.Server) { mcp.AddTool(s, CSVToJSONTool, CSVToJSONHandler) }},
+	}
+}
 
-	// Register json_read tool
-	mcp.AddTool(server, JSONReadTool, JSONReadHandler)
+// RegisterAll registers all available tools with the MCP server
+func RegisterAll(server *mcp.Server) error {
+	return RegisterSelected(server, config.ToolSelection{})
+}
 
-	// Register json_write tool
-	mcp.AddTool(server, JSONWriteTool, JSONWriteHandler)
+// RegisterSelected registers the tools picked by sel with the MCP server:
+// the Include list (or every tool when empty), minus Exclude, minus the
+// mutating tools in read-only mode. Unknown tool names, and mutating tools
+// explicitly included in read-only mode, are errors.
+func RegisterSelected(server *mcp.Server, sel config.ToolSelection) error {
+	regs := registrations()
+	known := make(map[string]registration, len(regs))
+	for _, reg := range regs {
+		known[reg.tool.Name] = reg
+	}
+	for _, name := range slices.Concat(sel.Include, sel.Exclude) {
+		if _, ok := known[name]; !ok {
+			return fmt.Errorf("%w: unknown tool %q", domain.ErrInvalidConfig, name)
+		}
+	}
+	if sel.ReadOnly {
+		for _, name := range sel.Include {
+			if known[name].mutating {
+				return fmt.Errorf("%w: tool %q changes files and cannot be enabled in read-only mode", domain.ErrInvalidConfig, name)
+			}
+		}
+	}
 
-	// Register json_query tool
-	mcp.AddTool(server, JSONQueryTool, JSONQueryHandler)
+	for _, reg := range regs {
+		name := reg.tool.Name
+		if len(sel.Include) > 0 && !slices.Contains(sel.Include, name) {
+			continue
+		}
+		if slices.Contains(sel.Exclude, name) || (sel.ReadOnly && reg.mutating) {
+			continue
+		}
+		reg.add(server)
+	}
 
 	return nil
 }
@@ -0,0 +1,108 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/config"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tools"
+)
+
+func TestRegisterSelected(t *testing.T) {
+	readOnlyTools := []string{"verify", "json_read", "json_get", "json_query", "jsonl_query", "json_diff", "json_validate"}
+
+	tests := []struct {
+		name    string
+		sel     config.ToolSelection
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "read-only",
+			sel:  config.ToolSelection{ReadOnly: true},
+			want: readOnlyTools,
+		},
+		{
+			name: "include",
+			sel:  config.ToolSelection{Include: []string{"json_query", "json_read", "write"}},
+			want: []string{"write", "json_read", "json_query"},
+		},
+		{
+			name: "read-only with exclude",
+			sel:  config.ToolSelection{ReadOnly: true, Exclude: []string{"json_diff", "json_validate"}},
+			want: []string{"verify", "json_read", "json_get", "json_query", "jsonl_query"},
+		},
+		{
+			name:    "unknown tool",
+			sel:     config.ToolSelection{Exclude: []string{"json_rm"}},
+			wantErr: domain.ErrInvalidConfig,
+		},
+		{
+			name:    "mutating tool in read-only mode",
+			sel:     config.ToolSelection{ReadOnly: true, Include: []string{"json_read", "json_write"}},
+			wantErr: domain.ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mcp.NewServer(&mcp.Implementation{Name: "server", Version: "test"}, nil)
+			err := tools.RegisterSelected(server, tt.sel)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("RegisterSelected() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RegisterSelected() unexpected error: %v", err)
+			}
+
+			got := listToolNames(t, server)
+			if !sameSet(got, tt.want) {
+				t.Errorf("tools/list = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// listToolNames returns the tool names a client sees in tools/list.
+func listToolNames(t *testing.T, server *mcp.Server) []string {
+	t.Helper()
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverSession.Close()
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "test"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientSession.Close()
+
+	result, err := clientSession.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(result.Tools))
+	for i, tool := range result.Tools {
+		names[i] = tool.Name
+	}
+	return names
+}
+
+// sameSet reports whether a and b hold the same names in any order.
+func sameSet(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
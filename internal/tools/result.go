@@ -0,0 +1,14 @@
+package tools
+
+import (
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// textResult wraps a plain message in a tool result
+func textResult(message string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: message},
+		},
+	}
+}
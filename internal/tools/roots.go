@@ -0,0 +1,180 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+)
+
+// rootsSyncTimeout bounds one roots/list request to the client, and how
+// long tool calls wait for the first one.
+const rootsSyncTimeout = 10 * time.Second
+
+var (
+	// configuredRoots are set via SetConfiguredRoots and bound the roots a
+	// client may advertise
+	configuredRoots []string
+
+	// rootsSyncMu serializes roots/list round trips, so the latest wins
+	rootsSyncMu sync.Mutex
+
+	// rootsPending maps each session whose first roots/list is in flight
+	// to a channel closed once it completes
+	rootsPending sync.Map
+)
+
+// SetConfiguredRoots sets the workspace roots given on the command line or
+// in the config file. They confine path resolution until the client
+// advertises its own roots, and client roots outside them are ignored.
+func SetConfiguredRoots(roots []string) error {
+	if err := pathutil.SetRoots(roots); err != nil {
+		return err
+	}
+	configuredRoots = pathutil.Roots()
+	return nil
+}
+
+// RootsInitializedHandler fetches the client's roots once the session is
+// initialized, if the client supports listing them. Tool calls wait for
+// the first fetch; see WaitForRootsMiddleware.
+func RootsInitializedHandler(ctx context.Context, req *mcp.InitializedRequest) {
+	if req.Session == nil {
+		return
+	}
+	if !listsRoots(req.Session) {
+		slog.Info("client does not list roots, keeping the configured roots")
+		return
+	}
+
+	// Notifications are handled before later requests are dispatched, so
+	// every tool call of the session sees the pending fetch
+	ready := make(chan struct{})
+	rootsPending.Store(req.Session, ready)
+
+	// The client answers on the connection delivering this notification,
+	// so wait for it outside the handler
+	go func() {
+		syncClientRoots(context.WithoutCancel(ctx), req.Session)
+		close(ready)
+		rootsPending.Delete(req.Session)
+	}()
+}
+
+// WaitForRootsMiddleware holds tool calls until the session's first
+// roots/list completes, or for at most rootsSyncTimeout, so that relative
+// paths resolve against the client's roots from the first call on.
+func WaitForRootsMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method == "tools/call" {
+			if ready, ok := rootsPending.Load(req.GetSession()); ok {
+				timer := time.NewTimer(rootsSyncTimeout)
+				defer timer.Stop()
+				select {
+				case <-ready.(chan struct{}):
+				case <-timer.C:
+					slog.Warn("tool call proceeding before the client's roots are known")
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// listsRoots reports whether the client declared the roots capability.
+// The SDK decodes the capability into a struct, so a bare "roots": {}
+// cannot be told from its absence; listChanged is what clients declare
+// along with it.
+func listsRoots(session *mcp.ServerSession) bool {
+	params := session.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.Roots.ListChanged
+}
+
+// RootsListChangedHandler fetches the client's roots again when it reports
+// that they changed.
+func RootsListChangedHandler(ctx context.Context, req *mcp.RootsListChangedRequest) {
+	if req.Session == nil {
+		return
+	}
+	go syncClientRoots(context.WithoutCancel(ctx), req.Session)
+}
+
+// syncClientRoots confines path resolution to the roots the client
+// advertises. Without usable client roots, the configured roots apply.
+func syncClientRoots(ctx context.Context, session *mcp.ServerSession) {
+	if session == nil {
+		return
+	}
+	rootsSyncMu.Lock()
+	defer rootsSyncMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, rootsSyncTimeout)
+	defer cancel()
+
+	result, err := session.ListRoots(ctx, nil)
+	if err != nil {
+		slog.Warn("failed to list client roots", slog.Any("error", err))
+		return
+	}
+
+	dirs := clientRootDirs(result.Roots)
+	if len(dirs) == 0 {
+		dirs = configuredRoots
+	}
+	if err := pathutil.SetRoots(dirs); err != nil {
+		slog.Warn("failed to set workspace roots", slog.Any("error", err))
+		return
+	}
+
+	slog.Info("workspace roots updated", slog.Any("roots", pathutil.Roots()))
+}
+
+// clientRootDirs converts client roots to directories. Roots that are not
+// file URIs, are not existing directories or lie outside the configured
+// roots are skipped.
+func clientRootDirs(roots []*mcp.Root) []string {
+	var dirs []string
+	for _, root := range roots {
+		if root == nil {
+			continue
+		}
+		u, err := url.Parse(root.URI)
+		if err != nil || u.Scheme != "file" || u.Path == "" {
+			slog.Warn("skipping client root that is not a file URI", slog.String("uri", root.URI))
+			continue
+		}
+		dir := filepath.Clean(filepath.FromSlash(u.Path))
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			slog.Warn("skipping client root that is not a directory", slog.String("uri", root.URI))
+			continue
+		}
+		if !withinConfiguredRoots(dir) {
+			slog.Warn("skipping client root outside the configured roots", slog.String("uri", root.URI))
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// withinConfiguredRoots reports whether dir lies within a configured root.
+// Without configured roots every directory qualifies.
+func withinConfiguredRoots(dir string) bool {
+	if len(configuredRoots) == 0 {
+		return true
+	}
+	for _, root := range configuredRoots {
+		if pathutil.Within(root, dir) {
+			return true
+		}
+	}
+	return false
+}
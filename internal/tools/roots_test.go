@@ -0,0 +1,187 @@
+package tools_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/tools"
+	"github.com/robertbagge/markdown-writer-mcp/internal/writer"
+)
+
+func TestClientRoots(t *testing.T) {
+	configured := t.TempDir()
+	project := filepath.Join(configured, "project")
+	other := filepath.Join(configured, "other")
+	outside := t.TempDir()
+	for _, dir := range []string{project, other} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tools.SetConfiguredRoots([]string{configured}); err != nil {
+		t.Fatalf("SetConfiguredRoots() unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = tools.SetConfiguredRoots(nil) })
+
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "server", Version: "test"}, &mcp.ServerOptions{
+		InitializedHandler:      tools.RootsInitializedHandler,
+		RootsListChangedHandler: tools.RootsListChangedHandler,
+	})
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "test"}, nil)
+	client.AddRoots(
+		&mcp.Root{URI: fileURI(project)},
+		&mcp.Root{URI: fileURI(outside)},
+		&mcp.Root{URI: "https://example.com/repo"},
+	)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverSession.Close()
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientSession.Close()
+
+	// Roots outside the configured roots and non-file roots are ignored
+	waitForRoots(t, []string{project})
+
+	got, err := pathutil.Resolve("docs/notes.md")
+	if err != nil {
+		t.Fatalf("Resolve() unexpected error: %v", err)
+	}
+	if want := filepath.Join(project, "docs", "notes.md"); got != want {
+		t.Errorf("Resolve() = %v, want %v", got, want)
+	}
+	if _, err := pathutil.Resolve(filepath.Join(other, "a.md")); !errors.Is(err, domain.ErrOutsideWorkspace) {
+		t.Errorf("Resolve() error = %v, wantErr %v", err, domain.ErrOutsideWorkspace)
+	}
+
+	// A changed roots list is fetched again
+	client.RemoveRoots(fileURI(project))
+	client.AddRoots(&mcp.Root{URI: fileURI(other)})
+	waitForRoots(t, []string{other})
+
+	// Without usable client roots, the configured roots apply
+	client.RemoveRoots(fileURI(other))
+	waitForRoots(t, []string{configured})
+}
+
+func fileURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+func waitForRoots(t *testing.T, want []string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got := pathutil.Roots()
+		if reflect.DeepEqual(got, want) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Roots() = %v, want %v", got, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestClientRoots_FirstToolCall(t *testing.T) {
+	configured := t.TempDir()
+	project := filepath.Join(configured, "project")
+	if err := os.Mkdir(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := tools.SetConfiguredRoots([]string{configured}); err != nil {
+		t.Fatalf("SetConfiguredRoots() unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = tools.SetConfiguredRoots(nil) })
+	tools.SetFileWriter(writer.NewOSFileWriter(writer.Limits{}))
+
+	tests := []struct {
+		name       string
+		listsRoots bool
+		wantDir    string
+	}{
+		{name: "waits for the client's roots", listsRoots: true, wantDir: project},
+		{name: "client without roots capability", listsRoots: false, wantDir: configured},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = tools.SetConfiguredRoots([]string{configured})
+
+			ctx := context.Background()
+			server := mcp.NewServer(&mcp.Implementation{Name: "server", Version: "test"}, &mcp.ServerOptions{
+				InitializedHandler: tools.RootsInitializedHandler,
+			})
+			server.AddReceivingMiddleware(tools.WaitForRootsMiddleware)
+			mcp.AddTool(server, tools.WriteTool, tools.WriteHandler)
+
+			var rootsListed atomic.Int32
+			client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "test"}, nil)
+			client.AddRoots(&mcp.Root{URI: fileURI(project)})
+			// Answer roots/list slowly, so the tool call below races it
+			client.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+				return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+					if method == "roots/list" {
+						rootsListed.Add(1)
+						time.Sleep(200 * time.Millisecond)
+					}
+					return next(ctx, method, req)
+				}
+			})
+			if !tt.listsRoots {
+				client.AddSendingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+					return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+						if params, ok := req.GetParams().(*mcp.InitializeParams); ok {
+							params.Capabilities = &mcp.ClientCapabilities{}
+						}
+						return next(ctx, method, req)
+					}
+				})
+			}
+
+			serverTransport, clientTransport := mcp.NewInMemoryTransports()
+			serverSession, err := server.Connect(ctx, serverTransport, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer serverSession.Close()
+			clientSession, err := client.Connect(ctx, clientTransport, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer clientSession.Close()
+
+			result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{
+				Name:      "write",
+				Arguments: map[string]any{"path": "docs/notes.md", "content": "# Notes"},
+			})
+			if err != nil || result.IsError {
+				t.Fatalf("CallTool() = %v, %v", result, err)
+			}
+			if _, err := os.Stat(filepath.Join(tt.wantDir, "docs", "notes.md")); err != nil {
+				t.Errorf("file not written below %s: %v", tt.wantDir, err)
+			}
+			if listed := rootsListed.Load() > 0; listed != tt.listsRoots {
+				t.Errorf("roots listed = %v, want %v", listed, tt.listsRoots)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/secrets"
+)
+
+var (
+	// secretDetector is injected via SetSecretDetector (DIP - dependency injection)
+	secretDetector secrets.Detector
+
+	// secretAction is set via SetSecretDetector
+	secretAction = secrets.ActionBlock
+)
+
+// SetSecretDetector injects the detector scanning content before every
+// write, and what to do with the secrets it finds. Without one, content is
+// not scanned.
+// This follows the Dependency Inversion Principle.
+func SetSecretDetector(d secrets.Detector, action secrets.Action) {
+	secretDetector = d
+	secretAction = action
+}
+
+// guardSecrets scans content that tool is about to write to path. Secrets
+// already in previous, the file's current content, are ignored so that an
+// edit does not fail on what the file already holds. Depending on the
+// action, it fails with ErrSecretDetected, returns the content with the
+// secrets redacted, or returns it unchanged; the notes describe what was
+// found, for the tool result.
+func guardSecrets(tool *mcp.Tool, path, previous, content string) (string, []string, error) {
+	if secretDetector == nil {
+		return content, nil, nil
+	}
+	var findings []secrets.Finding
+	for _, f := range secretDetector.Scan(content) {
+		if previous == "" || !strings.Contains(previous, content[f.Start:f.End]) {
+			findings = append(findings, f)
+		}
+	}
+	if len(findings) == 0 {
+		return content, nil, nil
+	}
+
+	notes := make([]string, len(findings))
+	for i, f := range findings {
+		notes[i] = f.String()
+	}
+	slog.Warn("possible secrets in content",
+		slog.String("tool", tool.Name),
+		slog.String("path", path),
+		slog.String("action", string(secretAction)),
+		slog.Any("findings", notes),
+	)
+
+	switch secretAction {
+	case secrets.ActionRedact:
+		return secrets.Redact(content, findings), notes, nil
+	case secrets.ActionWarn:
+		return content, notes, nil
+	default:
+		return "", nil, fmt.Errorf("%w: %s refused to write %s: %s; remove the credentials or reference them indirectly, e.g. through an environment variable",
+			domain.ErrSecretDetected, tool.Name, path, strings.Join(notes, "; "))
+	}
+}
+
+// replacedContent returns the content of the file at path that a write is
+// about to replace, for guardSecrets to ignore the secrets it already
+// holds. It is empty when no file exists, when nothing is scanned, or when
+// the file cannot be read, in which case every secret is reported.
+func replacedContent(ctx context.Context, path string) string {
+	if secretDetector == nil || fileReader == nil {
+		return ""
+	}
+	content, err := fileReader.Read(ctx, path)
+	if err != nil {
+		return ""
+	}
+	return content
+}
+
+// guardSecretLines applies guardSecrets to lines about to be appended.
+func guardSecretLines(tool *mcp.Tool, path string, lines []string) ([]string, []string, error) {
+	content, notes, err := guardSecrets(tool, path, "", strings.Join(lines, "\n"))
+	if err != nil || notes == nil {
+		return lines, notes, err
+	}
+	return strings.Split(content, "\n"), notes, nil
+}
+
+// withSecretNotes appends the secrets reported by guardSecrets to a tool
+// result message.
+func withSecretNotes(message string, notes []string) string {
+	if len(notes) == 0 {
+		return message
+	}
+	verb := "left in place"
+	if secretAction == secrets.ActionRedact {
+		verb = "redacted"
+	}
+	return fmt.Sprintf("%s\nWarning: possible secrets %s: %s", message, verb, strings.Join(notes, "; "))
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 
 	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
 	"github.com/robertbagge/markdown-writer-mcp/internal/verifier"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -48,6 +49,9 @@ func VerifyHandler(
 	if err != nil {
 		return nil, VerifyOutput{}, err
 	}
+	if err := authorize(VerifyTool, policy.Read, absPath); err != nil {
+		return nil, VerifyOutput{}, err
+	}
 
 	slog.Info("verify tool called",
 		slog.String("path", absPath),
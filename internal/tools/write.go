@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
 	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
+	"github.com/robertbagge/markdown-writer-mcp/internal/policy"
 	"github.com/robertbagge/markdown-writer-mcp/internal/writer"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -18,18 +20,27 @@ var WriteTool = &mcp.Tool{
 
 // WriteArgs defines the input parameters for the write tool
 type WriteArgs struct {
-	Path    string `json:"path" jsonschema:"Absolute or relative path to the markdown file to write"`
-	Content string `json:"content" jsonschema:"Markdown content to write to the file"`
+	Path      string `json:"path" jsonschema:"Absolute or relative path to the markdown file to write"`
+	Content   string `json:"content" jsonschema:"Markdown content to write to the file"`
+	Mode      string `json:"mode,omitempty" jsonschema:"Write mode: create (fail if the file exists), overwrite (fail if it does not exist) or upsert (create or replace). Defaults to the server's default mode, upsert unless configured otherwise"`
+	Overwrite bool   `json:"overwrite,omitempty" jsonschema:"Allow replacing an existing file when the server's default mode is create"`
 }
 
 // WriteOutput defines the output structure for the write tool
 type WriteOutput struct {
-	Path string `json:"path"`
-	Size int64  `json:"size"`
+	Path     string   `json:"path"`
+	Size     int64    `json:"size"`
+	Replaced bool     `json:"replaced"`
+	Secrets  []string `json:"secrets,omitempty"`
 }
 
-// fileWriter is injected via SetFileWriter (DIP - dependency injection)
-var fileWriter writer.FileWriter
+var (
+	// fileWriter is injected via SetFileWriter (DIP - dependency injection)
+	fileWriter writer.FileWriter
+
+	// defaultWriteMode is set via SetDefaultWriteMode
+	defaultWriteMode = writer.ModeUpsert
+)
 
 // SetFileWriter injects the file writer implementation.
 // This follows the Dependency Inversion Principle.
@@ -37,6 +48,44 @@ func SetFileWriter(w writer.FileWriter) {
 	fileWriter = w
 }
 
+// SetDefaultWriteMode sets the mode of write and json_write when the caller
+// gives none. Under ModeCreate, replacing a file takes overwrite: true.
+func SetDefaultWriteMode(mode writer.Mode) {
+	defaultWriteMode = mode
+}
+
+// writeMode returns the mode for a write given its mode and overwrite
+// arguments. When the default mode is create, a mode that may replace a
+// file must be confirmed with overwrite.
+func writeMode(mode string, overwrite bool) (writer.Mode, error) {
+	m := writer.Mode(mode)
+	switch m {
+	case "":
+		if defaultWriteMode == writer.ModeCreate && overwrite {
+			return writer.ModeUpsert, nil
+		}
+		return defaultWriteMode, nil
+	case writer.ModeCreate:
+		return m, nil
+	case writer.ModeOverwrite, writer.ModeUpsert:
+		if defaultWriteMode == writer.ModeCreate && !overwrite {
+			return "", fmt.Errorf("%w: mode %q may replace a file, which requires overwrite: true on this server", domain.ErrInvalidWriteMode, mode)
+		}
+		return m, nil
+	default:
+		return "", fmt.Errorf("%w: %q (want create, overwrite or upsert)", domain.ErrInvalidWriteMode, mode)
+	}
+}
+
+// writtenMessage describes a completed write of size bytes of kind content.
+func writtenMessage(size int64, kind, path string, replaced bool) string {
+	message := fmt.Sprintf("Successfully wrote %d bytes%s to %s", size, kind, path)
+	if replaced {
+		message += " (replaced existing file)"
+	}
+	return message
+}
+
 // WriteHandler handles the write tool invocation
 func WriteHandler(
 	ctx context.Context,
@@ -48,24 +97,45 @@ func WriteHandler(
 	if err != nil {
 		return nil, WriteOutput{}, err
 	}
+	if err := authorize(WriteTool, policy.Write, absPath); err != nil {
+		return nil, WriteOutput{}, err
+	}
+	if err := checkTextContent(args.Content); err != nil {
+		return nil, WriteOutput{}, err
+	}
+	mode, err := writeMode(args.Mode, args.Overwrite)
+	if err != nil {
+		return nil, WriteOutput{}, err
+	}
 
 	slog.Info("write tool called",
 		slog.String("path", absPath),
 		slog.Int("content_length", len(args.Content)),
+		slog.String("mode", string(mode)),
 	)
 
+	unlock := lockPath(absPath)
+	defer unlock()
+
+	content, notes, err := guardSecrets(WriteTool, absPath, replacedContent(ctx, absPath), args.Content)
+	if err != nil {
+		return nil, WriteOutput{}, err
+	}
+
 	// Write file using injected writer
-	size, err := fileWriter.Write(ctx, absPath, args.Content)
+	size, replaced, err := fileWriter.WriteMode(ctx, absPath, content, mode)
 	if err != nil {
 		return nil, WriteOutput{}, err
 	}
 
 	output := WriteOutput{
-		Path: absPath,
-		Size: size,
+		Path:     absPath,
+		Size:     size,
+		Replaced: replaced,
+		Secrets:  notes,
 	}
 
-	message := fmt.Sprintf("Successfully wrote %d bytes to %s", size, absPath)
+	message := withSecretNotes(writtenMessage(size, "", absPath, replaced), notes)
 	result := &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: message},
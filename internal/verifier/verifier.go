@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
 )
 
 // FileInfo contains information about a verified file.
@@ -33,21 +34,25 @@ func NewOSFileVerifier() *OSFileVerifier {
 
 // Verify checks if a file exists and returns its statistics.
 func (v *OSFileVerifier) Verify(ctx context.Context, path string) (*FileInfo, error) {
-	// Check if file exists and get stats
-	stat, err := os.Stat(path)
+	// Open the file through its workspace root, then stat what was opened
+	dir, name, err := pathutil.OpenDir(path)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+	file, err := dir.OpenFile(name, os.O_RDONLY, 0)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, domain.ErrFileNotFound
 		}
 		return nil, fmt.Errorf("verify failed: %w", err)
 	}
+	defer file.Close()
 
-	// Open file to count lines
-	file, err := os.Open(path)
+	stat, err := file.Stat()
 	if err != nil {
 		return nil, fmt.Errorf("verify failed: %w", err)
 	}
-	defer file.Close()
 
 	// Count lines
 	lines := 0
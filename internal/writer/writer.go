@@ -2,64 +2,393 @@ package writer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
 )
 
 // FileWriter defines the behavior for writing markdown files.
 // Interface is defined at the usage point (consumer-defined interface).
 type FileWriter interface {
 	Write(ctx context.Context, path, content string) (int64, error)
+	WriteMode(ctx context.Context, path, content string, mode Mode) (int64, bool, error)
 }
 
+// Mode controls whether a write may create a file, replace one, or both.
+type Mode string
+
+const (
+	// ModeUpsert creates the file or replaces it.
+	ModeUpsert Mode = "upsert"
+	// ModeCreate fails with ErrFileExists when the file exists.
+	ModeCreate Mode = "create"
+	// ModeOverwrite fails with ErrFileNotFound when the file is missing.
+	ModeOverwrite Mode = "overwrite"
+)
+
+// LineAppender defines the behavior for appending lines to a file without
+// rewriting it.
+type LineAppender interface {
+	AppendLines(ctx context.Context, path string, lines []string, create bool) (int64, error)
+}
+
+// Limits bounds what an OSFileWriter may write. Zero disables a limit.
+type Limits struct {
+	// MaxBytes caps the bytes written by one Write or AppendLines call.
+	MaxBytes int64
+
+	// QuotaBytes caps the combined size of the files under the workspace
+	// roots, including the file being written. It applies only when
+	// workspace roots are set.
+	QuotaBytes int64
+}
+
+// usageTTL is how long the measured workspace usage is trusted before the
+// roots are walked again, picking up changes made outside the writer.
+const usageTTL = time.Minute
+
 // OSFileWriter implements FileWriter using the OS file system with atomic writes.
-type OSFileWriter struct{}
+type OSFileWriter struct {
+	limits Limits
+
+	// usageMu guards the cached workspace usage. Writes add their growth
+	// to it, so the roots are walked only when the cache is stale.
+	usageMu    sync.Mutex
+	usage      int64
+	usageRoots []string
+	measuredAt time.Time
+}
 
 // NewOSFileWriter creates a new OS file system writer.
-func NewOSFileWriter() *OSFileWriter {
-	return &OSFileWriter{}
+func NewOSFileWriter(limits Limits) *OSFileWriter {
+	return &OSFileWriter{limits: limits}
 }
 
 // Write writes content to a file atomically using a temporary file and rename.
 // This ensures the file is either fully written or not written at all.
 func (w *OSFileWriter) Write(ctx context.Context, path, content string) (int64, error) {
+	size, _, err := w.WriteMode(ctx, path, content, ModeUpsert)
+	return size, err
+}
+
+// WriteMode writes content to a file atomically, as Write does, subject to
+// mode, and reports whether an existing file was replaced. A created file
+// is linked into place, so it appears only if no file exists at the path.
+func (w *OSFileWriter) WriteMode(ctx context.Context, path, content string, mode Mode) (_ int64, _ bool, err error) {
+	// Every access below goes through the workspace root, so a symlink
+	// swapped in after Resolve cannot redirect the write out of it
+	dir, name, err := pathutil.OpenDir(path)
+	if err != nil {
+		return 0, false, err
+	}
+	defer dir.Close()
+
+	_, statErr := dir.Lstat(name)
+	exists := statErr == nil
+	switch mode {
+	case ModeCreate:
+		if exists {
+			return 0, false, fmt.Errorf("%w: %s", domain.ErrFileExists, path)
+		}
+	case ModeOverwrite:
+		if !exists {
+			return 0, false, fmt.Errorf("%w: %s", domain.ErrFileNotFound, path)
+		}
+	case ModeUpsert:
+	default:
+		return 0, false, fmt.Errorf("%w: %q", domain.ErrInvalidWriteMode, mode)
+	}
+
+	growth, err := w.checkLimits(path, int64(len(content)), mode != ModeCreate)
+	if err != nil {
+		return 0, false, err
+	}
+	defer func() {
+		if err != nil {
+			w.addUsage(-growth)
+		}
+	}()
+
 	// Create parent directories if they don't exist
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return 0, fmt.Errorf("%w: %v", domain.ErrDirCreateFailed, err)
+	if err := dir.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		if errors.Is(err, domain.ErrOutsideWorkspace) {
+			return 0, false, err
+		}
+		return 0, false, fmt.Errorf("%w: %v", domain.ErrDirCreateFailed, err)
 	}
 
 	// Create temporary file in the same directory for atomic rename
-	tmpFile, err := os.CreateTemp(dir, filepath.Base(path)+".tmp.*")
+	tmpFile, tmpName, err := createTemp(dir, name)
 	if err != nil {
-		return 0, fmt.Errorf("%w: %v", domain.ErrWriteFailed, err)
+		return 0, false, err
 	}
-	tmpPath := tmpFile.Name()
-	defer os.Remove(tmpPath) // Cleanup temp file on error
+	defer dir.Remove(tmpName) // Cleanup temp file on error
 
 	// Write content to temporary file
 	n, err := tmpFile.WriteString(content)
 	if err != nil {
 		tmpFile.Close()
-		return 0, fmt.Errorf("%w: %v", domain.ErrWriteFailed, err)
+		return 0, false, fmt.Errorf("%w: %v", domain.ErrWriteFailed, err)
 	}
 
 	// Close the temp file before renaming
 	if err := tmpFile.Close(); err != nil {
-		return 0, fmt.Errorf("%w: %v", domain.ErrWriteFailed, err)
+		return 0, false, fmt.Errorf("%w: %v", domain.ErrWriteFailed, err)
+	}
+
+	if mode == ModeCreate {
+		if err := createFrom(dir, tmpName, name, path, content); err != nil {
+			return 0, false, err
+		}
+		return int64(n), false, nil
 	}
 
 	// Atomically rename temp file to target path
-	if err := os.Rename(tmpPath, path); err != nil {
+	if err := dir.Rename(tmpName, name); err != nil {
+		return 0, false, fmt.Errorf("%w: %v", domain.ErrWriteFailed, err)
+	}
+
+	return int64(n), exists, nil
+}
+
+// createTemp creates a new file next to name in dir, as os.CreateTemp
+// does, and returns it with its name.
+func createTemp(dir pathutil.Dir, name string) (*os.File, string, error) {
+	for range 10000 {
+		tmpName := name + ".tmp." + strconv.FormatUint(uint64(rand.Uint32()), 10)
+		f, err := dir.OpenFile(tmpName, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			if errors.Is(err, domain.ErrOutsideWorkspace) {
+				return nil, "", err
+			}
+			return nil, "", fmt.Errorf("%w: %v", domain.ErrWriteFailed, err)
+		}
+		return f, tmpName, nil
+	}
+	return nil, "", fmt.Errorf("%w: no unused temporary name for %s", domain.ErrWriteFailed, name)
+}
+
+// createFrom puts the written temp file at name unless a file exists there.
+// A hard link does so atomically; where links are unsupported, the content
+// is written to a file opened with O_EXCL.
+func createFrom(dir pathutil.Dir, tmpName, name, path, content string) error {
+	err := dir.Link(tmpName, name)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%w: %s", domain.ErrFileExists, path)
+	}
+
+	f, err := dir.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("%w: %s", domain.ErrFileExists, path)
+		}
+		return fmt.Errorf("%w: %v", domain.ErrWriteFailed, err)
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		dir.Remove(name)
+		return fmt.Errorf("%w: %v", domain.ErrWriteFailed, err)
+	}
+	if err := f.Close(); err != nil {
+		dir.Remove(name)
+		return fmt.Errorf("%w: %v", domain.ErrWriteFailed, err)
+	}
+	return nil
+}
+
+// AppendLines appends each line, newline-terminated, to the end of a file and
+// syncs it to disk. If the file does not end with a newline, one is written
+// first so the new lines never merge into a partial last line. A missing file
+// is created only when create is set.
+func (w *OSFileWriter) AppendLines(ctx context.Context, path string, lines []string, create bool) (_ int64, err error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	// Appending follows symlinks, which the workspace root keeps from
+	// leading out of it
+	dir, name, err := pathutil.OpenDir(path)
+	if err != nil {
+		return 0, err
+	}
+	defer dir.Close()
+	growth, err := w.checkLimits(path, int64(len(joinLines(lines))), false)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			w.addUsage(-growth)
+		}
+	}()
+
+	flags := os.O_RDWR | os.O_APPEND
+	if create {
+		if err := dir.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			if errors.Is(err, domain.ErrOutsideWorkspace) {
+				return 0, err
+			}
+			return 0, fmt.Errorf("%w: %v", domain.ErrDirCreateFailed, err)
+		}
+		flags |= os.O_CREATE
+	}
+	f, err := dir.OpenFile(name, flags, 0644)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, domain.ErrFileNotFound
+		}
+		if errors.Is(err, domain.ErrOutsideWorkspace) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("%w: %v", domain.ErrWriteFailed, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
 		return 0, fmt.Errorf("%w: %v", domain.ErrWriteFailed, err)
 	}
+	var prefix string
+	if info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err != nil {
+			return 0, fmt.Errorf("%w: %v", domain.ErrWriteFailed, err)
+		}
+		if last[0] != '\n' {
+			prefix = "\n"
+		}
+	}
 
+	// A single write keeps concurrent appenders from interleaving lines
+	n, err := f.WriteString(prefix + joinLines(lines))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", domain.ErrWriteFailed, err)
+	}
+	if err := f.Sync(); err != nil {
+		return 0, fmt.Errorf("%w: %v", domain.ErrWriteFailed, err)
+	}
+	if err := f.Close(); err != nil {
+		return 0, fmt.Errorf("%w: %v", domain.ErrWriteFailed, err)
+	}
+	if prefix != "" {
+		w.addUsage(int64(len(prefix)))
+	}
 	return int64(n), nil
 }
 
+// checkLimits returns ErrWriteTooLarge or ErrQuotaExceeded when writing
+// size bytes to path would break a limit. The bytes replace the file's
+// content when replace is set, and are added to it otherwise. Under a
+// quota, the growth is counted against the cached workspace usage right
+// away, so that concurrent writes cannot overshoot together, and returned
+// for the caller to take back should the write fail.
+func (w *OSFileWriter) checkLimits(path string, size int64, replace bool) (int64, error) {
+	if w.limits.MaxBytes > 0 && size > w.limits.MaxBytes {
+		return 0, fmt.Errorf("%w: %d bytes for %s, over the %d-byte limit", domain.ErrWriteTooLarge, size, path, w.limits.MaxBytes)
+	}
+
+	roots := pathutil.Roots()
+	if w.limits.QuotaBytes <= 0 || len(roots) == 0 {
+		return 0, nil
+	}
+	growth := size
+	if info, err := os.Lstat(path); err == nil && info.Mode().IsRegular() && replace {
+		growth -= info.Size()
+	}
+
+	w.usageMu.Lock()
+	defer w.usageMu.Unlock()
+	if !slices.Equal(roots, w.usageRoots) || time.Since(w.measuredAt) > usageTTL {
+		used, err := diskUsage(roots)
+		if err != nil {
+			return 0, fmt.Errorf("%w: measuring workspace usage: %v", domain.ErrWriteFailed, err)
+		}
+		w.usage, w.usageRoots, w.measuredAt = used, roots, time.Now()
+	}
+	after := w.usage + growth
+	if after > w.limits.QuotaBytes {
+		return 0, fmt.Errorf("%w: writing %s would bring the workspace to %d bytes, over the %d-byte quota", domain.ErrQuotaExceeded, path, after, w.limits.QuotaBytes)
+	}
+	w.usage = after
+	return growth, nil
+}
+
+// addUsage adjusts the cached workspace usage by delta bytes.
+func (w *OSFileWriter) addUsage(delta int64) {
+	w.usageMu.Lock()
+	defer w.usageMu.Unlock()
+	w.usage += delta
+}
+
+// diskUsage sums the sizes of the regular files under the given roots.
+// Symlinks are not followed, unreadable entries are skipped, and roots
+// nested in another root are counted once.
+func diskUsage(roots []string) (int64, error) {
+	var total int64
+	for i, root := range roots {
+		if nested(roots, i) {
+			continue
+		}
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if path == root {
+					return err
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+// nested reports whether roots[i] lies within another of the roots. Of two
+// equal roots, the later one counts as nested.
+func nested(roots []string, i int) bool {
+	for j, other := range roots {
+		if j != i && pathutil.Within(other, roots[i]) && (!pathutil.Within(roots[i], other) || j < i) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinLines terminates every line with a newline.
+func joinLines(lines []string) string {
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
 // InMemoryFileWriter is a fake implementation for testing.
 // It stores files in memory rather than on disk.
 type InMemoryFileWriter struct {
@@ -78,3 +407,31 @@ func (w *InMemoryFileWriter) Write(ctx context.Context, path, content string) (i
 	w.Files[path] = content
 	return int64(len(content)), nil
 }
+
+// WriteMode stores the content in memory, subject to mode.
+func (w *InMemoryFileWriter) WriteMode(ctx context.Context, path, content string, mode Mode) (int64, bool, error) {
+	_, exists := w.Files[path]
+	switch {
+	case mode == ModeCreate && exists:
+		return 0, false, fmt.Errorf("%w: %s", domain.ErrFileExists, path)
+	case mode == ModeOverwrite && !exists:
+		return 0, false, fmt.Errorf("%w: %s", domain.ErrFileNotFound, path)
+	}
+	size, err := w.Write(ctx, path, content)
+	return size, exists, err
+}
+
+// AppendLines appends the lines to the content in memory.
+func (w *InMemoryFileWriter) AppendLines(ctx context.Context, path string, lines []string, create bool) (int64, error) {
+	existing, ok := w.Files[path]
+	if !ok && !create {
+		return 0, domain.ErrFileNotFound
+	}
+	var prefix string
+	if existing != "" && !strings.HasSuffix(existing, "\n") {
+		prefix = "\n"
+	}
+	appended := prefix + joinLines(lines)
+	w.Files[path] = existing + appended
+	return int64(len(appended)), nil
+}
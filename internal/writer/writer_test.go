@@ -2,10 +2,15 @@ package writer_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/pathutil"
 	"github.com/robertbagge/markdown-writer-mcp/internal/writer"
 )
 
@@ -91,7 +96,7 @@ func TestOSFileWriter_Write(t *testing.T) {
 		},
 	}
 
-	w := writer.NewOSFileWriter()
+	w := writer.NewOSFileWriter(writer.Limits{})
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -149,3 +154,323 @@ func TestOSFileWriter_Write(t *testing.T) {
 		}
 	})
 }
+
+func TestOSFileWriter_SymlinkEscape(t *testing.T) {
+	workspace := t.TempDir()
+	outside := t.TempDir()
+	outsideFile := filepath.Join(outside, "log.jsonl")
+	if err := os.WriteFile(outsideFile, []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for link, target := range map[string]string{"out": outside, "log.jsonl": outsideFile} {
+		if err := os.Symlink(target, filepath.Join(workspace, link)); err != nil {
+			t.Skipf("symlinks unavailable: %v", err)
+		}
+	}
+
+	if err := pathutil.SetRoots([]string{workspace}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = pathutil.SetRoots(nil) })
+
+	w := writer.NewOSFileWriter(writer.Limits{})
+
+	t.Run("write through symlinked directory", func(t *testing.T) {
+		_, err := w.Write(context.Background(), filepath.Join(workspace, "out", "new", "a.md"), "# Escaped")
+		if !errors.Is(err, domain.ErrOutsideWorkspace) {
+			t.Fatalf("Write() error = %v, want %v", err, domain.ErrOutsideWorkspace)
+		}
+		if _, err := os.Stat(filepath.Join(outside, "new")); !os.IsNotExist(err) {
+			t.Errorf("Write() created a directory outside the workspace")
+		}
+	})
+
+	t.Run("append through symlinked file", func(t *testing.T) {
+		_, err := w.AppendLines(context.Background(), filepath.Join(workspace, "log.jsonl"), []string{`{"a":1}`}, false)
+		if !errors.Is(err, domain.ErrOutsideWorkspace) {
+			t.Fatalf("AppendLines() error = %v, want %v", err, domain.ErrOutsideWorkspace)
+		}
+		content, err := os.ReadFile(outsideFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != "{}\n" {
+			t.Errorf("AppendLines() changed a file outside the workspace: %q", content)
+		}
+	})
+}
+
+func TestOSFileWriter_WriteMode(t *testing.T) {
+	tests := []struct {
+		name         string
+		file         string
+		mode         writer.Mode
+		wantErr      error
+		wantReplaced bool
+		wantContent  string
+	}{
+		{name: "create new file", file: "new.md", mode: writer.ModeCreate, wantContent: "new"},
+		{name: "create existing file", file: "existing.md", mode: writer.ModeCreate, wantErr: domain.ErrFileExists, wantContent: "old"},
+		{name: "overwrite existing file", file: "existing.md", mode: writer.ModeOverwrite, wantReplaced: true, wantContent: "new"},
+		{name: "overwrite missing file", file: "new.md", mode: writer.ModeOverwrite, wantErr: domain.ErrFileNotFound},
+		{name: "upsert new file", file: "new.md", mode: writer.ModeUpsert, wantContent: "new"},
+		{name: "upsert existing file", file: "existing.md", mode: writer.ModeUpsert, wantReplaced: true, wantContent: "new"},
+		{name: "unknown mode", file: "new.md", mode: "append", wantErr: domain.ErrInvalidWriteMode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "existing.md"), []byte("old"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(dir, tt.file)
+
+			_, replaced, err := writer.NewOSFileWriter(writer.Limits{}).WriteMode(context.Background(), path, "new", tt.mode)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("WriteMode() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("WriteMode() unexpected error: %v", err)
+			}
+			if replaced != tt.wantReplaced {
+				t.Errorf("WriteMode() replaced = %v, want %v", replaced, tt.wantReplaced)
+			}
+
+			content, err := os.ReadFile(path)
+			if tt.wantContent == "" {
+				if !os.IsNotExist(err) {
+					t.Errorf("WriteMode() created %s", tt.file)
+				}
+				return
+			}
+			if string(content) != tt.wantContent {
+				t.Errorf("content = %q, want %q", content, tt.wantContent)
+			}
+
+			// No temporary files are left behind
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range entries {
+				if strings.Contains(e.Name(), ".tmp.") {
+					t.Errorf("temporary file left behind: %s", e.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestOSFileWriter_Limits(t *testing.T) {
+	t.Cleanup(func() { _ = pathutil.SetRoots(nil) })
+
+	// Each case writes to a fresh workspace holding a 10-byte existing.md
+	tests := []struct {
+		name    string
+		limits  writer.Limits
+		write   func(w *writer.OSFileWriter, workspace string) error
+		wantErr error
+	}{
+		{
+			name:   "within write limit",
+			limits: writer.Limits{MaxBytes: 5},
+			write:  writeFile("a.md", "12345"),
+		},
+		{
+			name:    "over write limit",
+			limits:  writer.Limits{MaxBytes: 5},
+			write:   writeFile("a.md", "123456"),
+			wantErr: domain.ErrWriteTooLarge,
+		},
+		{
+			name:    "append over write limit",
+			limits:  writer.Limits{MaxBytes: 5},
+			write:   appendLines("log.jsonl", "12345"),
+			wantErr: domain.ErrWriteTooLarge,
+		},
+		{
+			name:   "replacing a file counts only the growth",
+			limits: writer.Limits{QuotaBytes: 15},
+			write:  writeFile("existing.md", "012345678901234"),
+		},
+		{
+			name:    "new file over quota",
+			limits:  writer.Limits{QuotaBytes: 15},
+			write:   writeFile("new.md", "123456"),
+			wantErr: domain.ErrQuotaExceeded,
+		},
+		{
+			name:    "append over quota",
+			limits:  writer.Limits{QuotaBytes: 15},
+			write:   appendLines("existing.md", "123456"),
+			wantErr: domain.ErrQuotaExceeded,
+		},
+		{
+			name:    "earlier writes count against the quota",
+			limits:  writer.Limits{QuotaBytes: 15},
+			write:   inSequence(writeFile("a.md", "123"), appendLines("log.jsonl", "1"), writeFile("b.md", "1")),
+			wantErr: domain.ErrQuotaExceeded,
+		},
+		{
+			name:   "shrinking a file frees quota",
+			limits: writer.Limits{QuotaBytes: 15},
+			write:  inSequence(writeFile("existing.md", "0"), writeFile("new.md", "12345678")),
+		},
+		{
+			name:   "failed write frees its quota",
+			limits: writer.Limits{QuotaBytes: 15},
+			write: func(w *writer.OSFileWriter, dir string) error {
+				if err := writeFile(filepath.Join("existing.md", "a.md"), "1234")(w, dir); !errors.Is(err, domain.ErrDirCreateFailed) {
+					return fmt.Errorf("write below a file: %v", err)
+				}
+				return writeFile("new.md", "12345")(w, dir)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspace := t.TempDir()
+			if err := os.WriteFile(filepath.Join(workspace, "existing.md"), []byte("0123456789"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := pathutil.SetRoots([]string{workspace}); err != nil {
+				t.Fatal(err)
+			}
+
+			err := tt.write(writer.NewOSFileWriter(tt.limits), workspace)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("write unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("write error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func writeFile(name, content string) func(w *writer.OSFileWriter, dir string) error {
+	return func(w *writer.OSFileWriter, dir string) error {
+		_, err := w.Write(context.Background(), filepath.Join(dir, name), content)
+		return err
+	}
+}
+
+func inSequence(writes ...func(w *writer.OSFileWriter, dir string) error) func(w *writer.OSFileWriter, dir string) error {
+	return func(w *writer.OSFileWriter, dir string) error {
+		for _, write := range writes {
+			if err := write(w, dir); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func appendLines(name string, lines ...string) func(w *writer.OSFileWriter, dir string) error {
+	return func(w *writer.OSFileWriter, dir string) error {
+		_, err := w.AppendLines(context.Background(), filepath.Join(dir, name), lines, true)
+		return err
+	}
+}
+
+func TestOSFileWriter_AppendLines(t *testing.T) {
+	tmpDir := t.TempDir()
+	w := writer.NewOSFileWriter(writer.Limits{})
+
+	tests := []struct {
+		name        string
+		existing    *string
+		create      bool
+		lines       []string
+		wantErr     error
+		wantContent string
+		wantSize    int64
+	}{
+		{
+			name:        "append to file ending in newline",
+			existing:    strPtr("{\"n\":1}\n"),
+			lines:       []string{`{"n":2}`, `{"n":3}`},
+			wantContent: "{\"n\":1}\n{\"n\":2}\n{\"n\":3}\n",
+			wantSize:    16,
+		},
+		{
+			name:        "partial last line is terminated first",
+			existing:    strPtr(`{"n":1}`),
+			lines:       []string{`{"n":2}`},
+			wantContent: "{\"n\":1}\n{\"n\":2}\n",
+			wantSize:    9,
+		},
+		{
+			name:        "create missing file",
+			create:      true,
+			lines:       []string{`{"n":1}`},
+			wantContent: "{\"n\":1}\n",
+			wantSize:    8,
+		},
+		{
+			name:    "missing file without create",
+			lines:   []string{`{"n":1}`},
+			wantErr: domain.ErrFileNotFound,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tmpDir, "sub", fmt.Sprintf("log-%d.jsonl", i))
+			if tt.existing != nil {
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(*tt.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			size, err := w.AppendLines(context.Background(), path, tt.lines, tt.create)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("AppendLines() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AppendLines() error = %v", err)
+			}
+			if size != tt.wantSize {
+				t.Errorf("AppendLines() size = %v, want %v", size, tt.wantSize)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			if string(content) != tt.wantContent {
+				t.Errorf("AppendLines() content = %q, want %q", content, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestInMemoryFileWriter_AppendLines(t *testing.T) {
+	w := writer.NewInMemoryFileWriter()
+	w.Files["/tmp/log.jsonl"] = `{"n":1}`
+
+	if _, err := w.AppendLines(context.Background(), "/tmp/log.jsonl", []string{`{"n":2}`}, false); err != nil {
+		t.Fatalf("AppendLines() error = %v", err)
+	}
+	if got, want := w.Files["/tmp/log.jsonl"], "{\"n\":1}\n{\"n\":2}\n"; got != want {
+		t.Errorf("AppendLines() content = %q, want %q", got, want)
+	}
+	if _, err := w.AppendLines(context.Background(), "/tmp/missing.jsonl", []string{`{}`}, false); !errors.Is(err, domain.ErrFileNotFound) {
+		t.Errorf("AppendLines() error = %v, want %v", err, domain.ErrFileNotFound)
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,331 @@
+package yamldoc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpatch"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpointer"
+	"gopkg.in/yaml.v3"
+)
+
+// Patch applies RFC 6902 operations to a YAML stream by editing its node
+// tree, so that comments, key order and the quoting and flow style of
+// untouched values are kept. Indentation is normalized to the document's
+// own indentation step. In a stream of several documents the root is the
+// array of documents, as in Decode. The operations are first checked
+// against the decoded document with jsonpatch.Apply, whose error is
+// returned if they do not apply.
+func Patch(src []byte, ops []jsonpatch.Operation) ([]byte, error) {
+	doc, err := Decode(src)
+	if err != nil {
+		return nil, err
+	}
+	want, err := jsonpatch.Apply(doc, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := parseDocuments(src)
+	if err != nil {
+		return nil, err
+	}
+	e := &editor{multi: len(docs) > 1}
+	if e.multi {
+		e.root = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, d := range docs {
+			e.root.Content = append(e.root.Content, d.Content[0])
+		}
+	} else {
+		e.root = docs[0].Content[0]
+	}
+
+	for i, op := range ops {
+		if err := e.apply(op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	out, err := encodeDocuments(e.documents(docs), detectIndent(src))
+	if err != nil {
+		return nil, err
+	}
+
+	// The edited tree must decode to exactly the patched document
+	got, err := Decode(out)
+	if err != nil || !jsonpatch.Equal(got, want) {
+		return nil, fmt.Errorf("%w: could not apply the patch to the YAML node tree", domain.ErrInvalidPatch)
+	}
+	return out, nil
+}
+
+// editor applies patch operations to a node tree.
+type editor struct {
+	root *yaml.Node
+	// multi is set when root is the sequence of a stream's documents
+	multi bool
+}
+
+// documents wraps the edited root back into document nodes, reusing the
+// original documents so that their comments are kept.
+func (e *editor) documents(original []*yaml.Node) []*yaml.Node {
+	roots := []*yaml.Node{e.root}
+	if e.multi && e.root.Kind == yaml.SequenceNode {
+		roots = e.root.Content
+	}
+
+	docs := make([]*yaml.Node, len(roots))
+	for i, root := range roots {
+		doc := &yaml.Node{Kind: yaml.DocumentNode}
+		if i < len(original) {
+			copied := *original[i]
+			doc = &copied
+		}
+		doc.Content = []*yaml.Node{root}
+		docs[i] = doc
+	}
+	return docs
+}
+
+// apply performs one operation.
+func (e *editor) apply(op jsonpatch.Operation) error {
+	path, err := jsonpointer.Parse(op.Path)
+	if err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case "add", "replace":
+		n, err := toNode(op.Value)
+		if err != nil {
+			return err
+		}
+		if op.Op == "replace" {
+			return e.replace(path, n)
+		}
+		return e.add(path, n)
+
+	case "remove":
+		_, err := e.remove(path)
+		return err
+
+	case "move", "copy":
+		from, err := jsonpointer.Parse(op.From)
+		if err != nil {
+			return err
+		}
+		var n *yaml.Node
+		if op.Op == "move" {
+			n, err = e.remove(from)
+		} else {
+			n, err = e.get(from)
+			n = deepCopy(n)
+		}
+		if err != nil {
+			return err
+		}
+		return e.add(path, n)
+
+	case "test":
+		return nil
+	}
+	return fmt.Errorf("%w: unknown operation %q", domain.ErrInvalidPatch, op.Op)
+}
+
+// get returns the node at path.
+func (e *editor) get(path jsonpointer.Pointer) (*yaml.Node, error) {
+	current := e.root
+	for _, token := range path {
+		parent := resolve(current)
+		switch parent.Kind {
+		case yaml.MappingNode:
+			i := keyIndex(parent, token)
+			if i < 0 {
+				return nil, fmt.Errorf("%w: member %q not found", domain.ErrPointerNotFound, token)
+			}
+			current = parent.Content[i+1]
+		case yaml.SequenceNode:
+			i, err := jsonpointer.ArrayIndex(token, len(parent.Content))
+			if err != nil {
+				return nil, err
+			}
+			current = parent.Content[i]
+		default:
+			return nil, fmt.Errorf("%w: cannot descend into a scalar", domain.ErrPointerNotFound)
+		}
+	}
+	return current, nil
+}
+
+// parent returns the container holding the last token of path.
+func (e *editor) parent(path jsonpointer.Pointer) (*yaml.Node, error) {
+	n, err := e.get(path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	return resolve(n), nil
+}
+
+// replace substitutes the node at path, keeping the old node's comments.
+func (e *editor) replace(path jsonpointer.Pointer, n *yaml.Node) error {
+	if len(path) == 0 {
+		keepComments(n, e.root)
+		e.root = n
+		return nil
+	}
+	parent, err := e.parent(path)
+	if err != nil {
+		return err
+	}
+	last := path[len(path)-1]
+
+	switch parent.Kind {
+	case yaml.MappingNode:
+		i := keyIndex(parent, last)
+		if i < 0 {
+			return fmt.Errorf("%w: member %q not found", domain.ErrPointerNotFound, last)
+		}
+		keepComments(n, parent.Content[i+1])
+		parent.Content[i+1] = n
+	case yaml.SequenceNode:
+		i, err := jsonpointer.ArrayIndex(last, len(parent.Content))
+		if err != nil {
+			return err
+		}
+		keepComments(n, parent.Content[i])
+		parent.Content[i] = n
+	default:
+		return fmt.Errorf("%w: cannot descend into a scalar", domain.ErrPointerNotFound)
+	}
+	return nil
+}
+
+// add inserts a node at path, replacing an existing mapping member.
+func (e *editor) add(path jsonpointer.Pointer, n *yaml.Node) error {
+	if len(path) == 0 {
+		return e.replace(path, n)
+	}
+	parent, err := e.parent(path)
+	if err != nil {
+		return err
+	}
+	last := path[len(path)-1]
+
+	switch parent.Kind {
+	case yaml.MappingNode:
+		if keyIndex(parent, last) >= 0 {
+			return e.replace(path, n)
+		}
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: last}
+		parent.Content = append(parent.Content, key, n)
+	case yaml.SequenceNode:
+		i, err := jsonpointer.InsertIndex(last, len(parent.Content))
+		if err != nil {
+			return err
+		}
+		parent.Content = append(parent.Content, nil)
+		copy(parent.Content[i+1:], parent.Content[i:])
+		parent.Content[i] = n
+	default:
+		return fmt.Errorf("%w: cannot add to a scalar", domain.ErrPointerNotFound)
+	}
+	return nil
+}
+
+// remove deletes the node at path, with its key and comments, and returns it.
+func (e *editor) remove(path jsonpointer.Pointer) (*yaml.Node, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("%w: cannot remove the document root", domain.ErrInvalidPatch)
+	}
+	parent, err := e.parent(path)
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+
+	switch parent.Kind {
+	case yaml.MappingNode:
+		i := keyIndex(parent, last)
+		if i < 0 {
+			return nil, fmt.Errorf("%w: member %q not found", domain.ErrPointerNotFound, last)
+		}
+		n := parent.Content[i+1]
+		parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+		return n, nil
+	case yaml.SequenceNode:
+		i, err := jsonpointer.ArrayIndex(last, len(parent.Content))
+		if err != nil {
+			return nil, err
+		}
+		n := parent.Content[i]
+		parent.Content = append(parent.Content[:i], parent.Content[i+1:]...)
+		return n, nil
+	}
+	return nil, fmt.Errorf("%w: cannot descend into a scalar", domain.ErrPointerNotFound)
+}
+
+// keyIndex returns the index of the key node for key in a mapping, or -1.
+// The last of duplicate keys wins, as when decoding.
+func keyIndex(mapping *yaml.Node, key string) int {
+	found := -1
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if k := mapping.Content[i]; k.Kind == yaml.ScalarNode && k.ShortTag() != "!!merge" && k.Value == key {
+			found = i
+		}
+	}
+	return found
+}
+
+// resolve follows an alias to its anchored node.
+func resolve(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
+
+// keepComments carries the comments of a replaced node over to its
+// replacement.
+func keepComments(n, old *yaml.Node) {
+	if n.HeadComment == "" {
+		n.HeadComment = old.HeadComment
+	}
+	if n.LineComment == "" {
+		n.LineComment = old.LineComment
+	}
+	if n.FootComment == "" {
+		n.FootComment = old.FootComment
+	}
+}
+
+// deepCopy copies a node tree.
+func deepCopy(n *yaml.Node) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	copied := *n
+	copied.Content = make([]*yaml.Node, len(n.Content))
+	for i, child := range n.Content {
+		copied.Content[i] = deepCopy(child)
+	}
+	return &copied
+}
+
+// detectIndent returns the indentation step below the first mapping key
+// that opens a nested block, or 2.
+func detectIndent(src []byte) int {
+	prevIndent, prevOpens := 0, false
+	for _, line := range strings.Split(string(src), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(trimmed)
+		if prevOpens && indent > prevIndent {
+			return indent - prevIndent
+		}
+		prevIndent, prevOpens = indent, strings.HasSuffix(strings.TrimRight(trimmed, " "), ":")
+	}
+	return 2
+}
@@ -0,0 +1,348 @@
+// Package yamldoc maps YAML files onto the document model of the JSON tools:
+// map[string]any, []any, string, json.Number, bool and nil. Edits are made
+// on the YAML node tree, so comments and the style of untouched values
+// survive a rewrite.
+package yamldoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// maxAliasDepth bounds the nesting of the converted document, aliases
+// included, so a deeply chained alias cannot exhaust the stack.
+const maxAliasDepth = 64
+
+// Aliases may expand a document to at most expansionRatio times its source
+// node count, but always to minExpansionBudget values. This stops alias
+// bombs ("billion laughs"), whose few nested anchors each referenced many
+// times expand exponentially, while nesting alone is bounded above.
+const (
+	expansionRatio     = 100
+	minExpansionBudget = 100_000
+)
+
+// jsonNumber matches a number in standard JSON form.
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// yaml11Ambiguous matches plain strings that YAML 1.1 readers, still common
+// in tooling, resolve to booleans or numbers, such as "on" or "8080:80".
+var yaml11Ambiguous = regexp.MustCompile(`^(?i:y|n|yes|no|on|off)$|^[-+]?[0-9][0-9_]*(:[0-5]?[0-9])+(\.[0-9_]*)?$`)
+
+// Decode parses a YAML stream. A single document decodes to its value and
+// a stream of several documents, such as a set of Kubernetes manifests, to
+// an array of their values. An empty stream decodes to nil.
+func Decode(src []byte) (any, error) {
+	docs, err := parseDocuments(src)
+	if err != nil {
+		return nil, err
+	}
+	c := &converter{budget: minExpansionBudget}
+	for _, doc := range docs {
+		c.budget = max(c.budget, expansionRatio*countNodes(doc))
+	}
+	if len(docs) == 1 {
+		return c.toValue(docs[0], 0)
+	}
+	values := make([]any, len(docs))
+	for i, doc := range docs {
+		if values[i], err = c.toValue(doc, 0); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// Encode serializes a document as YAML with two-space indentation.
+func Encode(doc any) ([]byte, error) {
+	n, err := toNode(doc)
+	if err != nil {
+		return nil, err
+	}
+	return encodeDocuments([]*yaml.Node{{Kind: yaml.DocumentNode, Content: []*yaml.Node{n}}}, 2)
+}
+
+// parseDocuments reads every document of a YAML stream. An empty stream
+// yields a single null document.
+func parseDocuments(src []byte) ([]*yaml.Node, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(src))
+	var docs []*yaml.Node
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidYAML, err)
+		}
+		docs = append(docs, &doc)
+	}
+	if len(docs) == 0 {
+		docs = append(docs, &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{nullNode()}})
+	}
+	return docs, nil
+}
+
+// encodeDocuments writes documents as a YAML stream.
+func encodeDocuments(docs []*yaml.Node, indent int) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indent)
+	for _, doc := range docs {
+		untagMergeKeys(doc)
+		if err := enc.Encode(doc); err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidYAML, err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidYAML, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// untagMergeKeys clears the tag of merge keys, which the encoder would
+// otherwise write out as "!!merge <<".
+func untagMergeKeys(n *yaml.Node) {
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!merge" {
+		n.Tag = ""
+	}
+	for _, child := range n.Content {
+		untagMergeKeys(child)
+	}
+}
+
+// countNodes returns the number of nodes in the tree of n, not following
+// aliases.
+func countNodes(n *yaml.Node) int {
+	count := 1
+	for _, child := range n.Content {
+		count += countNodes(child)
+	}
+	return count
+}
+
+// converter converts nodes to the document model, counting the values it
+// produces against the alias expansion budget.
+type converter struct {
+	budget int
+}
+
+// toValue converts a node to the document model. Anchors and merge keys
+// are expanded.
+func (c *converter) toValue(n *yaml.Node, depth int) (any, error) {
+	if depth > maxAliasDepth {
+		return nil, fmt.Errorf("%w: document nested too deeply", domain.ErrInvalidYAML)
+	}
+	if c.budget--; c.budget < 0 {
+		return nil, fmt.Errorf("%w: aliases expand to too many values", domain.ErrInvalidYAML)
+	}
+
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+		return c.toValue(n.Content[0], depth)
+
+	case yaml.AliasNode:
+		return c.toValue(n.Alias, depth+1)
+
+	case yaml.SequenceNode:
+		arr := make([]any, len(n.Content))
+		for i, item := range n.Content {
+			v, err := c.toValue(item, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = v
+		}
+		return arr, nil
+
+	case yaml.MappingNode:
+		obj := make(map[string]any, len(n.Content)/2)
+		if err := c.addMembers(obj, n, depth); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	}
+	return scalarValue(n)
+}
+
+// addMembers copies the pairs of mapping n into obj. Keys set directly
+// take precedence over those brought in by merge keys.
+func (c *converter) addMembers(obj map[string]any, n *yaml.Node, depth int) error {
+	var merged []*yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if key.Kind == yaml.ScalarNode && key.ShortTag() == "!!merge" {
+			merged = append(merged, value)
+			continue
+		}
+		if key.Kind != yaml.ScalarNode {
+			return fmt.Errorf("%w: line %d: only scalar mapping keys are supported", domain.ErrInvalidYAML, key.Line)
+		}
+		v, err := c.toValue(value, depth+1)
+		if err != nil {
+			return err
+		}
+		obj[key.Value] = v
+	}
+
+	for _, m := range merged {
+		if m.Kind == yaml.AliasNode {
+			m = m.Alias
+		}
+		sources := []*yaml.Node{m}
+		if m.Kind == yaml.SequenceNode {
+			sources = m.Content
+		}
+		for _, src := range sources {
+			if src.Kind == yaml.AliasNode {
+				src = src.Alias
+			}
+			if src.Kind != yaml.MappingNode {
+				return fmt.Errorf("%w: line %d: merge key needs a mapping", domain.ErrInvalidYAML, src.Line)
+			}
+			inherited := make(map[string]any)
+			if err := c.addMembers(inherited, src, depth+1); err != nil {
+				return err
+			}
+			for k, v := range inherited {
+				if _, exists := obj[k]; !exists {
+					obj[k] = v
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// scalarValue converts a scalar by its resolved tag. Numbers become
+// json.Number, keeping their text when it is already valid JSON; dates and
+// other tagged scalars become strings.
+func scalarValue(n *yaml.Node) (any, error) {
+	switch n.ShortTag() {
+	case "!!null":
+		return nil, nil
+
+	case "!!bool":
+		var b bool
+		if err := n.Decode(&b); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", domain.ErrInvalidYAML, n.Line, err)
+		}
+		return b, nil
+
+	case "!!int", "!!float":
+		if jsonNumber.MatchString(n.Value) {
+			return json.Number(n.Value), nil
+		}
+		var v any
+		if err := n.Decode(&v); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", domain.ErrInvalidYAML, n.Line, err)
+		}
+		switch num := v.(type) {
+		case int:
+			return json.Number(strconv.Itoa(num)), nil
+		case int64:
+			return json.Number(strconv.FormatInt(num, 10)), nil
+		case uint64:
+			return json.Number(strconv.FormatUint(num, 10)), nil
+		case float64:
+			if math.IsNaN(num) || math.IsInf(num, 0) {
+				return nil, fmt.Errorf("%w: line %d: %s cannot be represented in JSON", domain.ErrInvalidYAML, n.Line, n.Value)
+			}
+			return json.Number(strconv.FormatFloat(num, 'g', -1, 64)), nil
+		}
+		return n.Value, nil
+	}
+	return n.Value, nil
+}
+
+// toNode converts a document model value to a YAML node. Object members
+// are sorted by key.
+func toNode(v any) (*yaml.Node, error) {
+	switch val := v.(type) {
+	case nil:
+		return nullNode(), nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(val)}, nil
+	case string:
+		n := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: val}
+		if strings.Contains(val, "\n") {
+			n.Style = yaml.LiteralStyle
+		} else if yaml11Ambiguous.MatchString(val) {
+			n.Style = yaml.DoubleQuotedStyle
+		}
+		return n, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(string(val), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: string(val)}, nil
+	case float64:
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			return nil, fmt.Errorf("%w: %v cannot be represented in JSON", domain.ErrInvalidYAML, val)
+		}
+		return toNode(json.Number(strconv.FormatFloat(val, 'g', -1, 64)))
+	case int:
+		return toNode(json.Number(strconv.Itoa(val)))
+	case int64:
+		return toNode(json.Number(strconv.FormatInt(val, 10)))
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, k := range keys {
+			child, err := toNode(val[k])
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}, child)
+		}
+		return n, nil
+	case []any:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range val {
+			child, err := toNode(item)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, child)
+		}
+		return n, nil
+	}
+
+	// Round-trip any other Go value through encoding/json
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var decoded any
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return toNode(decoded)
+}
+
+// nullNode returns a null scalar.
+func nullNode() *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+}
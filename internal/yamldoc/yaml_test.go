@@ -0,0 +1,264 @@
+package yamldoc_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/robertbagge/markdown-writer-mcp/internal/domain"
+	"github.com/robertbagge/markdown-writer-mcp/internal/jsonpatch"
+	"github.com/robertbagge/markdown-writer-mcp/internal/yamldoc"
+)
+
+// billionLaughs nests nine anchors, each referencing the previous one ten
+// times, so it expands to a billion values.
+const billionLaughs = `a: &a ["lol","lol","lol","lol","lol","lol","lol","lol","lol","lol"]
+b: &b [*a,*a,*a,*a,*a,*a,*a,*a,*a,*a]
+c: &c [*b,*b,*b,*b,*b,*b,*b,*b,*b,*b]
+d: &d [*c,*c,*c,*c,*c,*c,*c,*c,*c,*c]
+e: &e [*d,*d,*d,*d,*d,*d,*d,*d,*d,*d]
+f: &f [*e,*e,*e,*e,*e,*e,*e,*e,*e,*e]
+g: &g [*f,*f,*f,*f,*f,*f,*f,*f,*f,*f]
+h: &h [*g,*g,*g,*g,*g,*g,*g,*g,*g,*g]
+i: &i [*h,*h,*h,*h,*h,*h,*h,*h,*h,*h]
+`
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    any
+		wantErr error
+	}{
+		{
+			name: "scalars",
+			input: `str: hello
+quoted: "yes"
+int: 42
+big: 12345678901234567890
+hex: 0x1F
+float: 1.50
+bool: true
+null: ~
+date: 2024-01-02
+`,
+			want: map[string]any{
+				"str":    "hello",
+				"quoted": "yes",
+				"int":    json.Number("42"),
+				"big":    json.Number("12345678901234567890"),
+				"hex":    json.Number("31"),
+				"float":  json.Number("1.50"),
+				"bool":   true,
+				"null":   nil,
+				"date":   "2024-01-02",
+			},
+		},
+		{
+			name: "anchors and merge keys",
+			input: `base: &base
+  retries: 3
+  timeout: 10
+job:
+  <<: *base
+  timeout: 20
+list: [*base]
+`,
+			want: map[string]any{
+				"base": map[string]any{"retries": json.Number("3"), "timeout": json.Number("10")},
+				"job":  map[string]any{"retries": json.Number("3"), "timeout": json.Number("20")},
+				"list": []any{map[string]any{"retries": json.Number("3"), "timeout": json.Number("10")}},
+			},
+		},
+		{
+			name:  "multiple documents",
+			input: "kind: A\n---\nkind: B\n",
+			want:  []any{map[string]any{"kind": "A"}, map[string]any{"kind": "B"}},
+		},
+		{
+			name:  "empty stream",
+			input: "# nothing here\n",
+			want:  nil,
+		},
+		{
+			name:    "syntax error",
+			input:   "a: [1, 2\n",
+			wantErr: domain.ErrInvalidYAML,
+		},
+		{
+			name:    "infinity",
+			input:   "a: .inf\n",
+			wantErr: domain.ErrInvalidYAML,
+		},
+		{
+			name:    "alias bomb",
+			input:   billionLaughs,
+			wantErr: domain.ErrInvalidYAML,
+		},
+		{
+			name:    "complex key",
+			input:   "? [a, b]\n: 1\n",
+			wantErr: domain.ErrInvalidYAML,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := yamldoc.Decode([]byte(tt.input))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	doc := map[string]any{
+		"name":  "app",
+		"port":  json.Number("8080"),
+		"flags": []any{"true", nil, 1.5},
+		"text":  "line one\nline two",
+	}
+	got, err := yamldoc.Encode(doc)
+	if err != nil {
+		t.Fatalf("Encode() unexpected error: %v", err)
+	}
+	want := `flags:
+  - "true"
+  - null
+  - 1.5
+name: app
+port: 8080
+text: |-
+  line one
+  line two
+`
+	if string(got) != want {
+		t.Errorf("Encode() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	const workflow = `# CI workflow
+name: build # shown in the UI
+on: [push, pull_request]
+defaults: &defaults
+    retries: 3
+jobs:
+    test:
+        <<: *defaults
+        steps:
+            # check out first
+            - uses: actions/checkout@v4
+            - run: go test ./...
+`
+
+	tests := []struct {
+		name  string
+		input string
+		patch string
+		want  string
+	}{
+		{
+			name:  "comments and styles are kept",
+			input: workflow,
+			patch: `[
+				{"op": "replace", "path": "/name", "value": "ci"},
+				{"op": "add", "path": "/on/-", "value": "workflow_dispatch"},
+				{"op": "add", "path": "/jobs/test/steps/1", "value": {"run": "go vet ./..."}},
+				{"op": "remove", "path": "/jobs/test/steps/2"}
+			]`,
+			want: `# CI workflow
+name: ci # shown in the UI
+on: [push, pull_request, workflow_dispatch]
+defaults: &defaults
+    retries: 3
+jobs:
+    test:
+        <<: *defaults
+        steps:
+            # check out first
+            - uses: actions/checkout@v4
+            - run: go vet ./...
+`,
+		},
+		{
+			name:  "multiple documents",
+			input: "kind: A\n---\n# second\nkind: B\n",
+			patch: `[
+				{"op": "replace", "path": "/1/kind", "value": "C"},
+				{"op": "add", "path": "/-", "value": {"kind": "D"}}
+			]`,
+			want: "kind: A\n---\n# second\nkind: C\n---\nkind: D\n",
+		},
+		{
+			name:  "strings that look like other types are quoted",
+			input: "a: x\n",
+			patch: `[{"op": "add", "path": "/b", "value": "123"}, {"op": "add", "path": "/c", "value": "true"}]`,
+			want:  "a: x\nb: \"123\"\nc: \"true\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ops []jsonpatch.Operation
+			if err := json.Unmarshal([]byte(tt.patch), &ops); err != nil {
+				t.Fatalf("invalid test patch: %v", err)
+			}
+			got, err := yamldoc.Patch([]byte(tt.input), ops)
+			if err != nil {
+				t.Fatalf("Patch() unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Patch() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatch_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		op      jsonpatch.Operation
+		wantErr error
+	}{
+		{
+			name:    "missing member",
+			input:   "a: 1\n",
+			op:      jsonpatch.Operation{Op: "remove", Path: "/b"},
+			wantErr: domain.ErrPointerNotFound,
+		},
+		{
+			name:    "failed test",
+			input:   "a: 1\n",
+			op:      jsonpatch.Operation{Op: "test", Path: "/a", Value: 2},
+			wantErr: domain.ErrPatchTestFailed,
+		},
+		{
+			name:    "invalid YAML",
+			input:   "a: [\n",
+			op:      jsonpatch.Operation{Op: "remove", Path: "/a"},
+			wantErr: domain.ErrInvalidYAML,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := yamldoc.Patch([]byte(tt.input), []jsonpatch.Operation{tt.op})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Patch() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}